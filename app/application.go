@@ -4,8 +4,20 @@ import (
 	"fmt"
 	dbConfig "go_project_structure/config/db"
 	config "go_project_structure/config/env"
+	apikey "go_project_structure/internal/api_key"
+	"go_project_structure/internal/events"
+	"go_project_structure/internal/federation"
+	"go_project_structure/internal/grpcserver"
+	"go_project_structure/internal/oidc"
+	"go_project_structure/internal/permission"
+	revokedtoken "go_project_structure/internal/revoked_token"
 	"go_project_structure/internal/router"
+	"go_project_structure/internal/token"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
 
+	"context"
+	"net"
 	"net/http"
 	"time"
 
@@ -14,14 +26,17 @@ import (
 
 // Config holds the configuration for the server.
 type Config struct {
-	Addr string // PORT
+	Addr     string // PORT
+	GrpcAddr string // GRPC_PORT, unset or empty disables the gRPC server
 }
 
 // constructor for Config
 func NewConfig() Config {
 	port := config.GetString("PORT", ":8080")
+	grpcPort := config.GetString("GRPC_PORT", "")
 	return Config{
-		Addr: port,
+		Addr:     port,
+		GrpcAddr: grpcPort,
 	}
 }
 
@@ -46,10 +61,65 @@ func (app *Application) Run() error {
 		return err
 	}
 
+	if config.GetBool("DB_VERIFY_SCHEMA", true) {
+		if err := dbConfig.VerifySchema(db, Models...); err != nil {
+			fmt.Println("Database schema verification failed.")
+			return err
+		}
+	}
+
+	if err := token.LoadSigningKeys(); err != nil {
+		fmt.Println("Error loading signing keys.")
+		return err
+	}
+	if err := oidc.CheckSigningKeys(); err != nil {
+		fmt.Println("Error configuring the OpenID Connect provider.")
+		return err
+	}
+	if _, err := federation.LoadProviders(); err != nil {
+		fmt.Println("Error loading identity providers.")
+		return err
+	}
+
+	events.DefaultBus.Subscribe(events.LogHandler)
+	permission.RegisterOwnershipRule("user", user.OwnsAccount)
+	permission.RegisterResourceAttributes("user", user.AccountAttributes)
+	// revoked access tokens are refused by every transport that parses them
+	token.RegisterRevocationCheck(revokedtoken.NewRevokedTokenService(revokedtoken.NewRevokedTokenRepository(db)).IsRevoked)
+	// API keys are accepted wherever access tokens are
+	token.RegisterApiKeyAuthenticator(apikey.NewApiKeyService(apikey.NewApiKeyRepository(db), userrole.NewUserRoleRepository(db)).Authenticate)
+
+	// expired role assignments are already ignored by authorization checks;
+	// the sweeper soft deletes them and announces the expiry.
+	sweepInterval := time.Duration(config.GetInt("USER_ROLE_SWEEP_INTERVAL_SECONDS", 60)) * time.Second
+	if sweepInterval > 0 {
+		sweeper := userrole.NewAssignmentSweeper(userrole.NewUserRoleService(userrole.NewUserRoleRepository(db)), events.DefaultBus, sweepInterval)
+		sweepCtx, stopSweeper := context.WithCancel(context.Background())
+		defer stopSweeper()
+		go sweeper.Run(sweepCtx)
+	}
+
 	for _, registerFn := range router.DomainRegistries {
 		registerFn(db, rootRouter)
 	}
 
+	// the gRPC server shares the services of the HTTP routes and stops with the HTTP server
+	if app.Config.GrpcAddr != "" {
+		listener, err := net.Listen("tcp", app.Config.GrpcAddr)
+		if err != nil {
+			fmt.Println("Error listening for gRPC on", app.Config.GrpcAddr)
+			return err
+		}
+		grpcServer := grpcserver.NewServer(db)
+		defer grpcServer.GracefulStop()
+		go func() {
+			fmt.Println("Starting gRPC server on port", app.Config.GrpcAddr)
+			if err := grpcServer.Serve(listener); err != nil {
+				fmt.Printf("gRPC server stopped: %v\n", err)
+			}
+		}()
+	}
+
 	server := &http.Server{
 		Addr:         app.Config.Addr,
 		Handler:      rootRouter,       
@@ -0,0 +1,45 @@
+package app
+
+import (
+	apikey "go_project_structure/internal/api_key"
+	"go_project_structure/internal/federation"
+	"go_project_structure/internal/mfa"
+	"go_project_structure/internal/oidc"
+	"go_project_structure/internal/organization"
+	"go_project_structure/internal/passkey"
+	"go_project_structure/internal/permission"
+	refreshtoken "go_project_structure/internal/refresh_token"
+	resourcegrant "go_project_structure/internal/resource_grant"
+	revokedtoken "go_project_structure/internal/revoked_token"
+	"go_project_structure/internal/role"
+	rolepermission "go_project_structure/internal/role_permission"
+	serviceaccount "go_project_structure/internal/service_account"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
+)
+
+// Models lists every gorm model backed by a migration.
+// They are checked against the live database schema when the application starts.
+var Models = []interface{}{
+	&user.User{},
+	&organization.Organization{},
+	&role.Role{},
+	&role.RoleParent{},
+	&permission.Permission{},
+	&rolepermission.RolePermission{},
+	&userrole.UserRole{},
+	&resourcegrant.ResourceGrant{},
+	&refreshtoken.RefreshToken{},
+	&revokedtoken.RevokedToken{},
+	&oidc.OidcClient{},
+	&oidc.OidcAuthorizationCode{},
+	&federation.LinkedIdentity{},
+	&federation.FederationLogin{},
+	&apikey.ApiKey{},
+	&serviceaccount.ServiceAccount{},
+	&mfa.TotpFactor{},
+	&mfa.MfaRecoveryCode{},
+	&mfa.MfaChallenge{},
+	&passkey.PasskeyCredential{},
+	&passkey.PasskeyCeremony{},
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// compatibleColumnTypes lists the information_schema data types accepted for each gorm data type.
+var compatibleColumnTypes = map[schema.DataType][]string{
+	schema.Bool:   {"boolean"},
+	schema.Int:    {"smallint", "integer", "bigint"},
+	schema.Uint:   {"smallint", "integer", "bigint"},
+	schema.Float:  {"real", "double precision", "numeric"},
+	schema.String: {"character varying", "character", "text"},
+	schema.Time:   {"timestamp without time zone", "timestamp with time zone", "date"},
+	schema.Bytes:  {"bytea"},
+}
+
+type liveColumn struct {
+	DataType   string
+	IsNullable string
+	MaxLength  int
+}
+
+type liveIndex struct {
+	Columns string
+	Unique  bool
+	Partial bool
+}
+
+// VerifySchema compares every given gorm model with the live Postgres schema and
+// returns a readable diff of the drift it finds, so that a model/migration mismatch
+// stops the application at startup instead of failing the first request that hits it.
+func VerifySchema(db *gorm.DB, models ...interface{}) error {
+	fmt.Println("Verifying database schema against models.")
+
+	drift := []string{}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("error parsing model %T: %w", model, err)
+		}
+
+		modelDrift, err := verifyModelSchema(db, stmt.Schema)
+		if err != nil {
+			return err
+		}
+		drift = append(drift, modelDrift...)
+	}
+
+	if len(drift) > 0 {
+		return fmt.Errorf("database schema does not match the models:\n  - %s", strings.Join(drift, "\n  - "))
+	}
+
+	fmt.Println("Database schema matches the models.")
+	return nil
+}
+
+func verifyModelSchema(db *gorm.DB, sch *schema.Schema) ([]string, error) {
+	table := sch.Table
+	model := sch.ModelType.String()
+
+	columns, err := loadLiveColumns(db, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return []string{fmt.Sprintf("%s: table is missing (model %s)", table, model)}, nil
+	}
+
+	drift := []string{}
+	for _, field := range sch.Fields {
+		if field.DBName == "" {
+			continue
+		}
+
+		column, ok := columns[field.DBName]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("%s.%s: column is missing (field %s.%s)", table, field.DBName, model, field.Name))
+			continue
+		}
+
+		if accepted, known := compatibleColumnTypes[field.DataType]; known && !containsString(accepted, column.DataType) {
+			drift = append(drift, fmt.Sprintf("%s.%s: column type is %s but field %s.%s is %s", table, field.DBName, column.DataType, model, field.Name, field.DataType))
+		}
+		if field.NotNull && !field.PrimaryKey && column.IsNullable == "YES" {
+			drift = append(drift, fmt.Sprintf("%s.%s: column is nullable but field %s.%s is not null", table, field.DBName, model, field.Name))
+		}
+		if field.Size > 0 && column.MaxLength > 0 && field.Size != column.MaxLength {
+			drift = append(drift, fmt.Sprintf("%s.%s: column length is %d but field %s.%s has size %d", table, field.DBName, column.MaxLength, model, field.Name, field.Size))
+		}
+	}
+
+	indexes, err := loadLiveIndexes(db, table)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range sch.Fields {
+		if !field.Unique || field.DBName == "" {
+			continue
+		}
+		found := false
+		for _, index := range indexes {
+			if index.Unique && !index.Partial && index.Columns == field.DBName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			drift = append(drift, fmt.Sprintf("%s.%s: no unique constraint on the column (field %s.%s)", table, field.DBName, model, field.Name))
+		}
+	}
+
+	for _, expected := range sch.ParseIndexes() {
+		expectedColumns := []string{}
+		for _, option := range expected.Fields {
+			expectedColumns = append(expectedColumns, option.DBName)
+		}
+		columnList := strings.Join(expectedColumns, ",")
+		unique := expected.Class == "UNIQUE"
+		partial := expected.Where != ""
+
+		index, ok := indexes[expected.Name]
+		switch {
+		case !ok:
+			drift = append(drift, fmt.Sprintf("%s: index %s on (%s) is missing", table, expected.Name, columnList))
+		case index.Columns != columnList:
+			drift = append(drift, fmt.Sprintf("%s: index %s covers (%s) but the model expects (%s)", table, expected.Name, index.Columns, columnList))
+		case index.Unique != unique:
+			drift = append(drift, fmt.Sprintf("%s: index %s unique is %t but the model expects %t", table, expected.Name, index.Unique, unique))
+		case index.Partial != partial:
+			drift = append(drift, fmt.Sprintf("%s: index %s partial is %t but the model expects %t", table, expected.Name, index.Partial, partial))
+		}
+	}
+
+	return drift, nil
+}
+
+func loadLiveColumns(db *gorm.DB, table string) (map[string]liveColumn, error) {
+	query := `SELECT column_name, data_type, is_nullable, COALESCE(character_maximum_length, 0)
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = ?`
+
+	rows, err := db.Raw(query, table).Rows()
+	if err != nil {
+		fmt.Printf("Error loading columns of %s: %v\n", table, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := map[string]liveColumn{}
+	for rows.Next() {
+		var name string
+		var column liveColumn
+		if err := rows.Scan(&name, &column.DataType, &column.IsNullable, &column.MaxLength); err != nil {
+			return nil, err
+		}
+		columns[name] = column
+	}
+	return columns, rows.Err()
+}
+
+func loadLiveIndexes(db *gorm.DB, table string) (map[string]liveIndex, error) {
+	// information_schema has no view of indexes, so they are read from the catalog.
+	query := `SELECT i.relname, ix.indisunique, ix.indpred IS NOT NULL,
+			(SELECT string_agg(a.attname, ',' ORDER BY k.n)
+			 FROM unnest(ix.indkey) WITH ORDINALITY AS k(attnum, n)
+			 JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = k.attnum)
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = current_schema() AND t.relname = ?`
+
+	rows, err := db.Raw(query, table).Rows()
+	if err != nil {
+		fmt.Printf("Error loading indexes of %s: %v\n", table, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	indexes := map[string]liveIndex{}
+	for rows.Next() {
+		var name string
+		var columns *string
+		var index liveIndex
+		if err := rows.Scan(&name, &index.Unique, &index.Partial, &columns); err != nil {
+			return nil, err
+		}
+		if columns != nil {
+			index.Columns = *columns
+		}
+		indexes[name] = index
+	}
+	return indexes, rows.Err()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
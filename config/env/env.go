@@ -33,9 +33,12 @@ func GetString(key string, fallback string) string {
 
 func GetInt(key string, fallback int) int {
 
-	value := getKey(key, fallback)
+	value, ok := getKey(key, fallback).(string)
+	if !ok {
+		return fallback
+	}
 
-	intValue, err := strconv.Atoi(value.(string))
+	intValue, err := strconv.Atoi(value)
 	if err != nil {
 		fmt.Printf("Error converting %s to int: %v\n", key, err)
 		return fallback
@@ -46,9 +49,12 @@ func GetInt(key string, fallback int) int {
 
 func GetBool(key string, fallback bool) bool {
 
-	value := getKey(key, fallback)
+	value, ok := getKey(key, fallback).(string)
+	if !ok {
+		return fallback
+	}
 
-	boolValue, err := strconv.ParseBool(value.(string))
+	boolValue, err := strconv.ParseBool(value)
 	if err != nil {
 		fmt.Printf("Error converting %s to bool: %v\n", key, err)
 		return fallback
@@ -57,9 +63,12 @@ func GetBool(key string, fallback bool) bool {
 }
 
 func GetFloat(key string, fallback float64) float64 {
-	value := getKey(key, fallback)
+	value, ok := getKey(key, fallback).(string)
+	if !ok {
+		return fallback
+	}
 
-	floatValue, err := strconv.ParseFloat(value.(string), 64)
+	floatValue, err := strconv.ParseFloat(value, 64)
 	if err != nil {
 		fmt.Printf("Error converting %s to float: %v\n", key, err)
 		return fallback
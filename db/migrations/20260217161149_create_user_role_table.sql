@@ -14,5 +14,5 @@ CREATE TABLE IF NOT EXISTS user_role (
 
 -- +goose Down
 -- +goose StatementBegin
-DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS user_role;
 -- +goose StatementEnd
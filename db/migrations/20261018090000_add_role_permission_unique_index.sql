@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- keep the oldest live grant of every (role_id, permission_id) pair and revoke the duplicates
+UPDATE role_permissions rp
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE rp.deleted_at IS NULL
+AND EXISTS (
+    SELECT 1 FROM role_permissions dup
+    WHERE dup.deleted_at IS NULL
+    AND dup.role_id = rp.role_id
+    AND dup.permission_id = rp.permission_id
+    AND dup.id < rp.id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_role_permissions_role_permission_live
+ON role_permissions (role_id, permission_id)
+WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_role_permissions_deleted_at ON role_permissions (deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_role_permissions_deleted_at;
+DROP INDEX IF EXISTS idx_role_permissions_role_permission_live;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_name_live ON roles (name) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_roles_deleted_at ON roles (deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_roles_deleted_at;
+DROP INDEX IF EXISTS idx_roles_name_live;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE UNIQUE INDEX IF NOT EXISTS idx_permissions_name_live ON permissions (name) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_permissions_resource_action ON permissions (resource, action);
+CREATE INDEX IF NOT EXISTS idx_permissions_deleted_at ON permissions (deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_permissions_deleted_at;
+DROP INDEX IF EXISTS idx_permissions_resource_action;
+DROP INDEX IF EXISTS idx_permissions_name_live;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- emails only have to be unique among live users, so a deleted account frees its address
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_live ON users (email) WHERE deleted_at IS NULL;
+
+-- keep the oldest live assignment of every (user_id, role_id) pair and remove the duplicates
+UPDATE user_role ur
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE ur.deleted_at IS NULL
+AND EXISTS (
+    SELECT 1 FROM user_role dup
+    WHERE dup.deleted_at IS NULL
+    AND dup.user_id = ur.user_id
+    AND dup.role_id = ur.role_id
+    AND dup.id < ur.id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_role_user_role_live
+ON user_role (user_id, role_id)
+WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_user_role_deleted_at ON user_role (deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_user_role_deleted_at;
+DROP INDEX IF EXISTS idx_user_role_user_role_live;
+DROP INDEX IF EXISTS idx_users_email_live;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS role_parents (
+    id SERIAL PRIMARY KEY,
+    role_id INT NOT NULL,
+    parent_role_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    CHECK (role_id <> parent_role_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_role_parents_role_parent_live
+ON role_parents (role_id, parent_role_id)
+WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_role_parents_deleted_at ON role_parents (deleted_at);
+
+-- admin inherits everything moderator has, and moderator inherits everything user has
+INSERT INTO role_parents (role_id, parent_role_id)
+SELECT child.id, parent.id
+FROM roles child, roles parent
+WHERE child.deleted_at IS NULL AND parent.deleted_at IS NULL
+AND ((child.name = 'admin' AND parent.name = 'moderator') OR (child.name = 'moderator' AND parent.name = 'user'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS role_parents;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO permissions (name, description, resource, action) VALUES
+('*', 'All permissions on every resource', '*', '*'),
+('user:*', 'All permissions on users', 'user', '*'),
+('role:*', 'All permissions on roles', 'role', '*'),
+('permission:*', 'All permissions on permissions', 'permission', '*'),
+('*:read', 'Read every resource', '*', 'read');
+
+-- admin holds the global wildcard instead of relying on a row per permission
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.deleted_at IS NULL AND p.deleted_at IS NULL
+AND r.name = 'admin' AND p.name = '*'
+AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.deleted_at IS NULL AND rp.role_id = r.id AND rp.permission_id = p.id
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name IN ('*', 'user:*', 'role:*', 'permission:*', '*:read');
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE role_permissions
+ADD COLUMN IF NOT EXISTS effect VARCHAR(10) NOT NULL DEFAULT 'allow'
+CHECK (effect IN ('allow', 'deny'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE role_permissions DROP COLUMN IF EXISTS effect;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE user_role
+ADD COLUMN IF NOT EXISTS valid_from TIMESTAMP DEFAULT NULL,
+ADD COLUMN IF NOT EXISTS valid_until TIMESTAMP DEFAULT NULL,
+ADD CONSTRAINT chk_user_role_validity CHECK (valid_from IS NULL OR valid_until IS NULL OR valid_until > valid_from);
+
+-- the expiry sweeper only looks at live assignments that have an end date
+CREATE INDEX IF NOT EXISTS idx_user_role_valid_until ON user_role (valid_until)
+WHERE deleted_at IS NULL AND valid_until IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_user_role_valid_until;
+ALTER TABLE user_role
+DROP CONSTRAINT IF EXISTS chk_user_role_validity,
+DROP COLUMN IF EXISTS valid_until,
+DROP COLUMN IF EXISTS valid_from;
+-- +goose StatementEnd
@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS organizations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(100) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_slug_live ON organizations (slug) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations (deleted_at);
+
+-- roles without an organization stay global; role names are unique per scope
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS organization_id INT DEFAULT NULL REFERENCES organizations(id);
+CREATE INDEX IF NOT EXISTS idx_roles_organization_id ON roles (organization_id);
+DROP INDEX IF EXISTS idx_roles_name_live;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_name_global_live ON roles (name)
+WHERE deleted_at IS NULL AND organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_organization_name_live ON roles (organization_id, name)
+WHERE deleted_at IS NULL AND organization_id IS NOT NULL;
+
+-- assignments without an organization apply everywhere, the others only inside it
+ALTER TABLE user_role ADD COLUMN IF NOT EXISTS organization_id INT DEFAULT NULL REFERENCES organizations(id);
+CREATE INDEX IF NOT EXISTS idx_user_role_organization_id ON user_role (organization_id);
+DROP INDEX IF EXISTS idx_user_role_user_role_live;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_role_global_live ON user_role (user_id, role_id)
+WHERE deleted_at IS NULL AND organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_role_organization_live ON user_role (user_id, role_id, organization_id)
+WHERE deleted_at IS NULL AND organization_id IS NOT NULL;
+
+INSERT INTO permissions (name, description, resource, action) VALUES
+('organization:create', 'Create organizations', 'organization', 'create'),
+('organization:read', 'Read organizations and act in any of them', 'organization', 'read'),
+('organization:update', 'Update organizations', 'organization', 'update'),
+('organization:delete', 'Delete organizations', 'organization', 'delete'),
+('organization:*', 'All permissions on organizations', 'organization', '*');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE resource = 'organization');
+DELETE FROM permissions WHERE resource = 'organization';
+
+-- tenant scoped rows have no global meaning once the organizations are gone
+DELETE FROM user_role WHERE organization_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_user_role_organization_live;
+DROP INDEX IF EXISTS idx_user_role_global_live;
+DROP INDEX IF EXISTS idx_user_role_organization_id;
+ALTER TABLE user_role DROP COLUMN IF EXISTS organization_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_role_user_role_live ON user_role (user_id, role_id) WHERE deleted_at IS NULL;
+
+DELETE FROM roles WHERE organization_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_roles_organization_name_live;
+DROP INDEX IF EXISTS idx_roles_name_global_live;
+DROP INDEX IF EXISTS idx_roles_organization_id;
+ALTER TABLE roles DROP COLUMN IF EXISTS organization_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_name_live ON roles (name) WHERE deleted_at IS NULL;
+
+DROP TABLE IF EXISTS organizations;
+-- +goose StatementEnd
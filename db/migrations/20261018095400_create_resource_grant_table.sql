@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS resource_grants (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    permission_id INT NOT NULL,
+    resource_id VARCHAR(255) NOT NULL,
+    effect VARCHAR(10) NOT NULL DEFAULT 'allow',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE,
+    CONSTRAINT chk_resource_grants_effect CHECK (effect IN ('allow', 'deny'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resource_grants_user_permission_resource_live
+ON resource_grants (user_id, permission_id, resource_id)
+WHERE deleted_at IS NULL;
+-- permission checks look grants up by grantee and object
+CREATE INDEX IF NOT EXISTS idx_resource_grants_user_resource
+ON resource_grants (user_id, resource_id)
+WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_resource_grants_deleted_at ON resource_grants (deleted_at);
+
+INSERT INTO permissions (name, description, resource, action) VALUES
+('grant:create', 'Grant permissions on single objects', 'grant', 'create'),
+('grant:read', 'Read permissions granted on single objects', 'grant', 'read'),
+('grant:delete', 'Revoke permissions granted on single objects', 'grant', 'delete');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name IN ('grant:create', 'grant:read', 'grant:delete');
+DROP TABLE IF EXISTS resource_grants;
+-- +goose StatementEnd
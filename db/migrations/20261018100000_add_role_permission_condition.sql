@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE role_permissions ADD COLUMN IF NOT EXISTS condition TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE role_permissions DROP COLUMN IF EXISTS condition;
+-- +goose StatementEnd
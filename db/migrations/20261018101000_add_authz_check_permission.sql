@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO permissions (name, description, resource, action) VALUES
+('authz:check', 'Ask the authorization decision API whether any user may use a permission', 'authz', 'check');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE name = 'authz:check');
+DELETE FROM permissions WHERE name = 'authz:check';
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    id SERIAL PRIMARY KEY,
+    token_id VARCHAR(64) DEFAULT NULL,
+    user_id INT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- a row with a token_id revokes that jti; a row without one revokes every token
+-- of the user issued before it was created
+CREATE UNIQUE INDEX IF NOT EXISTS idx_revoked_tokens_token_id ON revoked_tokens (token_id);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_user_id ON revoked_tokens (user_id);
+-- expired rows are pruned
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_deleted_at ON revoked_tokens (deleted_at);
+
+INSERT INTO permissions (name, description, resource, action) VALUES
+('session:revoke', 'Revoke all sessions of any user', 'session', 'revoke');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE name = 'session:revoke');
+DELETE FROM permissions WHERE name = 'session:revoke';
+DROP TABLE IF EXISTS revoked_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS oidc_clients (
+    id SERIAL PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL,
+    redirect_uris TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oidc_clients_client_id ON oidc_clients (client_id);
+CREATE INDEX IF NOT EXISTS idx_oidc_clients_deleted_at ON oidc_clients (deleted_at);
+
+CREATE TABLE IF NOT EXISTS oidc_authorization_codes (
+    id SERIAL PRIMARY KEY,
+    code_hash VARCHAR(64) NOT NULL,
+    client_id VARCHAR(64) NOT NULL,
+    user_id INT NOT NULL,
+    redirect_uri TEXT NOT NULL,
+    scope VARCHAR(255) NOT NULL,
+    nonce VARCHAR(255) NOT NULL DEFAULT '',
+    code_challenge VARCHAR(128) NOT NULL DEFAULT '',
+    session_id VARCHAR(64) NOT NULL DEFAULT '',
+    auth_time TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL,
+    FOREIGN KEY (client_id) REFERENCES oidc_clients(client_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- only the SHA-256 of a code is stored; it is looked up by that hash
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oidc_authorization_codes_code_hash ON oidc_authorization_codes (code_hash);
+CREATE INDEX IF NOT EXISTS idx_oidc_authorization_codes_client_id ON oidc_authorization_codes (client_id);
+CREATE INDEX IF NOT EXISTS idx_oidc_authorization_codes_user_id ON oidc_authorization_codes (user_id);
+CREATE INDEX IF NOT EXISTS idx_oidc_authorization_codes_deleted_at ON oidc_authorization_codes (deleted_at);
+
+INSERT INTO permissions (name, description, resource, action) VALUES
+('oidc_client:create', 'Register OpenID Connect client applications', 'oidc_client', 'create'),
+('oidc_client:read', 'List OpenID Connect client applications', 'oidc_client', 'read'),
+('oidc_client:delete', 'Unregister OpenID Connect client applications', 'oidc_client', 'delete');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE name LIKE 'oidc_client:%');
+DELETE FROM permissions WHERE name LIKE 'oidc_client:%';
+DROP TABLE IF EXISTS oidc_authorization_codes;
+DROP TABLE IF EXISTS oidc_clients;
+-- +goose StatementEnd
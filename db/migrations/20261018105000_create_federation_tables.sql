@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS linked_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    provider VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    last_login_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- an identity of a provider is linked to one user at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_linked_identities_subject_live ON linked_identities (provider, subject) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_linked_identities_user_id ON linked_identities (user_id);
+CREATE INDEX IF NOT EXISTS idx_linked_identities_deleted_at ON linked_identities (deleted_at);
+
+CREATE TABLE IF NOT EXISTS federation_logins (
+    id SERIAL PRIMARY KEY,
+    state_hash VARCHAR(64) NOT NULL,
+    provider VARCHAR(64) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    organization_id INT DEFAULT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE
+);
+
+-- only the SHA-256 of a state is stored; the callback looks the login up by that hash
+CREATE UNIQUE INDEX IF NOT EXISTS idx_federation_logins_state_hash ON federation_logins (state_hash);
+CREATE INDEX IF NOT EXISTS idx_federation_logins_deleted_at ON federation_logins (deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS federation_logins;
+DROP TABLE IF EXISTS linked_identities;
+-- +goose StatementEnd
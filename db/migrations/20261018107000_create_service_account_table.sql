@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- service accounts are identities too: they hold roles through user_roles like people do
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kind VARCHAR(32) NOT NULL DEFAULT 'user';
+
+CREATE TABLE IF NOT EXISTS service_accounts (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    client_id VARCHAR(64) NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_by INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_accounts_user_id ON service_accounts (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_accounts_client_id ON service_accounts (client_id);
+CREATE INDEX IF NOT EXISTS idx_service_accounts_deleted_at ON service_accounts (deleted_at);
+
+INSERT INTO permissions (name, description, resource, action) VALUES
+('service_account:create', 'Create service accounts', 'service_account', 'create'),
+('service_account:read', 'List service accounts and their API keys', 'service_account', 'read'),
+('service_account:update', 'Rotate the secrets and manage the API keys of service accounts', 'service_account', 'update'),
+('service_account:delete', 'Delete service accounts', 'service_account', 'delete');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE name LIKE 'service_account:%');
+DELETE FROM permissions WHERE name LIKE 'service_account:%';
+DROP TABLE IF EXISTS service_accounts;
+UPDATE users SET deleted_at = NOW() WHERE kind = 'service_account' AND deleted_at IS NULL;
+ALTER TABLE users DROP COLUMN IF EXISTS kind;
+-- +goose StatementEnd
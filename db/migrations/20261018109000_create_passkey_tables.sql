@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS passkey_credentials (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    credential_id VARCHAR(1400) NOT NULL,
+    user_handle VARCHAR(128) NOT NULL,
+    public_key BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    transports VARCHAR(255) NOT NULL DEFAULT '',
+    last_used_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_passkey_credentials_credential_id ON passkey_credentials (credential_id);
+CREATE INDEX IF NOT EXISTS idx_passkey_credentials_user_id ON passkey_credentials (user_id);
+CREATE INDEX IF NOT EXISTS idx_passkey_credentials_deleted_at ON passkey_credentials (deleted_at);
+
+CREATE TABLE IF NOT EXISTS passkey_ceremonies (
+    id SERIAL PRIMARY KEY,
+    challenge_hash VARCHAR(64) NOT NULL,
+    purpose VARCHAR(32) NOT NULL,
+    user_id INT DEFAULT NULL,
+    user_handle VARCHAR(128) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_passkey_ceremonies_challenge_hash ON passkey_ceremonies (challenge_hash);
+CREATE INDEX IF NOT EXISTS idx_passkey_ceremonies_user_id ON passkey_ceremonies (user_id);
+CREATE INDEX IF NOT EXISTS idx_passkey_ceremonies_deleted_at ON passkey_ceremonies (deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS passkey_ceremonies;
+DROP TABLE IF EXISTS passkey_credentials;
+-- +goose StatementEnd
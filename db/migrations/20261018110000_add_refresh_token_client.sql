@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the OIDC client a token family was issued to; NULL for sessions started by /login
+ALTER TABLE refresh_tokens
+ADD COLUMN IF NOT EXISTS client_id VARCHAR(64) DEFAULT NULL
+REFERENCES oidc_clients(client_id) ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS client_id;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a global role is only assigned globally: an assignment inside an organization
+-- would give its grants to global resources on that organization's say
+UPDATE user_role SET deleted_at = NOW()
+WHERE deleted_at IS NULL AND organization_id IS NOT NULL
+AND role_id IN (SELECT id FROM roles WHERE organization_id IS NULL);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- the dropped assignments stay soft-deleted: restoring them would reopen the escape
+SELECT 1;
+-- +goose StatementEnd
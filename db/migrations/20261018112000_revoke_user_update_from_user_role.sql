@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- user:update on every account stays with admin; everyone else updates their own
+-- account through ownership, or another one through a grant on it
+UPDATE role_permissions SET deleted_at = NOW(), updated_at = NOW()
+WHERE deleted_at IS NULL
+AND role_id IN (SELECT id FROM roles WHERE name = 'user' AND organization_id IS NULL)
+AND permission_id IN (SELECT id FROM permissions WHERE name = 'user:update');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'user' AND r.organization_id IS NULL AND r.deleted_at IS NULL
+AND p.name = 'user:update' AND p.deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.role_id = r.id AND rp.permission_id = p.id AND rp.deleted_at IS NULL
+);
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+package apikey
+
+import "time"
+
+type CreateApiKeyRequest struct {
+	Name           string   `json:"name" validate:"required,max=255"`
+	Scopes         []string `json:"scopes" validate:"required"`
+	ExpiresInDays  int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+	OrganizationID int64    `json:"organization_id"`
+}
+
+// CreateApiKeyResponse carries the key itself, which is never shown again.
+type CreateApiKeyResponse struct {
+	Key    string      `json:"key"`
+	ApiKey *ApiKeyView `json:"api_key"`
+}
+
+// ApiKeyView is a key as listed to its owner, without its hash.
+type ApiKeyView struct {
+	ID             uint       `json:"id"`
+	Name           string     `json:"name"`
+	Prefix         string     `json:"prefix"`
+	Scopes         []string   `json:"scopes"`
+	OrganizationID *uint      `json:"organization_id"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
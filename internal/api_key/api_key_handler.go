@@ -0,0 +1,103 @@
+package apikey
+
+import (
+	"errors"
+	"fmt"
+	"go_project_structure/internal/token"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ApiKeyController struct {
+	ApiKeyService ApiKeyService
+}
+
+func NewApiKeyController(_apiKeyService ApiKeyService) *ApiKeyController {
+	return &ApiKeyController{
+		ApiKeyService: _apiKeyService,
+	}
+}
+
+func NewApiKeyView(apiKey *ApiKey) *ApiKeyView {
+	return &ApiKeyView{
+		ID:             apiKey.ID,
+		Name:           apiKey.Name,
+		Prefix:         apiKey.Prefix,
+		Scopes:         strings.Fields(apiKey.Scopes),
+		OrganizationID: apiKey.OrganizationID,
+		ExpiresAt:      apiKey.ExpiresAt,
+		LastUsedAt:     apiKey.LastUsedAt,
+		CreatedAt:      apiKey.CreatedAt,
+	}
+}
+
+func (kc *ApiKeyController) CreateApiKey(w http.ResponseWriter, r *http.Request) {
+	principal, ok := token.PrincipalFromContext(r.Context())
+	if !ok {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", errors.New("missing principal"))
+		return
+	}
+	// a leaked key must not be able to mint its own replacements
+	if principal.ApiKeyID != 0 {
+		utils.WriteJsonErrorResponse(w, http.StatusForbidden, "API keys cannot create API keys", errors.New("log in with a session to create API keys"))
+		return
+	}
+	requestPayload := r.Context().Value("create_api_key_payload").(CreateApiKeyRequest)
+
+	key, apiKey, err := kc.ApiKeyService.Create(principal.UserID, requestPayload.Name, requestPayload.Scopes, requestPayload.ExpiresInDays, requestPayload.OrganizationID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrScopeNotPermitted) || errors.Is(err, ErrNotMember) {
+			status = http.StatusForbidden
+		}
+		utils.WriteJsonErrorResponse(w, status, "API key creation failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusCreated, "API key created; store it now, it is not shown again", &CreateApiKeyResponse{
+		Key:    key,
+		ApiKey: NewApiKeyView(apiKey),
+	})
+}
+
+func (kc *ApiKeyController) GetApiKeys(w http.ResponseWriter, r *http.Request) {
+	principal, ok := token.PrincipalFromContext(r.Context())
+	if !ok {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", errors.New("missing principal"))
+		return
+	}
+
+	apiKeys, err := kc.ApiKeyService.List(principal.UserID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "API keys fetch failed.", err)
+		return
+	}
+	views := []*ApiKeyView{}
+	for _, apiKey := range apiKeys {
+		views = append(views, NewApiKeyView(apiKey))
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get api keys end point", views)
+}
+
+func (kc *ApiKeyController) RevokeApiKey(w http.ResponseWriter, r *http.Request) {
+	principal, ok := token.PrincipalFromContext(r.Context())
+	if !ok {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", errors.New("missing principal"))
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid api key id", fmt.Errorf("invalid id"))
+		return
+	}
+
+	err = kc.ApiKeyService.Revoke(id, principal.UserID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusNotFound, "API key revoke failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "API key revoked successfully", nil)
+}
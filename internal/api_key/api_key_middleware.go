@@ -0,0 +1,52 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strings"
+)
+
+func CreateApiKeyRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = CreateApiKeyRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("create api key payload received.")
+
+		RequestPayload.Name = strings.TrimSpace(RequestPayload.Name)
+		if RequestPayload.Name == "" || len(RequestPayload.Name) > 255 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("name is required and must be at most 255 characters"))
+			return
+		}
+		if len(RequestPayload.Scopes) == 0 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("scopes must list at least one permission"))
+			return
+		}
+		for i, scope := range RequestPayload.Scopes {
+			scope = strings.TrimSpace(scope)
+			if scope == "" || strings.ContainsAny(scope, " \t\n") {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("scopes must be permission names"))
+				return
+			}
+			RequestPayload.Scopes[i] = scope
+		}
+		if RequestPayload.ExpiresInDays < 0 || RequestPayload.ExpiresInDays > MaxApiKeyDays {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("expires_in_days must be between 1 and %d", MaxApiKeyDays))
+			return
+		}
+		if RequestPayload.OrganizationID < 0 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("organization_id must be a positive integer"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "create_api_key_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
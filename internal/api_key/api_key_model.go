@@ -0,0 +1,24 @@
+package apikey
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ApiKey is a named key a user minted for scripts and CI jobs. Prefix is the public
+// part of the key it is looked up by; only the SHA-256 of the whole key is stored.
+// The key can only use the permissions in Scopes, and only while its user holds them.
+type ApiKey struct {
+	gorm.Model
+	UserID  uint   `gorm:"not null;index"`
+	Name    string `gorm:"size:255;not null"`
+	Prefix  string `gorm:"size:32;not null;uniqueIndex"`
+	KeyHash string `gorm:"size:64;not null"`
+	// Scopes are the permission names the key may use, space separated
+	Scopes string `gorm:"not null"`
+	// OrganizationID pins the requests the key authenticates to an organization
+	OrganizationID *uint
+	ExpiresAt      *time.Time
+	LastUsedAt     *time.Time
+}
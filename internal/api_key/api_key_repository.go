@@ -0,0 +1,173 @@
+package apikey
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ApiKeyRepository interface {
+	Create(userId int64, name string, prefix string, keyHash string, scopes string, organizationId int64, expiresAt *time.Time) (*ApiKey, error)
+	GetUserKeys(userId int64) ([]*ApiKey, error)
+	GetActiveByPrefix(prefix string) (*ApiKey, *ApiKeyOwner, error)
+	Revoke(id int64, userId int64) error
+	TouchLastUsed(id uint) error
+}
+
+// ApiKeyOwner is the user or service account a key belongs to.
+type ApiKeyOwner struct {
+	Email string
+	Kind  string
+}
+
+type ApiKeyRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewApiKeyRepository(_db *gorm.DB) ApiKeyRepository {
+	return &ApiKeyRepositoryImpl{
+		db: _db,
+	}
+}
+
+const apiKeyColumns = "k.id, k.user_id, k.name, k.prefix, k.key_hash, k.scopes, k.organization_id, k.expires_at, k.last_used_at, k.created_at, k.updated_at"
+
+func (u *ApiKeyRepositoryImpl) Create(userId int64, name string, prefix string, keyHash string, scopes string, organizationId int64, expiresAt *time.Time) (*ApiKey, error) {
+	fmt.Println("Creating api key in apiKey repository.")
+
+	// step 1: prepare the query
+	query := `INSERT INTO api_keys AS k (user_id, name, prefix, key_hash, scopes, organization_id, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + apiKeyColumns
+
+	// step 2: execute the query
+	row := u.db.Raw(query, userId, name, prefix, keyHash, scopes, organizationScope(organizationId), expiresAt).Row()
+
+	// step 3: process the result
+	apiKey, err := scanApiKey(row)
+	if err != nil {
+		fmt.Printf("Error creating api key: %v\n", err)
+		return nil, err
+	}
+
+	// step 4: return the result
+	fmt.Printf("Created api key %s for user %d\n", prefix, userId)
+	return apiKey, nil
+}
+
+func (u *ApiKeyRepositoryImpl) GetUserKeys(userId int64) ([]*ApiKey, error) {
+	fmt.Println("Fetching api keys of user in apiKey repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + apiKeyColumns + " FROM api_keys k WHERE k.deleted_at IS NULL AND k.user_id = ? ORDER BY k.id"
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query, userId).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	apiKeys := []*ApiKey{}
+	for rows.Next() {
+		apiKey := &ApiKey{}
+		err := rows.Scan(&apiKey.ID, &apiKey.UserID, &apiKey.Name, &apiKey.Prefix, &apiKey.KeyHash, &apiKey.Scopes, &apiKey.OrganizationID,
+			&apiKey.ExpiresAt, &apiKey.LastUsedAt, &apiKey.CreatedAt, &apiKey.UpdatedAt)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	// step 4: return the result
+	return apiKeys, rows.Err()
+}
+
+// GetActiveByPrefix returns the unexpired key with the prefix along with its owner.
+// Keys of deleted users are not returned.
+func (u *ApiKeyRepositoryImpl) GetActiveByPrefix(prefix string) (*ApiKey, *ApiKeyOwner, error) {
+	// step 1: prepare the query
+	query := `SELECT ` + apiKeyColumns + `, usr.email, usr.kind FROM api_keys k
+		JOIN users usr ON usr.id = k.user_id AND usr.deleted_at IS NULL
+		WHERE k.deleted_at IS NULL AND k.prefix = ? AND (k.expires_at IS NULL OR k.expires_at > NOW())`
+
+	// step 2: execute the query
+	row := u.db.Raw(query, prefix).Row()
+
+	// step 3: process the result
+	apiKey := &ApiKey{}
+	owner := &ApiKeyOwner{}
+	err := row.Scan(&apiKey.ID, &apiKey.UserID, &apiKey.Name, &apiKey.Prefix, &apiKey.KeyHash, &apiKey.Scopes, &apiKey.OrganizationID,
+		&apiKey.ExpiresAt, &apiKey.LastUsedAt, &apiKey.CreatedAt, &apiKey.UpdatedAt, &owner.Email, &owner.Kind)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// step 4: return the result
+	return apiKey, owner, nil
+}
+
+// Revoke deletes a key of the user.
+func (u *ApiKeyRepositoryImpl) Revoke(id int64, userId int64) error {
+	fmt.Println("Revoking api key in apiKey repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE api_keys SET deleted_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND id = ? AND user_id = ?"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, id, userId)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error revoking api key: %v\n", result.Error)
+		return result.Error
+	}
+
+	// step 4: evaluate the result
+	if result.RowsAffected == 0 {
+		fmt.Println("No api key was revoked.")
+		return fmt.Errorf("No api key was revoked.")
+	}
+
+	// step 5: return the result
+	return nil
+}
+
+// TouchLastUsed records a use of the key. Uses within a minute of the recorded one
+// are not written, which keeps busy keys from writing on every request.
+func (u *ApiKeyRepositoryImpl) TouchLastUsed(id uint) error {
+	// step 1: prepare the query
+	query := "UPDATE api_keys SET last_used_at = NOW() WHERE id = ? AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, id)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error recording api key use: %v\n", result.Error)
+		return result.Error
+	}
+	return nil
+}
+
+// organizationScope turns the "no organization" zero value into NULL.
+func organizationScope(organizationId int64) interface{} {
+	if organizationId == 0 {
+		return nil
+	}
+	return organizationId
+}
+
+func scanApiKey(row *sql.Row) (*ApiKey, error) {
+	apiKey := &ApiKey{}
+	err := row.Scan(&apiKey.ID, &apiKey.UserID, &apiKey.Name, &apiKey.Prefix, &apiKey.KeyHash, &apiKey.Scopes, &apiKey.OrganizationID,
+		&apiKey.ExpiresAt, &apiKey.LastUsedAt, &apiKey.CreatedAt, &apiKey.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
@@ -0,0 +1,183 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go_project_structure/internal/permission"
+	"go_project_structure/internal/token"
+	userrole "go_project_structure/internal/user_role"
+	"strings"
+	"time"
+)
+
+var (
+	ErrApiKeyInvalid     = errors.New("API key is invalid or expired")
+	ErrScopeNotPermitted = errors.New("API key scopes must be covered by permissions the user holds")
+	ErrNotMember         = errors.New("user is not a member of the organization")
+)
+
+const (
+	// DefaultApiKeyDays is the lifetime of a key created without one.
+	DefaultApiKeyDays = 90
+	MaxApiKeyDays     = 365
+)
+
+type ApiKeyService interface {
+	Create(userId int64, name string, scopes []string, expiresInDays int, organizationId int64) (string, *ApiKey, error)
+	List(userId int64) ([]*ApiKey, error)
+	Revoke(id int64, userId int64) error
+	Authenticate(apiKey string) (*token.Principal, error)
+}
+
+type ApiKeyServiceImpl struct {
+	apiKeyRepository   ApiKeyRepository
+	userRoleRepository userrole.UserRoleRepository
+}
+
+func NewApiKeyService(_apiKeyRepository ApiKeyRepository, _userRoleRepository userrole.UserRoleRepository) ApiKeyService {
+	return &ApiKeyServiceImpl{
+		apiKeyRepository:   _apiKeyRepository,
+		userRoleRepository: _userRoleRepository,
+	}
+}
+
+// Create mints a key limited to scopes, which the user's grants in the organization
+// must cover, wildcard scopes included. The key itself is returned only here.
+func (ks *ApiKeyServiceImpl) Create(userId int64, name string, scopes []string, expiresInDays int, organizationId int64) (string, *ApiKey, error) {
+	fmt.Println("Creating api key in apiKey service.")
+	if organizationId != 0 {
+		member, err := ks.userRoleRepository.IsOrganizationMember(userId, organizationId)
+		if err != nil {
+			return "", nil, err
+		}
+		if !member {
+			return "", nil, ErrNotMember
+		}
+	}
+
+	// a scope may be a wildcard such as user:*, and is allowed when the user's
+	// grants cover all of it: * covers user:read, user:read does not cover user:*
+	attributes := permission.NewAttributes(userId, "", "", "")
+	for _, scope := range scopes {
+		allowed, err := ks.userRoleRepository.HasPermission(userId, organizationId, scope, attributes)
+		if err != nil {
+			fmt.Printf("Error checking scope %s: %v\n", scope, err)
+			return "", nil, err
+		}
+		if !allowed {
+			return "", nil, fmt.Errorf("%w: %s", ErrScopeNotPermitted, scope)
+		}
+	}
+
+	if expiresInDays == 0 {
+		expiresInDays = DefaultApiKeyDays
+	}
+	expiresAt := time.Now().AddDate(0, 0, expiresInDays)
+
+	id, err := randomString(6)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomString(32)
+	if err != nil {
+		return "", nil, err
+	}
+	prefix := token.ApiKeyPrefix + id
+	key := prefix + "_" + secret
+
+	apiKey, err := ks.apiKeyRepository.Create(userId, name, prefix, hashKey(key), strings.Join(scopes, " "), organizationId, &expiresAt)
+	if err != nil {
+		fmt.Printf("Error creating api key: %v\n", err)
+		return "", nil, err
+	}
+	return key, apiKey, nil
+}
+
+func (ks *ApiKeyServiceImpl) List(userId int64) ([]*ApiKey, error) {
+	fmt.Println("Fetching api keys in apiKey service.")
+	return ks.apiKeyRepository.GetUserKeys(userId)
+}
+
+func (ks *ApiKeyServiceImpl) Revoke(id int64, userId int64) error {
+	fmt.Println("Revoking api key in apiKey service.")
+	return ks.apiKeyRepository.Revoke(id, userId)
+}
+
+// Authenticate is the token.ApiKeyAuthenticator of the keys. The caller it returns
+// is limited to the scopes of the key and to its organization.
+func (ks *ApiKeyServiceImpl) Authenticate(key string) (*token.Principal, error) {
+	prefix, _, ok := cutPrefix(key)
+	if !ok {
+		return nil, ErrApiKeyInvalid
+	}
+
+	apiKey, owner, err := ks.apiKeyRepository.GetActiveByPrefix(prefix)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrApiKeyInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashKey(key)), []byte(apiKey.KeyHash)) != 1 {
+		return nil, ErrApiKeyInvalid
+	}
+
+	if err := ks.apiKeyRepository.TouchLastUsed(apiKey.ID); err != nil {
+		fmt.Printf("Error recording use of api key %d: %v\n", apiKey.ID, err)
+	}
+
+	principal := &token.Principal{
+		UserID:      int64(apiKey.UserID),
+		Email:       owner.Email,
+		IssuedAt:    apiKey.CreatedAt,
+		ApiKeyID:    apiKey.ID,
+		SubjectType: owner.Kind,
+		// an empty scope list still restricts the key, to nothing
+		Scopes: strings.Fields(apiKey.Scopes),
+	}
+	if principal.Scopes == nil {
+		principal.Scopes = []string{}
+	}
+	principal.Permissions = principal.Scopes
+	if apiKey.OrganizationID != nil {
+		principal.OrganizationID = int64(*apiKey.OrganizationID)
+	}
+	if apiKey.ExpiresAt != nil {
+		principal.ExpiresAt = *apiKey.ExpiresAt
+	}
+	return principal, nil
+}
+
+// cutPrefix splits a key into its public prefix, gpsk_<id>, and its secret.
+func cutPrefix(key string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(key, token.ApiKeyPrefix)
+	if !ok {
+		return "", "", false
+	}
+	id, secret, ok := strings.Cut(rest, "_")
+	if !ok || id == "" || secret == "" {
+		return "", "", false
+	}
+	return token.ApiKeyPrefix + id, secret, true
+}
+
+// randomString returns n random bytes as unpadded url safe base64. Underscores are
+// replaced because they separate the parts of a key.
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(base64.RawURLEncoding.EncodeToString(buf), "_", "-"), nil
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
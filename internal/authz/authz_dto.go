@@ -0,0 +1,37 @@
+package authz
+
+import (
+	userrole "go_project_structure/internal/user_role"
+)
+
+// CheckRequest asks whether a subject may use a permission, optionally on one object
+// and inside an organization. Attributes add to or replace the request, resource and
+// time facts that grant conditions see, e.g. {"request": {"ip": "10.0.0.7"}}.
+type CheckRequest struct {
+	SubjectID      int64                             `json:"subject_id" validate:"required"`
+	Permission     string                            `json:"permission" validate:"required"`
+	ResourceID     string                            `json:"resource_id" validate:"omitempty,max=255"`
+	OrganizationID int64                             `json:"organization_id"`
+	Attributes     map[string]map[string]interface{} `json:"attributes"`
+}
+
+type BatchCheckRequest struct {
+	Checks []CheckRequest `json:"checks" validate:"required,max=100"`
+}
+
+// CheckResult answers a CheckRequest. Decision is "allow" or "deny"; the embedded
+// decision explains which grant and roles led to it. SubjectType is "user" or
+// "service_account", and empty for subjects that do not exist.
+type CheckResult struct {
+	SubjectID      int64  `json:"subject_id"`
+	SubjectType    string `json:"subject_type,omitempty"`
+	OrganizationID int64  `json:"organization_id,omitempty"`
+	Permission     string `json:"permission"`
+	ResourceID     string `json:"resource_id,omitempty"`
+	Decision       string `json:"decision"`
+	userrole.PermissionDecision
+}
+
+type BatchCheckResponse struct {
+	Results []*CheckResult `json:"results"`
+}
@@ -0,0 +1,181 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"go_project_structure/internal/authz/authzpb"
+	"go_project_structure/internal/permission"
+	userrole "go_project_structure/internal/user_role"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// AuthzGrpcServer serves authzpb.AuthzService on top of the services behind the
+// /authz and /users/{userId} HTTP routes. Callers are authenticated and authorized
+// by the interceptor the server is registered with.
+type AuthzGrpcServer struct {
+	authzpb.UnimplementedAuthzServiceServer
+	authzService    AuthzService
+	userRoleService userrole.UserRoleService
+}
+
+func NewAuthzGrpcServer(_authzService AuthzService, _userRoleService userrole.UserRoleService) *AuthzGrpcServer {
+	return &AuthzGrpcServer{
+		authzService:    _authzService,
+		userRoleService: _userRoleService,
+	}
+}
+
+func (gs *AuthzGrpcServer) Check(ctx context.Context, request *authzpb.CheckRequest) (*authzpb.CheckResponse, error) {
+	check, err := checkFromProto(request)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := scopeToTenant(&check, permission.TenantFromContext(ctx)); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	result, err := gs.authzService.Check(check, CallAttributes(ctx, 0))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "permission check failed")
+	}
+	return checkResultToProto(result), nil
+}
+
+func (gs *AuthzGrpcServer) BatchCheck(ctx context.Context, request *authzpb.BatchCheckRequest) (*authzpb.BatchCheckResponse, error) {
+	if len(request.GetChecks()) == 0 || len(request.GetChecks()) > maxBatchChecks {
+		return nil, status.Errorf(codes.InvalidArgument, "checks must hold between 1 and %d checks", maxBatchChecks)
+	}
+	checks := make([]CheckRequest, 0, len(request.GetChecks()))
+	for i, checkRequest := range request.GetChecks() {
+		check, err := checkFromProto(checkRequest)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "check %d: %v", i, err)
+		}
+		if err := scopeToTenant(&check, permission.TenantFromContext(ctx)); err != nil {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		checks = append(checks, check)
+	}
+
+	results, err := gs.authzService.CheckBatch(checks, CallAttributes(ctx, 0))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "permission check failed")
+	}
+	response := &authzpb.BatchCheckResponse{}
+	for _, result := range results {
+		response.Results = append(response.Results, checkResultToProto(result))
+	}
+	return response, nil
+}
+
+func (gs *AuthzGrpcServer) ListUserPermissions(ctx context.Context, request *authzpb.ListUserPermissionsRequest) (*authzpb.ListUserPermissionsResponse, error) {
+	if request.GetUserId() <= 0 || request.GetOrganizationId() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id must be positive and organization_id must not be negative")
+	}
+
+	organizationId, err := organizationInTenant(request.GetOrganizationId(), permission.TenantFromContext(ctx))
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	permissions, err := gs.userRoleService.GetUserPermissions(request.GetUserId(), organizationId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user permissions fetch failed")
+	}
+	response := &authzpb.ListUserPermissionsResponse{}
+	for _, p := range permissions {
+		response.Permissions = append(response.Permissions, &authzpb.Permission{
+			Id:          uint64(p.ID),
+			Name:        p.Name,
+			Description: p.Description,
+			Resource:    p.Resource,
+			Action:      p.Action,
+		})
+	}
+	return response, nil
+}
+
+func (gs *AuthzGrpcServer) ListUserRoles(ctx context.Context, request *authzpb.ListUserRolesRequest) (*authzpb.ListUserRolesResponse, error) {
+	if request.GetUserId() <= 0 || request.GetOrganizationId() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id must be positive and organization_id must not be negative")
+	}
+
+	organizationId, err := organizationInTenant(request.GetOrganizationId(), permission.TenantFromContext(ctx))
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	roles, err := gs.userRoleService.GetUserRoles(request.GetUserId(), organizationId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "user roles fetch failed")
+	}
+	response := &authzpb.ListUserRolesResponse{}
+	for _, r := range roles {
+		role := &authzpb.Role{
+			Id:          uint64(r.ID),
+			Name:        r.Name,
+			Description: r.Description,
+		}
+		if r.OrganizationID != nil {
+			role.OrganizationId = uint64(*r.OrganizationID)
+		}
+		response.Roles = append(response.Roles, role)
+	}
+	return response, nil
+}
+
+// CallAttributes builds the condition attributes of a gRPC call: the peer address
+// is the request ip, the full method name the request path, and the method POST,
+// as on the wire.
+func CallAttributes(ctx context.Context, userId int64) permission.Attributes {
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+	}
+	method, _ := grpc.Method(ctx)
+	return permission.NewAttributes(userId, remoteAddr, "POST", method)
+}
+
+// checkFromProto converts and validates a check the same way CheckRequestValidator does.
+func checkFromProto(request *authzpb.CheckRequest) (CheckRequest, error) {
+	check := CheckRequest{
+		SubjectID:      request.GetSubjectId(),
+		Permission:     request.GetPermission(),
+		ResourceID:     request.GetResourceId(),
+		OrganizationID: request.GetOrganizationId(),
+	}
+	if request.GetAttributes() != nil {
+		check.Attributes = map[string]map[string]interface{}{}
+		for namespace, value := range request.GetAttributes().AsMap() {
+			values, ok := value.(map[string]interface{})
+			if !ok {
+				return check, fmt.Errorf("attributes.%s must be an object", namespace)
+			}
+			check.Attributes[namespace] = values
+		}
+	}
+	return check, validateCheck(&check)
+}
+
+func checkResultToProto(result *CheckResult) *authzpb.CheckResponse {
+	return &authzpb.CheckResponse{
+		SubjectId:         result.SubjectID,
+		OrganizationId:    result.OrganizationID,
+		Permission:        result.Permission,
+		ResourceId:        result.ResourceID,
+		Allowed:           result.Allowed,
+		Decision:          result.Decision,
+		Effect:            result.Effect,
+		MatchedPermission: result.MatchedPermission,
+		MatchedCondition:  result.MatchedCondition,
+		MatchedRoles:      result.MatchedRoles,
+		ResourceGrant:     result.ResourceGrant,
+		Owner:             result.Owner,
+		SubjectType:       result.SubjectType,
+	}
+}
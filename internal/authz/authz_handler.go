@@ -0,0 +1,74 @@
+package authz
+
+import (
+	"fmt"
+	"go_project_structure/internal/permission"
+	utils "go_project_structure/utils"
+	"net/http"
+)
+
+type AuthzController struct {
+	AuthzService AuthzService
+}
+
+func NewAuthzController(_authzService AuthzService) *AuthzController {
+	return &AuthzController{
+		AuthzService: _authzService,
+	}
+}
+
+func (ac *AuthzController) Check(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("authz_check_payload").(CheckRequest)
+	if err := scopeToTenant(&requestPayload, permission.TenantFromContext(r.Context())); err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusForbidden, "Forbidden", err)
+		return
+	}
+
+	result, err := ac.AuthzService.Check(requestPayload, permission.RequestAttributes(r, requestPayload.SubjectID))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Permission check failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Authorization check end point", result)
+}
+
+func (ac *AuthzController) CheckBatch(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("authz_batch_check_payload").(BatchCheckRequest)
+	for i := range requestPayload.Checks {
+		if err := scopeToTenant(&requestPayload.Checks[i], permission.TenantFromContext(r.Context())); err != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusForbidden, "Forbidden", err)
+			return
+		}
+	}
+
+	results, err := ac.AuthzService.CheckBatch(requestPayload.Checks, permission.RequestAttributes(r, 0))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Permission check failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Authorization batch check end point", BatchCheckResponse{Results: results})
+}
+
+// scopeToTenant keeps the checks of a caller acting in a tenant inside it: checks
+// without an organization are made in the tenant, checks naming another one are
+// refused. Outside any tenant the caller passed the global authz:check guard.
+func scopeToTenant(check *CheckRequest, tenant int64) error {
+	organizationId, err := organizationInTenant(check.OrganizationID, tenant)
+	if err != nil {
+		return err
+	}
+	check.OrganizationID = organizationId
+	return nil
+}
+
+// organizationInTenant is the organization a caller acting in tenant asks about:
+// the tenant itself when none is named, and no other one.
+func organizationInTenant(organizationId int64, tenant int64) (int64, error) {
+	if tenant == 0 {
+		return organizationId, nil
+	}
+	if organizationId != 0 && organizationId != tenant {
+		return 0, fmt.Errorf("checks made in organization %d must stay in it", tenant)
+	}
+	return tenant, nil
+}
@@ -0,0 +1,81 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"go_project_structure/internal/permission"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strings"
+)
+
+// maxBatchChecks bounds the number of checks evaluated in one batch request.
+const maxBatchChecks = 100
+
+func CheckRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = CheckRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("authz check payload received.")
+
+		if err := validateCheck(&RequestPayload); err != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", err)
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "authz_check_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func BatchCheckRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = BatchCheckRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("authz batch check payload received.")
+
+		if len(RequestPayload.Checks) == 0 || len(RequestPayload.Checks) > maxBatchChecks {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("checks must hold between 1 and %d checks", maxBatchChecks))
+			return
+		}
+		for i := range RequestPayload.Checks {
+			if err := validateCheck(&RequestPayload.Checks[i]); err != nil {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("check %d: %w", i, err))
+				return
+			}
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "authz_batch_check_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validateCheck(check *CheckRequest) error {
+	if check.SubjectID <= 0 {
+		return fmt.Errorf("subject_id must be a positive integer")
+	}
+	if check.OrganizationID < 0 {
+		return fmt.Errorf("organization_id must not be negative")
+	}
+	check.Permission = strings.TrimSpace(check.Permission)
+	if _, _, err := permission.ParsePermissionName(check.Permission); err != nil {
+		return err
+	}
+	check.ResourceID = strings.TrimSpace(check.ResourceID)
+	if len(check.ResourceID) > 255 {
+		return fmt.Errorf("resource_id must be at most 255 characters")
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package authz
+
+import (
+	"fmt"
+	"go_project_structure/internal/permission"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
+	"strconv"
+)
+
+const (
+	DecisionAllow = "allow"
+	DecisionDeny  = "deny"
+)
+
+type AuthzService interface {
+	Check(check CheckRequest, attributes permission.Attributes) (*CheckResult, error)
+	CheckBatch(checks []CheckRequest, attributes permission.Attributes) ([]*CheckResult, error)
+}
+
+type AuthzServiceImpl struct {
+	userRoleService userrole.UserRoleService
+	userRepository  user.UserRepository
+}
+
+func NewAuthzService(_userRoleService userrole.UserRoleService, _userRepository user.UserRepository) AuthzService {
+	return &AuthzServiceImpl{
+		userRoleService: _userRoleService,
+		userRepository:  _userRepository,
+	}
+}
+
+// Check decides a single check. attributes are the facts of the incoming request;
+// the check's own attributes are laid over them and subject.id and subject.type
+// describe the checked subject.
+func (as *AuthzServiceImpl) Check(check CheckRequest, attributes permission.Attributes) (*CheckResult, error) {
+	fmt.Println("Checking permission in authz service.")
+	// subjects that do not exist hold no roles; the decision denies them on its own
+	subjectType := ""
+	if subject, err := as.userRepository.GetByID(strconv.FormatInt(check.SubjectID, 10)); err == nil {
+		subjectType = subject.Kind
+	}
+
+	decision, err := as.userRoleService.DecidePermission(check.SubjectID, check.OrganizationID, check.Permission, check.ResourceID, checkAttributes(check, subjectType, attributes))
+	if err != nil {
+		fmt.Printf("Error checking permission: %v\n", err)
+		return nil, err
+	}
+
+	result := &CheckResult{
+		SubjectID:          check.SubjectID,
+		SubjectType:        subjectType,
+		OrganizationID:     check.OrganizationID,
+		Permission:         check.Permission,
+		ResourceID:         check.ResourceID,
+		Decision:           DecisionDeny,
+		PermissionDecision: *decision,
+	}
+	if decision.Allowed {
+		result.Decision = DecisionAllow
+	}
+	return result, nil
+}
+
+// CheckBatch decides every check in order. The first check that fails to evaluate
+// fails the whole batch, so callers never act on a partial answer.
+func (as *AuthzServiceImpl) CheckBatch(checks []CheckRequest, attributes permission.Attributes) ([]*CheckResult, error) {
+	fmt.Println("Checking permission batch in authz service.")
+	results := make([]*CheckResult, 0, len(checks))
+	for i, check := range checks {
+		result, err := as.Check(check, attributes)
+		if err != nil {
+			return nil, fmt.Errorf("check %d: %w", i, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// checkAttributes lays the attributes of a check over those of the request, key by key
+// within each namespace, and pins subject.id and subject.type to the checked subject.
+func checkAttributes(check CheckRequest, subjectType string, attributes permission.Attributes) permission.Attributes {
+	merged := permission.Attributes{}
+	for namespace, value := range attributes {
+		merged[namespace] = value
+	}
+	for namespace, values := range check.Attributes {
+		combined := map[string]interface{}{}
+		if existing, ok := merged[namespace].(map[string]interface{}); ok {
+			for key, value := range existing {
+				combined[key] = value
+			}
+		}
+		for key, value := range values {
+			combined[key] = value
+		}
+		merged[namespace] = combined
+	}
+
+	subject := map[string]interface{}{}
+	if existing, ok := merged["subject"].(map[string]interface{}); ok {
+		for key, value := range existing {
+			subject[key] = value
+		}
+	}
+	subject["id"] = check.SubjectID
+	subject["type"] = subjectType
+	merged["subject"] = subject
+	return merged
+}
@@ -0,0 +1,55 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Event is something that happened in a domain that other parts of the
+// application may want to react to, e.g. an expired role assignment.
+type Event struct {
+	Name       string                 `json:"name"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+type Handler func(event Event)
+
+// Bus delivers published events synchronously to every subscribed handler.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// DefaultBus is the bus the application publishes domain events on.
+var DefaultBus = NewBus()
+
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+func (b *Bus) Publish(event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// LogHandler prints every event it receives.
+func LogHandler(event Event) {
+	fmt.Printf("event %s at %s: %v\n", event.Name, event.OccurredAt.Format(time.RFC3339), event.Data)
+}
@@ -0,0 +1,116 @@
+package federation
+
+import (
+	"errors"
+	"go_project_structure/internal/token"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// stateCookie binds a login to the browser that started it, so that a callback
+// carrying someone else's state is refused.
+const stateCookie = "federation_state"
+
+type FederationController struct {
+	FederationService FederationService
+}
+
+func NewFederationController(_federationService FederationService) *FederationController {
+	return &FederationController{
+		FederationService: _federationService,
+	}
+}
+
+// Login sends the user to the provider. organization_id pins the session it starts,
+// as it does for /login.
+func (fc *FederationController) Login(w http.ResponseWriter, r *http.Request) {
+	providerName := chi.URLParam(r, "provider")
+	var organizationId int64
+	if value := r.URL.Query().Get("organization_id"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed <= 0 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid organization id", errors.New("organization_id must be a positive integer"))
+			return
+		}
+		organizationId = parsed
+	}
+
+	authorizationUrl, state, err := fc.FederationService.BeginLogin(providerName, organizationId)
+	if errors.Is(err, ErrUnknownProvider) {
+		utils.WriteJsonErrorResponse(w, http.StatusNotFound, "Login failed", err)
+		return
+	}
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadGateway, "Login failed", err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    state,
+		Path:     "/auth/" + providerName + "/callback",
+		MaxAge:   int(loginTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(CallbackURL(providerName), "https://"),
+		// the callback is a top-level navigation from the provider
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authorizationUrl, http.StatusFound)
+}
+
+// Callback completes the login the provider redirected back from and returns the
+// tokens of the new session.
+func (fc *FederationController) Callback(w http.ResponseWriter, r *http.Request) {
+	providerName := chi.URLParam(r, "provider")
+	query := r.URL.Query()
+	if upstreamErr := query.Get("error"); upstreamErr != "" {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Login failed", errors.New(upstreamErr+": "+query.Get("error_description")))
+		return
+	}
+
+	state := query.Get("state")
+	cookie, err := r.Cookie(stateCookie)
+	if err != nil || state == "" || cookie.Value != state || query.Get("code") == "" {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Login failed", ErrLoginStateInvalid)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: "/auth/" + providerName + "/callback", MaxAge: -1})
+
+	responsePayload, err := fc.FederationService.CompleteLogin(providerName, state, query.Get("code"))
+	if err != nil {
+		status := http.StatusUnauthorized
+		switch {
+		case errors.Is(err, ErrUnknownProvider):
+			status = http.StatusNotFound
+		case errors.Is(err, ErrLoginStateInvalid):
+			status = http.StatusBadRequest
+		}
+		utils.WriteJsonErrorResponse(w, status, "Login failed", err)
+		return
+	}
+	if responsePayload.MfaRequired {
+		utils.WriteJsonSuccessResponse(w, http.StatusOK, "MFA required; complete the login at /login/mfa", responsePayload)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Login successful", responsePayload)
+}
+
+// GetIdentities lists the identities linked to the caller.
+func (fc *FederationController) GetIdentities(w http.ResponseWriter, r *http.Request) {
+	principal, ok := token.PrincipalFromContext(r.Context())
+	if !ok {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", errors.New("missing principal"))
+		return
+	}
+
+	identities, err := fc.FederationService.GetUserIdentities(principal.UserID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Linked identity fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Linked identities fetched successfully", identities)
+}
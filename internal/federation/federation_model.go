@@ -0,0 +1,33 @@
+package federation
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LinkedIdentity links a user to their account at an upstream identity provider,
+// identified there by the sub claim of its ID tokens.
+type LinkedIdentity struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	Provider string `gorm:"size:64;not null;uniqueIndex:idx_linked_identities_subject_live,where:deleted_at IS NULL"`
+	Subject  string `gorm:"size:255;not null;uniqueIndex:idx_linked_identities_subject_live,where:deleted_at IS NULL"`
+	// Email is the address the provider last reported for the identity
+	Email       string `gorm:"size:255;not null;default:''"`
+	LastLoginAt *time.Time
+}
+
+// FederationLogin is a login started at an upstream provider and not completed yet.
+// It is looked up by the SHA-256 of its state and carries what the callback needs
+// to verify the response: the nonce and the PKCE code verifier.
+type FederationLogin struct {
+	gorm.Model
+	StateHash      string `gorm:"size:64;not null;uniqueIndex"`
+	Provider       string `gorm:"size:64;not null"`
+	Nonce          string `gorm:"size:64;not null"`
+	CodeVerifier   string `gorm:"size:128;not null"`
+	OrganizationID *uint
+	ExpiresAt      time.Time `gorm:"not null"`
+	UsedAt         *time.Time
+}
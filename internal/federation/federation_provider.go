@@ -0,0 +1,438 @@
+package federation
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	env "go_project_structure/config/env"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Provider is an upstream OpenID Connect provider users can sign in through. It is
+// configured with FEDERATION_PROVIDERS, a comma separated list of names, and for each
+// name FEDERATION_<NAME>_* variables:
+//
+//	ISSUER          issuer URL; its discovery document describes the endpoints
+//	CLIENT_ID       client registered at the provider
+//	CLIENT_SECRET   secret of that client
+//	SCOPES          requested scopes, "openid email profile" when unset
+//	GROUPS_CLAIM    ID token claim listing the groups of the user, "groups" when unset
+//	ROLE_MAP        upstream group to global role, e.g. "engineering=developer,ops=admin"
+//	JIT             create a local user on the first login of an unknown identity
+//	LINK_BY_EMAIL   link an unknown identity to the local user with the same verified email
+//
+// The issuer must use https, except on the loopback interface so that a local stub
+// provider can stand in during development.
+type Provider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       string
+	GroupsClaim  string
+	// RoleMap maps upstream groups to the names of global roles
+	RoleMap     map[string][]string
+	JIT         bool
+	LinkByEmail bool
+
+	mu        sync.Mutex
+	discovery *providerMetadata
+	keys      map[string]interface{}
+	keysAt    time.Time
+}
+
+// providerMetadata is the part of the discovery document of a provider that is used.
+type providerMetadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JwksURI               string   `json:"jwks_uri"`
+	TokenEndpointAuth     []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// upstreamAlgorithms are the ID token signing algorithms accepted from providers.
+var upstreamAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+var (
+	providersOnce sync.Once
+	providers     map[string]*Provider
+	providersErr  error
+)
+
+// LoadProviders reads the configured providers. It is safe to call more than once;
+// the configuration is read the first time.
+func LoadProviders() (map[string]*Provider, error) {
+	providersOnce.Do(func() {
+		providers, providersErr = readProviders()
+	})
+	return providers, providersErr
+}
+
+func readProviders() (map[string]*Provider, error) {
+	configured := map[string]*Provider{}
+	for _, name := range strings.Split(env.GetString("FEDERATION_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "FEDERATION_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := &Provider{
+			Name:         name,
+			Issuer:       strings.TrimSuffix(env.GetString(prefix+"ISSUER", ""), "/"),
+			ClientID:     env.GetString(prefix+"CLIENT_ID", ""),
+			ClientSecret: env.GetString(prefix+"CLIENT_SECRET", ""),
+			Scopes:       env.GetString(prefix+"SCOPES", "openid email profile"),
+			GroupsClaim:  env.GetString(prefix+"GROUPS_CLAIM", "groups"),
+			RoleMap:      parseRoleMap(env.GetString(prefix+"ROLE_MAP", "")),
+			JIT:          env.GetBool(prefix+"JIT", false),
+			LinkByEmail:  env.GetBool(prefix+"LINK_BY_EMAIL", false),
+		}
+		if provider.ClientID == "" {
+			return nil, fmt.Errorf("%sCLIENT_ID is required", prefix)
+		}
+		if err := checkEndpoint(provider.Issuer); err != nil {
+			return nil, fmt.Errorf("%sISSUER: %w", prefix, err)
+		}
+		configured[name] = provider
+	}
+	return configured, nil
+}
+
+// parseRoleMap reads "group=role,group=role". A group may map to several roles.
+func parseRoleMap(value string) map[string][]string {
+	roleMap := map[string][]string{}
+	for _, entry := range strings.Split(value, ",") {
+		group, roleName, ok := strings.Cut(entry, "=")
+		group, roleName = strings.TrimSpace(group), strings.TrimSpace(roleName)
+		if !ok || group == "" || roleName == "" {
+			continue
+		}
+		roleMap[group] = append(roleMap[group], roleName)
+	}
+	return roleMap
+}
+
+// ManagedRoles are the roles the provider hands out; they follow the groups of the
+// user at every login.
+func (p *Provider) ManagedRoles() []string {
+	seen := map[string]bool{}
+	managed := []string{}
+	for _, roleNames := range p.RoleMap {
+		for _, roleName := range roleNames {
+			if !seen[roleName] {
+				seen[roleName] = true
+				managed = append(managed, roleName)
+			}
+		}
+	}
+	return managed
+}
+
+// checkEndpoint accepts https URLs, and http ones on the loopback interface.
+func checkEndpoint(endpoint string) error {
+	target, err := url.Parse(endpoint)
+	if err != nil || target.Host == "" {
+		return fmt.Errorf("%q is not an absolute URL", endpoint)
+	}
+	switch target.Scheme {
+	case "https":
+		return nil
+	case "http":
+		host := target.Hostname()
+		if host == "localhost" || host == "127.0.0.1" || host == "::1" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q must use https", endpoint)
+}
+
+// metadata fetches the discovery document of the provider once.
+func (p *Provider) metadata() (*providerMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	discovery := &providerMetadata{}
+	if err := getJSON(p.Issuer+"/.well-known/openid-configuration", discovery); err != nil {
+		return nil, fmt.Errorf("fetching the discovery document of %s: %w", p.Name, err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("%s reports issuer %q instead of %q", p.Name, discovery.Issuer, p.Issuer)
+	}
+	for _, endpoint := range []string{discovery.AuthorizationEndpoint, discovery.TokenEndpoint, discovery.JwksURI} {
+		if err := checkEndpoint(endpoint); err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Name, err)
+		}
+	}
+	p.discovery = discovery
+	return discovery, nil
+}
+
+// AuthorizationURL is where the user is sent to sign in at the provider.
+func (p *Provider) AuthorizationURL(redirectUri string, state string, nonce string, codeChallenge string) (string, error) {
+	discovery, err := p.metadata()
+	if err != nil {
+		return "", err
+	}
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {redirectUri},
+		"scope":                 {p.Scopes},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange redeems an authorization code at the token endpoint of the provider and
+// returns the ID token it issued.
+func (p *Provider) Exchange(code string, redirectUri string, codeVerifier string) (string, error) {
+	discovery, err := p.metadata()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectUri},
+		"code_verifier": {codeVerifier},
+	}
+	basic := len(discovery.TokenEndpointAuth) == 0
+	for _, method := range discovery.TokenEndpointAuth {
+		basic = basic || method == "client_secret_basic"
+	}
+	if !basic {
+		form.Set("client_id", p.ClientID)
+		form.Set("client_secret", p.ClientSecret)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if basic {
+		request.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&tokens); err != nil {
+		return "", fmt.Errorf("reading the token response of %s: %w", p.Name, err)
+	}
+	if response.StatusCode != http.StatusOK || tokens.Error != "" {
+		return "", fmt.Errorf("%s refused the code: %s %s", p.Name, tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return "", fmt.Errorf("%s returned no ID token", p.Name)
+	}
+	return tokens.IDToken, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID
+// token of the provider and returns its claims.
+func (p *Provider) VerifyIDToken(idToken string, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, p.verificationKey,
+		jwt.WithValidMethods(upstreamAlgorithms),
+		jwt.WithIssuer(p.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if claimNonce, _ := claims["nonce"].(string); claimNonce != nonce {
+		return nil, fmt.Errorf("the ID token nonce does not match")
+	}
+	if audience, _ := claims.GetAudience(); len(audience) > 1 {
+		if party, _ := claims["azp"].(string); party != p.ClientID {
+			return nil, fmt.Errorf("the ID token was issued to another client")
+		}
+	}
+	if subject, _ := claims.GetSubject(); subject == "" {
+		return nil, fmt.Errorf("the ID token has no subject")
+	}
+	return claims, nil
+}
+
+// Groups reads the groups claim of verified ID token claims.
+func (p *Provider) Groups(claims jwt.MapClaims) []string {
+	groups := []string{}
+	switch value := claims[p.GroupsClaim].(type) {
+	case string:
+		groups = append(groups, value)
+	case []interface{}:
+		for _, group := range value {
+			if name, ok := group.(string); ok {
+				groups = append(groups, name)
+			}
+		}
+	}
+	return groups
+}
+
+// verificationKey is the jwt.Keyfunc of the provider. An unknown kid refetches the
+// keys of the provider, at most once a minute, so that its rotations are picked up.
+func (p *Provider) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	keys, fetchedAt := p.keys, p.keysAt
+	p.mu.Unlock()
+	key, ok := lookupKey(keys, kid)
+	if !ok && time.Since(fetchedAt) > time.Minute {
+		discovery, err := p.metadata()
+		if err != nil {
+			return nil, err
+		}
+		keys, err = fetchKeys(discovery.JwksURI)
+		if err != nil {
+			return nil, fmt.Errorf("fetching the keys of %s: %w", p.Name, err)
+		}
+		p.mu.Lock()
+		p.keys, p.keysAt = keys, time.Now()
+		p.mu.Unlock()
+		key, ok = lookupKey(keys, kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey:
+		_, rsaOk := token.Method.(*jwt.SigningMethodRSA)
+		_, pssOk := token.Method.(*jwt.SigningMethodRSAPSS)
+		ok = rsaOk || pssOk
+	case *ecdsa.PublicKey:
+		_, ok = token.Method.(*jwt.SigningMethodECDSA)
+	case ed25519.PublicKey:
+		_, ok = token.Method.(*jwt.SigningMethodEd25519)
+	}
+	if !ok {
+		return nil, fmt.Errorf("signing key %q does not sign with %s", kid, token.Method.Alg())
+	}
+	return key, nil
+}
+
+// lookupKey finds a key by kid. A token without a kid can only use the sole key of
+// a provider that has one.
+func lookupKey(keys map[string]interface{}, kid string) (interface{}, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+	key, ok := keys[kid]
+	return key, ok
+}
+
+// fetchKeys reads the signature keys of a JWKS; other keys are skipped.
+func fetchKeys(jwksUri string) (map[string]interface{}, error) {
+	var jwks struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			Use     string `json:"use"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+			Curve   string `json:"crv"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := getJSON(jwksUri, &jwks); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]interface{}{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var key interface{}
+		switch jwk.KeyType {
+		case "RSA":
+			n, nErr := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, eErr := base64.RawURLEncoding.DecodeString(jwk.E)
+			if nErr != nil || eErr != nil || len(e) == 0 || len(e) > 4 {
+				continue
+			}
+			key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+			curve, ok := curves[jwk.Curve]
+			x, xErr := base64.RawURLEncoding.DecodeString(jwk.X)
+			y, yErr := base64.RawURLEncoding.DecodeString(jwk.Y)
+			if !ok || xErr != nil || yErr != nil {
+				continue
+			}
+			// the point is checked to be on the curve
+			ecdsaKey, err := ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+			if err != nil {
+				continue
+			}
+			key = ecdsaKey
+		case "OKP":
+			x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+			if jwk.Curve != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+				continue
+			}
+			key = ed25519.PublicKey(x)
+		default:
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	return keys, nil
+}
+
+func getJSON(endpoint string, result interface{}) error {
+	response, err := httpClient.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %s", endpoint, response.Status)
+	}
+	return json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(result)
+}
@@ -0,0 +1,286 @@
+package federation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	stubClientID     = "stub-client"
+	stubClientSecret = "stub-secret"
+	stubKeyID        = "stub-key"
+)
+
+// stubProvider is a local OpenID Connect provider: it serves a discovery document,
+// its JWKS and a token endpoint that redeems the codes the test hands out.
+type stubProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *ecdsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]stubCode
+}
+
+// stubCode is an authorization code the stub provider will redeem for an ID token
+// with claims, once the PKCE verifier matches challenge.
+type stubCode struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &stubProvider{t: t, key: key, codes: map[string]stubCode{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"issuer":                                stub.server.URL,
+			"authorization_endpoint":                stub.server.URL + "/authorize",
+			"token_endpoint":                        stub.server.URL + "/token",
+			"jwks_uri":                              stub.server.URL + "/jwks",
+			"token_endpoint_auth_methods_supported": []string{"client_secret_basic"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "EC",
+				"kid": stubKeyID,
+				"use": "sig",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(key.PublicKey.X.FillBytes(make([]byte, 32))),
+				"y":   base64.RawURLEncoding.EncodeToString(key.PublicKey.Y.FillBytes(make([]byte, 32))),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", stub.token)
+	stub.server = httptest.NewServer(mux)
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func (s *stubProvider) token(w http.ResponseWriter, r *http.Request) {
+	clientId, secret, ok := r.BasicAuth()
+	if !ok || clientId != stubClientID || secret != stubClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	s.mu.Lock()
+	code, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != code.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id_token": s.sign(code.claims, stubKeyID)})
+}
+
+// provider is a Provider configured against the stub.
+func (s *stubProvider) provider() *Provider {
+	return &Provider{
+		Name:         "stub",
+		Issuer:       s.server.URL,
+		ClientID:     stubClientID,
+		ClientSecret: stubClientSecret,
+		Scopes:       "openid email profile",
+		GroupsClaim:  "groups",
+		RoleMap:      map[string][]string{},
+	}
+}
+
+// claims are valid ID token claims for subject, issued to the stub client.
+func (s *stubProvider) claims(subject string, nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   s.server.URL,
+		"sub":   subject,
+		"aud":   stubClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": nonce,
+	}
+}
+
+// sign signs claims with the key of the stub, or with a fresh key the stub does not
+// publish when kid is not stubKeyID.
+func (s *stubProvider) sign(claims jwt.MapClaims, kid string) string {
+	key := s.key
+	if kid != stubKeyID {
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		key = other
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	idToken.Header["kid"] = kid
+	signed, err := idToken.SignedString(key)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return signed
+}
+
+// authorize plays the user signing in at the stub: the code it returns redeems for
+// an ID token with claims, given the PKCE verifier of the authorization URL.
+func (s *stubProvider) authorize(authorizationUrl string, claims jwt.MapClaims) string {
+	s.t.Helper()
+	if !strings.HasPrefix(authorizationUrl, s.server.URL+"/authorize?") {
+		s.t.Fatalf("authorization URL %q does not point at the stub", authorizationUrl)
+	}
+	code, err := randomToken(16)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	s.mu.Lock()
+	s.codes[code] = stubCode{challenge: queryParam(s.t, authorizationUrl, "code_challenge"), claims: claims}
+	s.mu.Unlock()
+	return code
+}
+
+func queryParam(t *testing.T, rawUrl string, name string) string {
+	t.Helper()
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Query().Get(name)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	stub := newStubProvider(t)
+	const nonce = "expected-nonce"
+
+	tests := []struct {
+		name    string
+		modify  func(claims jwt.MapClaims)
+		kid     string
+		wantErr bool
+	}{
+		{name: "valid token", modify: func(jwt.MapClaims) {}},
+		{name: "wrong nonce", modify: func(claims jwt.MapClaims) { claims["nonce"] = "other-nonce" }, wantErr: true},
+		{name: "missing nonce", modify: func(claims jwt.MapClaims) { delete(claims, "nonce") }, wantErr: true},
+		{name: "wrong audience", modify: func(claims jwt.MapClaims) { claims["aud"] = "other-client" }, wantErr: true},
+		{name: "several audiences with our azp", modify: func(claims jwt.MapClaims) {
+			claims["aud"] = []string{stubClientID, "other-client"}
+			claims["azp"] = stubClientID
+		}},
+		{name: "several audiences with another azp", modify: func(claims jwt.MapClaims) {
+			claims["aud"] = []string{stubClientID, "other-client"}
+			claims["azp"] = "other-client"
+		}, wantErr: true},
+		{name: "several audiences without azp", modify: func(claims jwt.MapClaims) {
+			claims["aud"] = []string{stubClientID, "other-client"}
+		}, wantErr: true},
+		{name: "wrong issuer", modify: func(claims jwt.MapClaims) { claims["iss"] = "https://idp.example.com" }, wantErr: true},
+		{name: "expired", modify: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }, wantErr: true},
+		{name: "no subject", modify: func(claims jwt.MapClaims) { delete(claims, "sub") }, wantErr: true},
+		{name: "unknown kid", modify: func(jwt.MapClaims) {}, kid: "unknown-key", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := stub.provider()
+			claims := stub.claims("user-1", nonce)
+			tt.modify(claims)
+			kid := tt.kid
+			if kid == "" {
+				kid = stubKeyID
+			}
+
+			verified, err := provider.VerifyIDToken(stub.sign(claims, kid), nonce)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("VerifyIDToken accepted the token: %v", verified)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken: %v", err)
+			}
+			if subject, _ := verified.GetSubject(); subject != "user-1" {
+				t.Errorf("subject = %q, want user-1", subject)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRejectsForeignSignature(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := stub.provider()
+
+	// a token carrying the published kid but signed by another key
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodES256, stub.claims("user-1", "nonce"))
+	forged.Header["kid"] = stubKeyID
+	signed, err := forged.SignedString(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.VerifyIDToken(signed, "nonce"); err == nil {
+		t.Fatal("VerifyIDToken accepted a token signed by another key")
+	}
+}
+
+func TestProviderExchange(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := stub.provider()
+
+	verifier := "a-code-verifier-long-enough-for-pkce-0123456789"
+	challenge := sha256.Sum256([]byte(verifier))
+	authorizationUrl, err := provider.AuthorizationURL("http://localhost/callback", "state", "nonce", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		t.Fatalf("AuthorizationURL: %v", err)
+	}
+	if got := queryParam(t, authorizationUrl, "client_id"); got != stubClientID {
+		t.Errorf("client_id = %q, want %q", got, stubClientID)
+	}
+
+	code := stub.authorize(authorizationUrl, stub.claims("user-1", "nonce"))
+	if _, err := provider.Exchange(code, "http://localhost/callback", "wrong-verifier"); err == nil {
+		t.Fatal("Exchange succeeded with the wrong code verifier")
+	}
+
+	code = stub.authorize(authorizationUrl, stub.claims("user-1", "nonce"))
+	idToken, err := provider.Exchange(code, "http://localhost/callback", verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if _, err := provider.VerifyIDToken(idToken, "nonce"); err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package federation
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type FederationRepository interface {
+	GetIdentity(provider string, subject string) (*LinkedIdentity, error)
+	GetUserIdentities(userId int64) ([]*LinkedIdentity, error)
+	LinkIdentity(userId int64, provider string, subject string, email string) (*LinkedIdentity, error)
+	RecordIdentityLogin(id uint, email string) error
+	CreateLogin(stateHash string, provider string, nonce string, codeVerifier string, organizationId int64, expiresAt time.Time) error
+	ConsumeLogin(stateHash string, provider string) (*FederationLogin, error)
+}
+
+type FederationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewFederationRepository(_db *gorm.DB) FederationRepository {
+	return &FederationRepositoryImpl{
+		db: _db,
+	}
+}
+
+const linkedIdentityColumns = "i.id, i.user_id, i.provider, i.subject, i.email, i.last_login_at, i.created_at, i.updated_at"
+
+func (u *FederationRepositoryImpl) GetIdentity(provider string, subject string) (*LinkedIdentity, error) {
+	fmt.Println("Fetching linked identity in federation repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + linkedIdentityColumns + " FROM linked_identities i WHERE i.deleted_at IS NULL AND i.provider = ? AND i.subject = ?"
+
+	// step 2: execute the query
+	row := u.db.Raw(query, provider, subject).Row()
+
+	// step 3: process the result
+	identity, err := scanLinkedIdentity(row)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			fmt.Printf("Error fetching linked identity: %v\n", err)
+		}
+		return nil, err
+	}
+
+	// step 4: return the result
+	return identity, nil
+}
+
+func (u *FederationRepositoryImpl) GetUserIdentities(userId int64) ([]*LinkedIdentity, error) {
+	fmt.Println("Fetching linked identities of user in federation repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + linkedIdentityColumns + " FROM linked_identities i WHERE i.deleted_at IS NULL AND i.user_id = ? ORDER BY i.id"
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query, userId).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	identities := []*LinkedIdentity{}
+	for rows.Next() {
+		identity := &LinkedIdentity{}
+		err := rows.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.LastLoginAt, &identity.CreatedAt, &identity.UpdatedAt)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	// step 4: return the result
+	return identities, rows.Err()
+}
+
+// LinkIdentity links the upstream identity to the user. The user must be live.
+func (u *FederationRepositoryImpl) LinkIdentity(userId int64, provider string, subject string, email string) (*LinkedIdentity, error) {
+	fmt.Println("Linking identity in federation repository.")
+
+	// step 1: prepare the query
+	query := `INSERT INTO linked_identities AS i (user_id, provider, subject, email, last_login_at)
+		SELECT u.id, ?, ?, ?, NOW() FROM users u WHERE u.id = ? AND u.deleted_at IS NULL
+		RETURNING ` + linkedIdentityColumns
+
+	// step 2: execute the query
+	row := u.db.Raw(query, provider, subject, email, userId).Row()
+
+	// step 3: process the result
+	identity, err := scanLinkedIdentity(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		fmt.Printf("Error linking identity: %v\n", err)
+		return nil, err
+	}
+
+	// step 4: return the result
+	fmt.Printf("Linked %s identity %s to user %d\n", provider, subject, userId)
+	return identity, nil
+}
+
+// RecordIdentityLogin stamps a login through the identity and keeps its email current.
+func (u *FederationRepositoryImpl) RecordIdentityLogin(id uint, email string) error {
+	fmt.Println("Recording identity login in federation repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE linked_identities SET email = ?, last_login_at = NOW(), updated_at = NOW() WHERE id = ?"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, email, id)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error recording identity login: %v\n", result.Error)
+		return result.Error
+	}
+	return nil
+}
+
+func (u *FederationRepositoryImpl) CreateLogin(stateHash string, provider string, nonce string, codeVerifier string, organizationId int64, expiresAt time.Time) error {
+	fmt.Println("Creating federation login in federation repository.")
+
+	// step 1: prepare the query
+	query := "INSERT INTO federation_logins (state_hash, provider, nonce, code_verifier, organization_id, expires_at) VALUES (?, ?, ?, ?, ?, ?)"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, stateHash, provider, nonce, codeVerifier, organizationScope(organizationId), expiresAt)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error creating federation login: %v\n", result.Error)
+		return result.Error
+	}
+	return nil
+}
+
+// ConsumeLogin uses up the pending login of the provider with the given state hash.
+// A login that is unknown, already completed or expired is refused with
+// ErrLoginStateInvalid.
+func (u *FederationRepositoryImpl) ConsumeLogin(stateHash string, provider string) (*FederationLogin, error) {
+	fmt.Println("Consuming federation login in federation repository.")
+
+	// step 1: prepare the query
+	query := `UPDATE federation_logins SET used_at = NOW(), updated_at = NOW()
+		WHERE deleted_at IS NULL AND state_hash = ? AND provider = ? AND used_at IS NULL AND expires_at > NOW()
+		RETURNING id, state_hash, provider, nonce, code_verifier, organization_id, expires_at, used_at, created_at, updated_at`
+
+	// step 2: execute the query
+	row := u.db.Raw(query, stateHash, provider).Row()
+
+	// step 3: process the result
+	login := &FederationLogin{}
+	err := row.Scan(&login.ID, &login.StateHash, &login.Provider, &login.Nonce, &login.CodeVerifier, &login.OrganizationID,
+		&login.ExpiresAt, &login.UsedAt, &login.CreatedAt, &login.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrLoginStateInvalid
+	}
+	if err != nil {
+		fmt.Printf("Error consuming federation login: %v\n", err)
+		return nil, err
+	}
+
+	// step 4: return the result
+	return login, nil
+}
+
+// organizationScope turns the "no organization" zero value into NULL.
+func organizationScope(organizationId int64) interface{} {
+	if organizationId == 0 {
+		return nil
+	}
+	return organizationId
+}
+
+func scanLinkedIdentity(row *sql.Row) (*LinkedIdentity, error) {
+	identity := &LinkedIdentity{}
+	err := row.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.LastLoginAt, &identity.CreatedAt, &identity.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
@@ -0,0 +1,289 @@
+package federation
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go_project_structure/internal/mfa"
+	"go_project_structure/internal/oidc"
+	"go_project_structure/internal/role"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
+	"go_project_structure/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownProvider   = errors.New("unknown identity provider")
+	ErrLoginStateInvalid = errors.New("the login is invalid or has expired")
+	ErrIdentityNotLinked = errors.New("no account is linked to this identity")
+)
+
+// loginTTL is how long a user has to sign in at the provider.
+const loginTTL = 10 * time.Minute
+
+type FederationService interface {
+	BeginLogin(providerName string, organizationId int64) (string, string, error)
+	CompleteLogin(providerName string, state string, code string) (*mfa.LoginResponse, error)
+	GetUserIdentities(userId int64) ([]*LinkedIdentity, error)
+}
+
+type FederationServiceImpl struct {
+	federationRepository FederationRepository
+	userRepository       user.UserRepository
+	userService          user.UserService
+	userRoleRepository   userrole.UserRoleRepository
+	roleRepository       role.RoleRepository
+	mfaService           mfa.MfaService
+}
+
+func NewFederationService(_federationRepository FederationRepository, _userRepository user.UserRepository, _userService user.UserService, _userRoleRepository userrole.UserRoleRepository, _roleRepository role.RoleRepository, _mfaService mfa.MfaService) FederationService {
+	return &FederationServiceImpl{
+		federationRepository: _federationRepository,
+		userRepository:       _userRepository,
+		userService:          _userService,
+		userRoleRepository:   _userRoleRepository,
+		roleRepository:       _roleRepository,
+		mfaService:           _mfaService,
+	}
+}
+
+// CallbackURL is the redirect URI registered at the provider.
+func CallbackURL(providerName string) string {
+	return oidc.Issuer() + "/auth/" + providerName + "/callback"
+}
+
+// BeginLogin starts a login at the provider and returns the URL to send the user to
+// along with the state, which the browser must present again at the callback.
+func (fs *FederationServiceImpl) BeginLogin(providerName string, organizationId int64) (string, string, error) {
+	fmt.Println("Beginning federated login in federation service.")
+	provider, err := lookupProvider(providerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	codeVerifier, err := randomToken(48)
+	if err != nil {
+		return "", "", err
+	}
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	authorizationUrl, err := provider.AuthorizationURL(CallbackURL(provider.Name), state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		fmt.Printf("Error building authorization url: %v\n", err)
+		return "", "", err
+	}
+	err = fs.federationRepository.CreateLogin(hashToken(state), provider.Name, nonce, codeVerifier, organizationId, time.Now().Add(loginTTL))
+	if err != nil {
+		return "", "", err
+	}
+	return authorizationUrl, state, nil
+}
+
+// CompleteLogin redeems the code the provider redirected back with, finds or
+// provisions the local user of the identity, aligns the roles the provider manages
+// with the groups of the user and starts a session, or challenges the user for a
+// second factor like a password login does.
+func (fs *FederationServiceImpl) CompleteLogin(providerName string, state string, code string) (*mfa.LoginResponse, error) {
+	fmt.Println("Completing federated login in federation service.")
+	provider, err := lookupProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	login, err := fs.federationRepository.ConsumeLogin(hashToken(state), provider.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := provider.Exchange(code, CallbackURL(provider.Name), login.CodeVerifier)
+	if err != nil {
+		fmt.Printf("Error exchanging code: %v\n", err)
+		return nil, err
+	}
+	claims, err := provider.VerifyIDToken(idToken, login.Nonce)
+	if err != nil {
+		fmt.Printf("Error verifying ID token: %v\n", err)
+		return nil, err
+	}
+
+	localUser, err := fs.resolveUser(provider, claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.syncRoles(provider, int64(localUser.ID), provider.Groups(claims)); err != nil {
+		fmt.Printf("Error syncing roles: %v\n", err)
+		return nil, err
+	}
+
+	var organizationId int64
+	if login.OrganizationID != nil {
+		organizationId = int64(*login.OrganizationID)
+	}
+	return fs.mfaService.BeginLogin(localUser, organizationId)
+}
+
+func (fs *FederationServiceImpl) GetUserIdentities(userId int64) ([]*LinkedIdentity, error) {
+	fmt.Println("Fetching linked identities in federation service.")
+	identities, err := fs.federationRepository.GetUserIdentities(userId)
+	if err != nil {
+		fmt.Printf("Error fetching linked identities: %v\n", err)
+		return nil, err
+	}
+	return identities, nil
+}
+
+// resolveUser finds the user linked to the identity. An identity seen for the first
+// time is linked to the user with its verified email when the provider may link by
+// email, or to a new user when it may provision users.
+func (fs *FederationServiceImpl) resolveUser(provider *Provider, claims jwt.MapClaims) (*user.User, error) {
+	subject, _ := claims.GetSubject()
+	email, _ := claims["email"].(string)
+	emailVerified, _ := claims["email_verified"].(bool)
+
+	identity, err := fs.federationRepository.GetIdentity(provider.Name, subject)
+	if err == nil {
+		if err := fs.federationRepository.RecordIdentityLogin(identity.ID, email); err != nil {
+			return nil, err
+		}
+		return fs.userRepository.GetByID(strconv.FormatUint(uint64(identity.UserID), 10))
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// an unverified email says nothing about who owns the account
+	if email == "" || !emailVerified {
+		return nil, ErrIdentityNotLinked
+	}
+	localUser, err := fs.userRepository.GetByEmail(email)
+	switch {
+	case err == nil && provider.LinkByEmail:
+	case err == nil:
+		return nil, ErrIdentityNotLinked
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	case !provider.JIT:
+		return nil, ErrIdentityNotLinked
+	default:
+		localUser, err = fs.provisionUser(email, claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := fs.federationRepository.LinkIdentity(int64(localUser.ID), provider.Name, subject, email); err != nil {
+		return nil, err
+	}
+	return localUser, nil
+}
+
+// provisionUser creates the local user of a new identity. It gets a random password
+// nobody knows, so it can only sign in through the provider.
+func (fs *FederationServiceImpl) provisionUser(email string, claims jwt.MapClaims) (*user.User, error) {
+	fmt.Println("Provisioning federated user in federation service.")
+	name, _ := claims["name"].(string)
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	password, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.userRepository.Create(name, email, passwordHash); err != nil {
+		fmt.Printf("Error provisioning user: %v\n", err)
+		return nil, err
+	}
+	return fs.userRepository.GetByEmail(email)
+}
+
+// syncRoles assigns the global roles the groups of the user map to and removes the
+// other roles the provider manages. Roles missing from the role map are left alone,
+// and so are assignments the user already holds, whatever their validity window.
+func (fs *FederationServiceImpl) syncRoles(provider *Provider, userId int64, groups []string) error {
+	managed := provider.ManagedRoles()
+	if len(managed) == 0 {
+		return nil
+	}
+	wanted := map[string]bool{}
+	for _, group := range groups {
+		for _, roleName := range provider.RoleMap[group] {
+			wanted[roleName] = true
+		}
+	}
+
+	assignments, err := fs.userRoleRepository.GetUserAssignments(userId, 0)
+	if err != nil {
+		return err
+	}
+	assigned := map[uint]bool{}
+	for _, assignment := range assignments {
+		if assignment.OrganizationID == nil {
+			assigned[assignment.RoleID] = true
+		}
+	}
+
+	for _, roleName := range managed {
+		managedRole, err := fs.roleRepository.GetByName(roleName, nil)
+		if err != nil {
+			fmt.Printf("Role %s of the %s role map does not exist\n", roleName, provider.Name)
+			continue
+		}
+		switch {
+		case wanted[roleName] && !assigned[managedRole.ID]:
+			err = fs.userRoleRepository.AssignRoleToUser(userId, int64(managedRole.ID), 0, nil, nil)
+		case !wanted[roleName] && assigned[managedRole.ID]:
+			err = fs.userRoleRepository.RemoveRoleFromUser(userId, int64(managedRole.ID), 0)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func lookupProvider(providerName string) (*Provider, error) {
+	configured, err := LoadProviders()
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := configured[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// randomToken returns size random bytes, URL-safe base64 encoded.
+func randomToken(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// hashToken is the SHA-256 of a secret as stored in the database.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,410 @@
+package federation
+
+import (
+	"database/sql"
+	"errors"
+	"go_project_structure/internal/mfa"
+	"go_project_structure/internal/role"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// fakeFederationRepository keeps identities and pending logins in memory.
+type fakeFederationRepository struct {
+	identities []*LinkedIdentity
+	logins     map[string]*FederationLogin
+}
+
+func (f *fakeFederationRepository) GetIdentity(provider string, subject string) (*LinkedIdentity, error) {
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeFederationRepository) GetUserIdentities(userId int64) ([]*LinkedIdentity, error) {
+	identities := []*LinkedIdentity{}
+	for _, identity := range f.identities {
+		if int64(identity.UserID) == userId {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (f *fakeFederationRepository) LinkIdentity(userId int64, provider string, subject string, email string) (*LinkedIdentity, error) {
+	identity := &LinkedIdentity{UserID: uint(userId), Provider: provider, Subject: subject, Email: email}
+	identity.ID = uint(len(f.identities) + 1)
+	f.identities = append(f.identities, identity)
+	return identity, nil
+}
+
+func (f *fakeFederationRepository) RecordIdentityLogin(id uint, email string) error {
+	return nil
+}
+
+func (f *fakeFederationRepository) CreateLogin(stateHash string, provider string, nonce string, codeVerifier string, organizationId int64, expiresAt time.Time) error {
+	f.logins[stateHash] = &FederationLogin{StateHash: stateHash, Provider: provider, Nonce: nonce, CodeVerifier: codeVerifier, ExpiresAt: expiresAt}
+	return nil
+}
+
+func (f *fakeFederationRepository) ConsumeLogin(stateHash string, provider string) (*FederationLogin, error) {
+	login, ok := f.logins[stateHash]
+	if !ok || login.Provider != provider || login.UsedAt != nil || !login.ExpiresAt.After(time.Now()) {
+		return nil, ErrLoginStateInvalid
+	}
+	now := time.Now()
+	login.UsedAt = &now
+	return login, nil
+}
+
+// fakeUserRepository keeps users in memory; methods CompleteLogin does not use
+// panic through the nil embedded interface.
+type fakeUserRepository struct {
+	user.UserRepository
+	users []*user.User
+}
+
+func (f *fakeUserRepository) Create(username string, email string, password string) error {
+	created := &user.User{Name: username, Email: email, Password: password, Kind: "user"}
+	created.ID = uint(len(f.users) + 1)
+	f.users = append(f.users, created)
+	return nil
+}
+
+func (f *fakeUserRepository) GetByID(id string) (*user.User, error) {
+	for _, u := range f.users {
+		if strconv.FormatUint(uint64(u.ID), 10) == id {
+			return u, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeUserRepository) GetByEmail(email string) (*user.User, error) {
+	for _, u := range f.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// fakeRoleRepository knows global roles by name.
+type fakeRoleRepository struct {
+	role.RoleRepository
+	roles []*role.Role
+}
+
+func (f *fakeRoleRepository) GetByName(name string, organizationId *int64) (*role.Role, error) {
+	for _, r := range f.roles {
+		if r.Name == name && organizationId == nil {
+			return r, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// fakeUserRoleRepository keeps global role assignments in memory.
+type fakeUserRoleRepository struct {
+	userrole.UserRoleRepository
+	assigned map[int64]map[int64]bool
+}
+
+func (f *fakeUserRoleRepository) GetUserAssignments(userId int64, organizationId int64) ([]*userrole.UserRole, error) {
+	assignments := []*userrole.UserRole{}
+	for roleId := range f.assigned[userId] {
+		assignments = append(assignments, &userrole.UserRole{UserID: uint(userId), RoleID: uint(roleId)})
+	}
+	return assignments, nil
+}
+
+func (f *fakeUserRoleRepository) AssignRoleToUser(userId int64, roleId int64, organizationId int64, validFrom *time.Time, validUntil *time.Time) error {
+	if f.assigned[userId] == nil {
+		f.assigned[userId] = map[int64]bool{}
+	}
+	f.assigned[userId][roleId] = true
+	return nil
+}
+
+func (f *fakeUserRoleRepository) RemoveRoleFromUser(userId int64, roleId int64, organizationId int64) error {
+	delete(f.assigned[userId], roleId)
+	return nil
+}
+
+// fakeMfaService starts a session for every login it is handed.
+type fakeMfaService struct {
+	mfa.MfaService
+	loggedIn []*user.User
+}
+
+func (f *fakeMfaService) BeginLogin(loggedIn *user.User, organizationId int64) (*mfa.LoginResponse, error) {
+	f.loggedIn = append(f.loggedIn, loggedIn)
+	return &mfa.LoginResponse{Token: "access-token-of-" + loggedIn.Email}, nil
+}
+
+// federationFixture is a FederationServiceImpl wired to the stub provider and to
+// in-memory repositories holding the global roles developer, admin and auditor.
+type federationFixture struct {
+	stub        *stubProvider
+	provider    *Provider
+	service     *FederationServiceImpl
+	federations *fakeFederationRepository
+	users       *fakeUserRepository
+	userRoles   *fakeUserRoleRepository
+	mfa         *fakeMfaService
+}
+
+var providersMu sync.Mutex
+
+func newFederationFixture(t *testing.T) *federationFixture {
+	t.Helper()
+	stub := newStubProvider(t)
+	provider := stub.provider()
+	provider.RoleMap = map[string][]string{"engineering": {"developer"}, "ops": {"admin"}}
+
+	// the providers are read from the environment once; the test puts the stub in
+	// their place
+	providersMu.Lock()
+	providersOnce.Do(func() {})
+	previous, previousErr := providers, providersErr
+	providers, providersErr = map[string]*Provider{provider.Name: provider}, nil
+	t.Cleanup(func() {
+		providers, providersErr = previous, previousErr
+		providersMu.Unlock()
+	})
+
+	roles := []*role.Role{}
+	for i, name := range []string{"developer", "admin", "auditor"} {
+		r := &role.Role{Model: gorm.Model{ID: uint(i + 1)}, Name: name}
+		roles = append(roles, r)
+	}
+
+	fixture := &federationFixture{
+		stub:        stub,
+		provider:    provider,
+		federations: &fakeFederationRepository{logins: map[string]*FederationLogin{}},
+		users:       &fakeUserRepository{},
+		userRoles:   &fakeUserRoleRepository{assigned: map[int64]map[int64]bool{}},
+		mfa:         &fakeMfaService{},
+	}
+	fixture.service = &FederationServiceImpl{
+		federationRepository: fixture.federations,
+		userRepository:       fixture.users,
+		userRoleRepository:   fixture.userRoles,
+		roleRepository:       &fakeRoleRepository{roles: roles},
+		mfaService:           fixture.mfa,
+	}
+	return fixture
+}
+
+// login runs a federated login through the stub: claims is called with the nonce of
+// the login and returns the claims of the ID token the provider issues.
+func (f *federationFixture) login(t *testing.T, claims func(nonce string) jwt.MapClaims) (*mfa.LoginResponse, error) {
+	t.Helper()
+	authorizationUrl, state, err := f.service.BeginLogin(f.provider.Name, 0)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	code := f.stub.authorize(authorizationUrl, claims(queryParam(t, authorizationUrl, "nonce")))
+	return f.service.CompleteLogin(f.provider.Name, state, code)
+}
+
+// identityClaims are the claims of an ID token for subject with a verified email.
+func (f *federationFixture) identityClaims(subject string, email string, groups ...string) func(nonce string) jwt.MapClaims {
+	return func(nonce string) jwt.MapClaims {
+		claims := f.stub.claims(subject, nonce)
+		claims["email"] = email
+		claims["email_verified"] = true
+		claims["name"] = "Ada Lovelace"
+		claims["groups"] = groups
+		return claims
+	}
+}
+
+func (f *federationFixture) roleNamesOf(userId uint) []string {
+	names := map[int64]string{1: "developer", 2: "admin", 3: "auditor"}
+	held := []string{}
+	for roleId := range f.userRoles.assigned[int64(userId)] {
+		held = append(held, names[roleId])
+	}
+	sort.Strings(held)
+	return held
+}
+
+func TestCompleteLoginProvisionsUser(t *testing.T) {
+	f := newFederationFixture(t)
+	f.provider.JIT = true
+
+	response, err := f.login(t, f.identityClaims("upstream-1", "ada@example.com", "engineering"))
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if response.Token != "access-token-of-ada@example.com" {
+		t.Errorf("token = %q, want a session of the provisioned user", response.Token)
+	}
+	if len(f.users.users) != 1 || f.users.users[0].Email != "ada@example.com" || f.users.users[0].Name != "Ada Lovelace" {
+		t.Fatalf("provisioned users = %+v, want ada@example.com", f.users.users)
+	}
+	identity, err := f.federations.GetIdentity("stub", "upstream-1")
+	if err != nil || identity.UserID != f.users.users[0].ID {
+		t.Fatalf("identity = %+v, %v, want it linked to the provisioned user", identity, err)
+	}
+	if got := f.roleNamesOf(f.users.users[0].ID); len(got) != 1 || got[0] != "developer" {
+		t.Errorf("roles = %v, want [developer]", got)
+	}
+
+	// the second login finds the linked identity instead of provisioning again
+	if _, err := f.login(t, f.identityClaims("upstream-1", "ada@example.com", "engineering")); err != nil {
+		t.Fatalf("second CompleteLogin: %v", err)
+	}
+	if len(f.users.users) != 1 {
+		t.Errorf("%d users after the second login, want 1", len(f.users.users))
+	}
+}
+
+func TestCompleteLoginWithoutJITRefusesUnknownIdentity(t *testing.T) {
+	f := newFederationFixture(t)
+
+	_, err := f.login(t, f.identityClaims("upstream-1", "ada@example.com"))
+	if !errors.Is(err, ErrIdentityNotLinked) {
+		t.Fatalf("CompleteLogin error = %v, want ErrIdentityNotLinked", err)
+	}
+	if len(f.users.users) != 0 || len(f.mfa.loggedIn) != 0 {
+		t.Errorf("an unknown identity was provisioned or logged in")
+	}
+}
+
+func TestCompleteLoginRefusesUnverifiedEmail(t *testing.T) {
+	f := newFederationFixture(t)
+	f.provider.JIT = true
+	f.provider.LinkByEmail = true
+	f.users.Create("Ada", "ada@example.com", "hash")
+
+	unverified := func(nonce string) jwt.MapClaims {
+		claims := f.identityClaims("upstream-1", "ada@example.com")(nonce)
+		claims["email_verified"] = false
+		return claims
+	}
+	_, err := f.login(t, unverified)
+	if !errors.Is(err, ErrIdentityNotLinked) {
+		t.Fatalf("CompleteLogin error = %v, want ErrIdentityNotLinked", err)
+	}
+	if len(f.federations.identities) != 0 || len(f.mfa.loggedIn) != 0 {
+		t.Errorf("an unverified email was linked to the existing account")
+	}
+}
+
+func TestCompleteLoginLinksByVerifiedEmail(t *testing.T) {
+	f := newFederationFixture(t)
+	f.users.Create("Ada", "ada@example.com", "hash")
+
+	// without LINK_BY_EMAIL the existing account is not taken over
+	if _, err := f.login(t, f.identityClaims("upstream-1", "ada@example.com")); !errors.Is(err, ErrIdentityNotLinked) {
+		t.Fatalf("CompleteLogin error = %v, want ErrIdentityNotLinked", err)
+	}
+
+	f.provider.LinkByEmail = true
+	if _, err := f.login(t, f.identityClaims("upstream-1", "ada@example.com")); err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	identity, err := f.federations.GetIdentity("stub", "upstream-1")
+	if err != nil || identity.UserID != f.users.users[0].ID {
+		t.Fatalf("identity = %+v, %v, want it linked to the existing user", identity, err)
+	}
+}
+
+func TestCompleteLoginRefusesWrongNonce(t *testing.T) {
+	f := newFederationFixture(t)
+	f.provider.JIT = true
+
+	replayed := func(nonce string) jwt.MapClaims {
+		return f.identityClaims("upstream-1", "ada@example.com")("nonce-of-another-login")
+	}
+	if _, err := f.login(t, replayed); err == nil {
+		t.Fatal("CompleteLogin accepted an ID token with the nonce of another login")
+	}
+	if len(f.users.users) != 0 || len(f.mfa.loggedIn) != 0 {
+		t.Errorf("a user was provisioned or logged in from a replayed ID token")
+	}
+}
+
+func TestCompleteLoginRefusesTokenForAnotherClient(t *testing.T) {
+	f := newFederationFixture(t)
+	f.provider.JIT = true
+
+	tests := map[string]func(claims jwt.MapClaims){
+		"aud": func(claims jwt.MapClaims) { claims["aud"] = "other-client" },
+		"azp": func(claims jwt.MapClaims) {
+			claims["aud"] = []string{stubClientID, "other-client"}
+			claims["azp"] = "other-client"
+		},
+	}
+	for name, modify := range tests {
+		foreign := func(nonce string) jwt.MapClaims {
+			claims := f.identityClaims("upstream-1", "ada@example.com")(nonce)
+			modify(claims)
+			return claims
+		}
+		if _, err := f.login(t, foreign); err == nil {
+			t.Errorf("%s: CompleteLogin accepted an ID token issued to another client", name)
+		}
+	}
+	if len(f.users.users) != 0 {
+		t.Errorf("a user was provisioned from an ID token issued to another client")
+	}
+}
+
+func TestCompleteLoginRefusesUnknownState(t *testing.T) {
+	f := newFederationFixture(t)
+
+	if _, err := f.service.CompleteLogin(f.provider.Name, "unknown-state", "code"); !errors.Is(err, ErrLoginStateInvalid) {
+		t.Fatalf("CompleteLogin error = %v, want ErrLoginStateInvalid", err)
+	}
+	if _, err := f.service.CompleteLogin("unknown-provider", "state", "code"); !errors.Is(err, ErrUnknownProvider) {
+		t.Fatalf("CompleteLogin error = %v, want ErrUnknownProvider", err)
+	}
+}
+
+func TestCompleteLoginSyncsMappedRoles(t *testing.T) {
+	f := newFederationFixture(t)
+	f.users.Create("Ada", "ada@example.com", "hash")
+	ada := f.users.users[0]
+	f.federations.LinkIdentity(int64(ada.ID), "stub", "upstream-1", "ada@example.com")
+	// admin is managed by the role map, auditor is not and stays whatever the groups
+	f.userRoles.AssignRoleToUser(int64(ada.ID), 2, 0, nil, nil)
+	f.userRoles.AssignRoleToUser(int64(ada.ID), 3, 0, nil, nil)
+
+	steps := []struct {
+		groups []string
+		want   []string
+	}{
+		{[]string{"engineering"}, []string{"auditor", "developer"}},
+		{[]string{"engineering", "ops", "unmapped"}, []string{"admin", "auditor", "developer"}},
+		{[]string{}, []string{"auditor"}},
+	}
+	for _, step := range steps {
+		if _, err := f.login(t, f.identityClaims("upstream-1", "ada@example.com", step.groups...)); err != nil {
+			t.Fatalf("CompleteLogin with groups %v: %v", step.groups, err)
+		}
+		got := f.roleNamesOf(ada.ID)
+		if len(got) != len(step.want) {
+			t.Fatalf("groups %v: roles = %v, want %v", step.groups, got, step.want)
+		}
+		for i := range got {
+			if got[i] != step.want[i] {
+				t.Fatalf("groups %v: roles = %v, want %v", step.groups, got, step.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"go_project_structure/internal/authz"
+	"go_project_structure/internal/authz/authzpb"
+	"go_project_structure/internal/permission"
+	"go_project_structure/internal/token"
+	"go_project_structure/internal/user"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodPermissions is the permission each gRPC method requires of its caller, the
+// same one its HTTP counterpart requires. Methods missing here are refused.
+var methodPermissions = map[string]string{
+	authzpb.AuthzService_Check_FullMethodName:               "authz:check",
+	authzpb.AuthzService_BatchCheck_FullMethodName:          "authz:check",
+	authzpb.AuthzService_ListUserPermissions_FullMethodName: "permission:read",
+	authzpb.AuthzService_ListUserRoles_FullMethodName:       "role:read",
+}
+
+// authInterceptor authenticates the caller from the bearer access token or API key in
+// the authorization metadata, exactly like JwtAuthMiddleware, and checks the permission of the method
+// in the organization of the token, which the caller has to be allowed to act in as
+// with ResolveTenant. The organization is passed on for permission.TenantFromContext.
+func authInterceptor(userRepository user.UserRepository, authorizer permission.Authorizer, tenants *permission.PermissionMiddleware) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		permissionName, ok := methodPermissions[info.FullMethod]
+		if !ok {
+			return nil, status.Errorf(codes.PermissionDenied, "method %s is not available", info.FullMethod)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		authorization := md.Get("authorization")
+		if len(authorization) == 0 || !strings.HasPrefix(authorization[0], "Bearer ") {
+			return nil, status.Error(codes.Unauthenticated, "bearer token missing")
+		}
+		principal, err := token.Authenticate(strings.TrimPrefix(authorization[0], "Bearer "))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token: "+err.Error())
+		}
+		if _, err := userRepository.GetByID(strconv.FormatInt(principal.UserID, 10)); err != nil {
+			return nil, status.Error(codes.Unauthenticated, "unknown user")
+		}
+
+		callerId := principal.UserID
+		organizationId := principal.OrganizationID
+		if organizationId != 0 {
+			allowed, err := tenants.MayActIn(principal, callerId, organizationId, authz.CallAttributes(ctx, callerId))
+			if err != nil {
+				return nil, status.Error(codes.Internal, "organization check failed")
+			}
+			if !allowed {
+				fmt.Printf("User %d may not act in organization %d\n", callerId, organizationId)
+				return nil, status.Errorf(codes.PermissionDenied, "not a member of organization %d", organizationId)
+			}
+		}
+
+		allowed := false
+		if permission.PrincipalAllows(principal, permissionName) {
+			allowed, err = authorizer.HasPermission(callerId, organizationId, permissionName, authz.CallAttributes(ctx, callerId))
+		}
+		if err != nil {
+			return nil, status.Error(codes.Internal, "permission check failed")
+		}
+		if !allowed {
+			fmt.Printf("User %d is missing permission %s for %s\n", callerId, permissionName, info.FullMethod)
+			return nil, status.Errorf(codes.PermissionDenied, "missing permission: %s", permissionName)
+		}
+
+		ctx = context.WithValue(token.WithPrincipal(ctx, principal), "organization_id", organizationId)
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,38 @@
+package grpcserver
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go_project_structure/internal/authz"
+	"go_project_structure/internal/authz/authzpb"
+	"go_project_structure/internal/organization"
+	"go_project_structure/internal/permission"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
+
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+// NewServer builds the gRPC server with every gRPC service registered, wired to the
+// same services and repositories as the HTTP routers.
+func NewServer(db *gorm.DB) *grpc.Server {
+	ur := userrole.NewUserRoleRepository(db)
+	us := userrole.NewUserRoleService(ur)
+	as := authz.NewAuthzService(us, user.NewUserRepository(db))
+
+	organizationRepository := organization.NewOrganizationRepository(db)
+	// only MayActIn is used: the interceptor resolves the caller itself
+	tenants := permission.NewPermissionMiddleware(us, nil, func(organizationId int64) (bool, error) {
+		_, err := organizationRepository.GetByID(fmt.Sprint(organizationId))
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+
+	server := grpc.NewServer(grpc.UnaryInterceptor(authInterceptor(user.NewUserRepository(db), us, tenants)))
+	authzpb.RegisterAuthzServiceServer(server, authz.NewAuthzGrpcServer(as, us))
+	return server
+}
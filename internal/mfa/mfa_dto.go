@@ -0,0 +1,68 @@
+package mfa
+
+import "go_project_structure/internal/passkey"
+
+// LoginResponse answers a login. Either the session is started and Token,
+// RefreshToken and ExpiresIn are set, or MfaRequired is set and MfaToken must be
+// completed at /login/mfa within MfaExpiresIn seconds with one of MfaMethods, "totp"
+// or "passkey". MfaEnrollmentRequired means the user has to enroll an authenticator
+// app at /login/mfa/enroll first.
+// RecoveryCodes are set when the login confirmed such an enrollment.
+type LoginResponse struct {
+	Token                 string   `json:"token,omitempty"`
+	RefreshToken          string   `json:"refresh_token,omitempty"`
+	ExpiresIn             int64    `json:"expires_in,omitempty"`
+	MfaRequired           bool     `json:"mfa_required,omitempty"`
+	MfaToken              string   `json:"mfa_token,omitempty"`
+	MfaExpiresIn          int64    `json:"mfa_expires_in,omitempty"`
+	MfaMethods            []string `json:"mfa_methods,omitempty"`
+	MfaEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"`
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"`
+}
+
+// CompleteLoginRequest completes a challenge with a code from the authenticator app
+// or a recovery code, or with the response of a passkey to the options of
+// /login/mfa/passkey.
+type CompleteLoginRequest struct {
+	MfaToken   string                       `json:"mfa_token" validate:"required"`
+	Code       string                       `json:"code"`
+	Credential *passkey.AssertionCredential `json:"credential"`
+}
+
+// PasskeyLoginRequest logs in with the response of a passkey to the options of
+// /login/passkey/options.
+type PasskeyLoginRequest struct {
+	Credential passkey.AssertionCredential `json:"credential" validate:"required"`
+	// OrganizationID pins the issued token to an organization
+	OrganizationID int64 `json:"organization_id,omitempty"`
+}
+
+// MfaTokenRequest carries the challenge of a login in progress.
+type MfaTokenRequest struct {
+	MfaToken string `json:"mfa_token" validate:"required"`
+}
+
+// CodeRequest carries a code from the authenticator app, or a recovery code where
+// one is accepted.
+type CodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// EnrollmentResponse carries the new secret, and the otpauth URI to show as a QR code.
+type EnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResponse carries recovery codes, which are shown only once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type StatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	EnrollmentPending      bool  `json:"enrollment_pending"`
+	RequiredByRole         bool  `json:"required_by_role"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+	Passkeys               int   `json:"passkeys"`
+}
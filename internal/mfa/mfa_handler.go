@@ -0,0 +1,197 @@
+package mfa
+
+import (
+	"errors"
+	"go_project_structure/internal/passkey"
+	"go_project_structure/internal/token"
+	"go_project_structure/internal/user"
+	utils "go_project_structure/utils"
+	"net/http"
+)
+
+type MfaController struct {
+	MfaService MfaService
+}
+
+func NewMfaController(_mfaService MfaService) *MfaController {
+	return &MfaController{
+		MfaService: _mfaService,
+	}
+}
+
+func (mc *MfaController) Login(w http.ResponseWriter, r *http.Request) {
+	var requestPayload = user.LoginUserRequest{}
+	err := utils.ReadJsonBody(r, &requestPayload)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	responsePayload, err := mc.MfaService.Login(requestPayload.Email, requestPayload.Password, requestPayload.OrganizationID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Login failed", err)
+		return
+	}
+	if responsePayload.MfaRequired {
+		utils.WriteJsonSuccessResponse(w, http.StatusOK, "MFA required; complete the login at /login/mfa", responsePayload)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Login successful", responsePayload)
+}
+
+func (mc *MfaController) CompleteLogin(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("complete_login_payload").(CompleteLoginRequest)
+
+	responsePayload, err := mc.MfaService.CompleteLogin(requestPayload.MfaToken, requestPayload.Code, requestPayload.Credential)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, statusFor(err), "Login failed", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Login successful", responsePayload)
+}
+
+func (mc *MfaController) EnrollForLogin(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("mfa_token_payload").(MfaTokenRequest)
+
+	enrollment, err := mc.MfaService.EnrollForLogin(requestPayload.MfaToken)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, statusFor(err), "MFA enrollment failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Add the secret to your authenticator app and complete the login with its code", enrollment)
+}
+
+func (mc *MfaController) BeginPasskeyChallenge(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("mfa_token_payload").(MfaTokenRequest)
+
+	options, err := mc.MfaService.BeginPasskeyChallenge(requestPayload.MfaToken)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, statusFor(err), "Passkey login failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Pass the options to navigator.credentials.get and complete the login at /login/mfa", &passkey.RequestOptionsResponse{PublicKey: options})
+}
+
+func (mc *MfaController) BeginPasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	options, err := mc.MfaService.BeginPasskeyLogin()
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Passkey login failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Pass the options to navigator.credentials.get and complete the login at /login/passkey", &passkey.RequestOptionsResponse{PublicKey: options})
+}
+
+func (mc *MfaController) LoginWithPasskey(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("passkey_login_payload").(PasskeyLoginRequest)
+
+	responsePayload, err := mc.MfaService.LoginWithPasskey(requestPayload.Credential, requestPayload.OrganizationID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, statusFor(err), "Login failed", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Login successful", responsePayload)
+}
+
+func (mc *MfaController) GetStatus(w http.ResponseWriter, r *http.Request) {
+	principal, ok := mfaPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := mc.MfaService.Status(principal.UserID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "MFA status fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get mfa status end point", status)
+}
+
+func (mc *MfaController) StartEnrollment(w http.ResponseWriter, r *http.Request) {
+	principal, ok := mfaPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := mc.MfaService.StartEnrollment(principal.UserID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, statusFor(err), "MFA enrollment failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Add the secret to your authenticator app and confirm it with its code", enrollment)
+}
+
+func (mc *MfaController) ConfirmEnrollment(w http.ResponseWriter, r *http.Request) {
+	principal, ok := mfaPrincipal(w, r)
+	if !ok {
+		return
+	}
+	requestPayload := r.Context().Value("mfa_code_payload").(CodeRequest)
+
+	recoveryCodes, err := mc.MfaService.ConfirmEnrollment(principal.UserID, requestPayload.Code)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, statusFor(err), "MFA enrollment failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "MFA enabled; store the recovery codes now, they are not shown again", &RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+func (mc *MfaController) Disable(w http.ResponseWriter, r *http.Request) {
+	principal, ok := mfaPrincipal(w, r)
+	if !ok {
+		return
+	}
+	requestPayload := r.Context().Value("mfa_code_payload").(CodeRequest)
+
+	err := mc.MfaService.Disable(principal.UserID, requestPayload.Code)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, statusFor(err), "MFA disable failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "MFA disabled successfully", nil)
+}
+
+func (mc *MfaController) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	principal, ok := mfaPrincipal(w, r)
+	if !ok {
+		return
+	}
+	requestPayload := r.Context().Value("mfa_code_payload").(CodeRequest)
+
+	recoveryCodes, err := mc.MfaService.RegenerateRecoveryCodes(principal.UserID, requestPayload.Code)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, statusFor(err), "Recovery codes regeneration failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Recovery codes replaced; store them now, they are not shown again", &RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// mfaPrincipal returns the principal managing their own second factor. Neither API
+// keys nor service accounts have one, so they are turned away.
+func mfaPrincipal(w http.ResponseWriter, r *http.Request) (*token.Principal, bool) {
+	principal, ok := token.PrincipalFromContext(r.Context())
+	if !ok {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", errors.New("missing principal"))
+		return nil, false
+	}
+	if principal.ApiKeyID != 0 || principal.IsServiceAccount() {
+		utils.WriteJsonErrorResponse(w, http.StatusForbidden, "MFA is managed by users on their own session", errors.New("API keys and service accounts have no second factor"))
+		return nil, false
+	}
+	return principal, true
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrChallengeInvalid), errors.Is(err, ErrCodeInvalid):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrEnrollmentRequired), errors.Is(err, ErrRequiredByRole):
+		return http.StatusForbidden
+	case errors.Is(err, ErrNotEnabled), errors.Is(err, ErrAlreadyEnabled):
+		return http.StatusConflict
+	}
+	return passkey.StatusFor(err)
+}
@@ -0,0 +1,114 @@
+package mfa
+
+import (
+	"context"
+	"fmt"
+	"go_project_structure/internal/passkey"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strings"
+)
+
+func CompleteLoginRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = CompleteLoginRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("complete login payload received.")
+
+		RequestPayload.MfaToken = strings.TrimSpace(RequestPayload.MfaToken)
+		RequestPayload.Code = strings.TrimSpace(RequestPayload.Code)
+		if RequestPayload.MfaToken == "" {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("mfa_token is required"))
+			return
+		}
+		if (RequestPayload.Code == "") == (RequestPayload.Credential == nil) {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("exactly one of code or credential is required"))
+			return
+		}
+		if RequestPayload.Credential != nil && !passkey.ValidAssertion(RequestPayload.Credential) {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("credential must be the login response of a passkey"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "complete_login_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func MfaTokenRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = MfaTokenRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("mfa token payload received.")
+
+		RequestPayload.MfaToken = strings.TrimSpace(RequestPayload.MfaToken)
+		if RequestPayload.MfaToken == "" {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("mfa_token is required"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "mfa_token_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func PasskeyLoginRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = PasskeyLoginRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("passkey login payload received.")
+
+		if !passkey.ValidAssertion(&RequestPayload.Credential) {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("credential must be the login response of a passkey"))
+			return
+		}
+		if RequestPayload.OrganizationID < 0 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("organization_id must be a positive integer"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "passkey_login_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func CodeRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = CodeRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("mfa code payload received.")
+
+		RequestPayload.Code = strings.TrimSpace(RequestPayload.Code)
+		if RequestPayload.Code == "" || len(RequestPayload.Code) > 32 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("code is required"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "mfa_code_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
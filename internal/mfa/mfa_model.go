@@ -0,0 +1,41 @@
+package mfa
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TotpFactor is the authenticator app of a user. SecretCiphertext is the TOTP secret
+// encrypted with MFA_ENCRYPTION_KEY. The factor protects logins only once a code has
+// confirmed it; LastUsedStep is the time step of the last accepted code, which cannot
+// be used again.
+type TotpFactor struct {
+	gorm.Model
+	UserID           uint   `gorm:"not null;uniqueIndex:idx_totp_factors_user_id_live,where:deleted_at IS NULL"`
+	SecretCiphertext string `gorm:"not null"`
+	ConfirmedAt      *time.Time
+	LastUsedStep     int64 `gorm:"not null;default:0"`
+}
+
+// MfaRecoveryCode is a one-time code that stands in for the authenticator app.
+// Only its SHA-256 hash is stored.
+type MfaRecoveryCode struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	CodeHash string `gorm:"size:64;not null"`
+	UsedAt   *time.Time
+}
+
+// MfaChallenge is a password login waiting for its second factor. The client holds
+// the challenge token, of which only the SHA-256 hash is stored, and completes the
+// login with it once, before it expires and within a few attempts.
+type MfaChallenge struct {
+	gorm.Model
+	TokenHash      string `gorm:"size:64;not null;uniqueIndex"`
+	UserID         uint   `gorm:"not null;index"`
+	OrganizationID *uint
+	Attempts       int       `gorm:"not null;default:0"`
+	ExpiresAt      time.Time `gorm:"not null"`
+	UsedAt         *time.Time
+}
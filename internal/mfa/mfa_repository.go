@@ -0,0 +1,268 @@
+package mfa
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MfaRepository interface {
+	GetFactor(userId int64) (*TotpFactor, error)
+	CreateFactor(userId int64, secretCiphertext string) (*TotpFactor, error)
+	ConfirmFactor(id uint) error
+	UseStep(id uint, step int64) (bool, error)
+	DeleteFactor(userId int64) error
+
+	ReplaceRecoveryCodes(userId int64, codeHashes []string) error
+	UseRecoveryCode(userId int64, codeHash string) (bool, error)
+	CountRecoveryCodes(userId int64) (int64, error)
+
+	CreateChallenge(userId int64, organizationId int64, tokenHash string, expiresAt time.Time) error
+	AttemptChallenge(tokenHash string, maxAttempts int) (*MfaChallenge, error)
+	CompleteChallenge(id uint) (bool, error)
+}
+
+type MfaRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewMfaRepository(_db *gorm.DB) MfaRepository {
+	return &MfaRepositoryImpl{
+		db: _db,
+	}
+}
+
+const totpFactorColumns = "id, user_id, secret_ciphertext, confirmed_at, last_used_step, created_at, updated_at"
+
+const mfaChallengeColumns = "id, token_hash, user_id, organization_id, attempts, expires_at, used_at, created_at, updated_at"
+
+// GetFactor returns the live factor of the user, confirmed or still being enrolled.
+func (u *MfaRepositoryImpl) GetFactor(userId int64) (*TotpFactor, error) {
+	// step 1: prepare the query
+	query := "SELECT " + totpFactorColumns + " FROM totp_factors WHERE deleted_at IS NULL AND user_id = ?"
+
+	// step 2: execute the query
+	row := u.db.Raw(query, userId).Row()
+
+	// step 3: process the result
+	return scanTotpFactor(row)
+}
+
+// CreateFactor starts an enrollment, replacing an enrollment of the user that was
+// never confirmed.
+func (u *MfaRepositoryImpl) CreateFactor(userId int64, secretCiphertext string) (*TotpFactor, error) {
+	fmt.Println("Creating totp factor in mfa repository.")
+
+	var factor *TotpFactor
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		// step 1: drop the pending enrollment
+		if err := tx.Exec("UPDATE totp_factors SET deleted_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND user_id = ? AND confirmed_at IS NULL", userId).Error; err != nil {
+			return err
+		}
+
+		// step 2: start the new one
+		row := tx.Raw("INSERT INTO totp_factors (user_id, secret_ciphertext) VALUES (?, ?) RETURNING "+totpFactorColumns, userId, secretCiphertext).Row()
+		var err error
+		factor, err = scanTotpFactor(row)
+		return err
+	})
+	if err != nil {
+		fmt.Printf("Error creating totp factor: %v\n", err)
+		return nil, err
+	}
+
+	// step 3: return the result
+	return factor, nil
+}
+
+func (u *MfaRepositoryImpl) ConfirmFactor(id uint) error {
+	fmt.Println("Confirming totp factor in mfa repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE totp_factors SET confirmed_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND id = ? AND confirmed_at IS NULL"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, id)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error confirming totp factor: %v\n", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UseStep records the time step of an accepted code. It reports false when that step
+// or a later one was already used, i.e. when the code is being replayed.
+func (u *MfaRepositoryImpl) UseStep(id uint, step int64) (bool, error) {
+	// step 1: prepare the query
+	query := "UPDATE totp_factors SET last_used_step = ?, updated_at = NOW() WHERE deleted_at IS NULL AND id = ? AND last_used_step < ?"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, step, id, step)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error recording totp step: %v\n", result.Error)
+		return false, result.Error
+	}
+
+	// step 4: return the result
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteFactor removes the factor of the user along with their recovery codes.
+func (u *MfaRepositoryImpl) DeleteFactor(userId int64) error {
+	fmt.Println("Deleting totp factor in mfa repository.")
+
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("UPDATE totp_factors SET deleted_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND user_id = ?", userId).Error; err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE mfa_recovery_codes SET deleted_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND user_id = ?", userId).Error
+	})
+	if err != nil {
+		fmt.Printf("Error deleting totp factor: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes invalidates the recovery codes of the user and stores new ones.
+func (u *MfaRepositoryImpl) ReplaceRecoveryCodes(userId int64, codeHashes []string) error {
+	fmt.Println("Replacing recovery codes in mfa repository.")
+
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		// step 1: invalidate the old codes
+		if err := tx.Exec("UPDATE mfa_recovery_codes SET deleted_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND user_id = ?", userId).Error; err != nil {
+			return err
+		}
+
+		// step 2: store the new ones
+		for _, codeHash := range codeHashes {
+			if err := tx.Exec("INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES (?, ?)", userId, codeHash).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error replacing recovery codes: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// UseRecoveryCode uses up an unused recovery code of the user. It reports false when
+// the user has no such code.
+func (u *MfaRepositoryImpl) UseRecoveryCode(userId int64, codeHash string) (bool, error) {
+	// step 1: prepare the query
+	query := "UPDATE mfa_recovery_codes SET used_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND used_at IS NULL AND user_id = ? AND code_hash = ?"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, userId, codeHash)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error using recovery code: %v\n", result.Error)
+		return false, result.Error
+	}
+
+	// step 4: return the result
+	return result.RowsAffected > 0, nil
+}
+
+func (u *MfaRepositoryImpl) CountRecoveryCodes(userId int64) (int64, error) {
+	// step 1: prepare the query
+	query := "SELECT COUNT(*) FROM mfa_recovery_codes WHERE deleted_at IS NULL AND used_at IS NULL AND user_id = ?"
+
+	// step 2: execute the query
+	row := u.db.Raw(query, userId).Row()
+
+	// step 3: process the result
+	var count int64
+	if err := row.Scan(&count); err != nil {
+		fmt.Printf("Error counting recovery codes: %v\n", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (u *MfaRepositoryImpl) CreateChallenge(userId int64, organizationId int64, tokenHash string, expiresAt time.Time) error {
+	fmt.Println("Creating mfa challenge in mfa repository.")
+
+	// step 1: prepare the query
+	query := "INSERT INTO mfa_challenges (token_hash, user_id, organization_id, expires_at) VALUES (?, ?, ?, ?)"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, tokenHash, userId, organizationScope(organizationId), expiresAt)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error creating mfa challenge: %v\n", result.Error)
+		return result.Error
+	}
+	return nil
+}
+
+// AttemptChallenge counts an attempt to complete the challenge and returns it while
+// it is open: not completed, not expired and not out of attempts.
+func (u *MfaRepositoryImpl) AttemptChallenge(tokenHash string, maxAttempts int) (*MfaChallenge, error) {
+	// step 1: prepare the query
+	query := `UPDATE mfa_challenges SET attempts = attempts + 1, updated_at = NOW()
+		WHERE deleted_at IS NULL AND token_hash = ? AND used_at IS NULL AND expires_at > NOW() AND attempts < ?
+		RETURNING ` + mfaChallengeColumns
+
+	// step 2: execute the query
+	row := u.db.Raw(query, tokenHash, maxAttempts).Row()
+
+	// step 3: process the result
+	challenge := &MfaChallenge{}
+	err := row.Scan(&challenge.ID, &challenge.TokenHash, &challenge.UserID, &challenge.OrganizationID, &challenge.Attempts,
+		&challenge.ExpiresAt, &challenge.UsedAt, &challenge.CreatedAt, &challenge.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// CompleteChallenge closes the challenge. It reports false when a concurrent request
+// completed it first.
+func (u *MfaRepositoryImpl) CompleteChallenge(id uint) (bool, error) {
+	// step 1: prepare the query
+	query := "UPDATE mfa_challenges SET used_at = NOW(), updated_at = NOW() WHERE id = ? AND used_at IS NULL"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, id)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error completing mfa challenge: %v\n", result.Error)
+		return false, result.Error
+	}
+
+	// step 4: return the result
+	return result.RowsAffected == 1, nil
+}
+
+// organizationScope turns the "no organization" zero value into NULL.
+func organizationScope(organizationId int64) interface{} {
+	if organizationId == 0 {
+		return nil
+	}
+	return organizationId
+}
+
+func scanTotpFactor(row *sql.Row) (*TotpFactor, error) {
+	factor := &TotpFactor{}
+	err := row.Scan(&factor.ID, &factor.UserID, &factor.SecretCiphertext, &factor.ConfirmedAt, &factor.LastUsedStep, &factor.CreatedAt, &factor.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return factor, nil
+}
@@ -0,0 +1,564 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	env "go_project_structure/config/env"
+	"go_project_structure/internal/events"
+	"go_project_structure/internal/passkey"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrChallengeInvalid   = errors.New("MFA challenge is invalid, expired or out of attempts")
+	ErrCodeInvalid        = errors.New("invalid authentication code")
+	ErrNotEnabled         = errors.New("MFA is not enabled")
+	ErrAlreadyEnabled     = errors.New("MFA is already enabled; disable it before enrolling again")
+	ErrEnrollmentRequired = errors.New("a role you hold requires MFA; enroll an authenticator app to log in")
+	ErrRequiredByRole     = errors.New("a role you hold requires MFA, so it cannot be disabled")
+)
+
+const (
+	// maxChallengeAttempts bounds the codes tried against one challenge
+	maxChallengeAttempts = 5
+	recoveryCodeCount    = 10
+)
+
+// Events published about second factors.
+const (
+	EnabledEvent          = "mfa.enabled"
+	DisabledEvent         = "mfa.disabled"
+	RecoveryCodeUsedEvent = "mfa.recovery_code_used"
+)
+
+type MfaService interface {
+	Login(email string, password string, organizationId int64) (*LoginResponse, error)
+	BeginLogin(loggedIn *user.User, organizationId int64) (*LoginResponse, error)
+	CompleteLogin(mfaToken string, code string, credential *passkey.AssertionCredential) (*LoginResponse, error)
+	EnrollForLogin(mfaToken string) (*EnrollmentResponse, error)
+	BeginPasskeyChallenge(mfaToken string) (*passkey.RequestOptions, error)
+	BeginPasskeyLogin() (*passkey.RequestOptions, error)
+	LoginWithPasskey(credential passkey.AssertionCredential, organizationId int64) (*LoginResponse, error)
+	Required(userId int64) (bool, error)
+	VerifyCode(userId int64, code string) error
+
+	StartEnrollment(userId int64) (*EnrollmentResponse, error)
+	ConfirmEnrollment(userId int64, code string) ([]string, error)
+	Disable(userId int64, code string) error
+	RegenerateRecoveryCodes(userId int64, code string) ([]string, error)
+	Status(userId int64) (*StatusResponse, error)
+}
+
+type MfaServiceImpl struct {
+	mfaRepository      MfaRepository
+	userService        user.UserService
+	userRoleRepository userrole.UserRoleRepository
+	passkeyService     passkey.PasskeyService
+	bus                *events.Bus
+}
+
+func NewMfaService(_mfaRepository MfaRepository, _userService user.UserService, _userRoleRepository userrole.UserRoleRepository, _passkeyService passkey.PasskeyService, _bus *events.Bus) MfaService {
+	return &MfaServiceImpl{
+		mfaRepository:      _mfaRepository,
+		userService:        _userService,
+		userRoleRepository: _userRoleRepository,
+		passkeyService:     _passkeyService,
+		bus:                _bus,
+	}
+}
+
+// ChallengeTTL is how long a password login waits for its second factor.
+func ChallengeTTL() time.Duration {
+	return time.Duration(env.GetInt("MFA_CHALLENGE_TTL_SECONDS", 300)) * time.Second
+}
+
+// Login checks the password and hands over to BeginLogin.
+func (ms *MfaServiceImpl) Login(email string, password string, organizationId int64) (*LoginResponse, error) {
+	fmt.Println("Logging in user in mfa service.")
+	loggedIn, err := ms.userService.Authenticate(email, password)
+	if err != nil {
+		return nil, err
+	}
+	return ms.BeginLogin(loggedIn, organizationId)
+}
+
+// BeginLogin finishes the first step of a login: users with a confirmed
+// authenticator app or a passkey, or holding a role that requires a second factor,
+// get a challenge to complete at /login/mfa; everybody else gets their session
+// right away.
+func (ms *MfaServiceImpl) BeginLogin(loggedIn *user.User, organizationId int64) (*LoginResponse, error) {
+	userId := int64(loggedIn.ID)
+	methods, err := ms.methods(userId)
+	if err != nil {
+		return nil, err
+	}
+	enabled := len(methods) > 0
+
+	required := enabled
+	if !enabled {
+		required, err = ms.userRoleRepository.RequiresMfa(userId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !required {
+		tokens, err := ms.userService.StartSession(loggedIn, organizationId)
+		if err != nil {
+			return nil, err
+		}
+		return sessionResponse(tokens), nil
+	}
+
+	mfaToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	if err := ms.mfaRepository.CreateChallenge(userId, organizationId, hashValue(mfaToken), time.Now().Add(ChallengeTTL())); err != nil {
+		return nil, err
+	}
+	fmt.Printf("User %d must complete an mfa challenge\n", userId)
+	return &LoginResponse{
+		MfaRequired:           true,
+		MfaToken:              mfaToken,
+		MfaExpiresIn:          int64(ChallengeTTL().Seconds()),
+		MfaMethods:            methods,
+		MfaEnrollmentRequired: !enabled,
+	}, nil
+}
+
+// CompleteLogin completes a challenge with a code from the authenticator app, a
+// recovery code or a passkey, and starts the session. A user enrolling during the
+// login confirms the new app with its first code and receives their recovery codes
+// here.
+func (ms *MfaServiceImpl) CompleteLogin(mfaToken string, code string, credential *passkey.AssertionCredential) (*LoginResponse, error) {
+	fmt.Println("Completing mfa challenge in mfa service.")
+	challenge, err := ms.attemptChallenge(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	userId := int64(challenge.UserID)
+
+	var recoveryCodes []string
+	if credential != nil {
+		_, err = ms.passkeyService.FinishAssertion(*credential, userId)
+	} else {
+		recoveryCodes, err = ms.verifyLoginCode(userId, code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	completed, err := ms.mfaRepository.CompleteChallenge(challenge.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !completed {
+		return nil, ErrChallengeInvalid
+	}
+
+	var organizationId int64
+	if challenge.OrganizationID != nil {
+		organizationId = int64(*challenge.OrganizationID)
+	}
+	response, err := ms.startSession(userId, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
+
+// BeginPasskeyChallenge starts completing a challenge with one of the passkeys of
+// the user.
+func (ms *MfaServiceImpl) BeginPasskeyChallenge(mfaToken string) (*passkey.RequestOptions, error) {
+	fmt.Println("Beginning passkey challenge in mfa service.")
+	challenge, err := ms.attemptChallenge(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	return ms.passkeyService.BeginAssertion(int64(challenge.UserID))
+}
+
+// BeginPasskeyLogin starts a passwordless login with a passkey.
+func (ms *MfaServiceImpl) BeginPasskeyLogin() (*passkey.RequestOptions, error) {
+	fmt.Println("Beginning passkey login in mfa service.")
+	return ms.passkeyService.BeginAssertion(0)
+}
+
+// LoginWithPasskey logs in the user a passkey belongs to. The passkey verified the
+// user, so it is two factors on its own and the login takes no challenge.
+func (ms *MfaServiceImpl) LoginWithPasskey(credential passkey.AssertionCredential, organizationId int64) (*LoginResponse, error) {
+	fmt.Println("Logging in with passkey in mfa service.")
+	userId, err := ms.passkeyService.FinishAssertion(credential, 0)
+	if err != nil {
+		return nil, err
+	}
+	return ms.startSession(userId, organizationId)
+}
+
+// EnrollForLogin starts the enrollment of a user whose role requires MFA before they
+// ever logged in with it; the challenge stands in for the session they do not have.
+// Users with any second factor complete the challenge with it instead: otherwise the
+// password alone could enroll a new one in its place.
+func (ms *MfaServiceImpl) EnrollForLogin(mfaToken string) (*EnrollmentResponse, error) {
+	fmt.Println("Enrolling totp during login in mfa service.")
+	challenge, err := ms.attemptChallenge(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	userId := int64(challenge.UserID)
+	methods, err := ms.methods(userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(methods) > 0 {
+		return nil, ErrAlreadyEnabled
+	}
+	return ms.StartEnrollment(userId)
+}
+
+// Required reports whether logging the user in takes a second factor: they have
+// one, or a role they hold requires it.
+func (ms *MfaServiceImpl) Required(userId int64) (bool, error) {
+	methods, err := ms.methods(userId)
+	if err != nil {
+		return false, err
+	}
+	if len(methods) > 0 {
+		return true, nil
+	}
+	return ms.userRoleRepository.RequiresMfa(userId)
+}
+
+// VerifyCode accepts a code of the confirmed authenticator app of the user, each at
+// most once, or one of their unused recovery codes.
+func (ms *MfaServiceImpl) VerifyCode(userId int64, code string) error {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	factor, err := ms.getFactor(userId)
+	if err != nil {
+		return err
+	}
+	if factor == nil || factor.ConfirmedAt == nil {
+		return ErrNotEnabled
+	}
+
+	if len(code) == totpDigits {
+		secret, err := decryptSecret(factor.SecretCiphertext)
+		if err != nil {
+			return err
+		}
+		step, ok := verifyTotp(secret, code, factor.LastUsedStep, time.Now())
+		if !ok {
+			return ErrCodeInvalid
+		}
+		fresh, err := ms.mfaRepository.UseStep(factor.ID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return ErrCodeInvalid
+		}
+		return nil
+	}
+
+	used, err := ms.mfaRepository.UseRecoveryCode(userId, hashValue(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrCodeInvalid
+	}
+	remaining, _ := ms.mfaRepository.CountRecoveryCodes(userId)
+	ms.publish(RecoveryCodeUsedEvent, userId, map[string]interface{}{"remaining": remaining})
+	return nil
+}
+
+// StartEnrollment creates a new secret for the user's authenticator app. It protects
+// nothing until ConfirmEnrollment accepts a code generated from it.
+func (ms *MfaServiceImpl) StartEnrollment(userId int64) (*EnrollmentResponse, error) {
+	fmt.Println("Starting totp enrollment in mfa service.")
+	factor, err := ms.getFactor(userId)
+	if err != nil {
+		return nil, err
+	}
+	if factor != nil && factor.ConfirmedAt != nil {
+		return nil, ErrAlreadyEnabled
+	}
+	enrolling, err := ms.userService.GetUserById(strconv.FormatInt(userId, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := newTotpSecret()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := encryptSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ms.mfaRepository.CreateFactor(userId, ciphertext); err != nil {
+		return nil, err
+	}
+	return &EnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: provisioningURI(enrolling.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment turns MFA on once a code from the new authenticator app checks
+// out, and returns the recovery codes, which are shown only here.
+func (ms *MfaServiceImpl) ConfirmEnrollment(userId int64, code string) ([]string, error) {
+	fmt.Println("Confirming totp enrollment in mfa service.")
+	factor, err := ms.getFactor(userId)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil {
+		return nil, ErrNotEnabled
+	}
+	if factor.ConfirmedAt != nil {
+		return nil, ErrAlreadyEnabled
+	}
+	return ms.confirm(userId, factor, code)
+}
+
+// Disable turns the authenticator app off, which takes a current code. Users
+// holding a role that requires MFA cannot turn it off unless a passkey remains.
+func (ms *MfaServiceImpl) Disable(userId int64, code string) error {
+	fmt.Println("Disabling mfa in mfa service.")
+	required, err := ms.userRoleRepository.RequiresMfa(userId)
+	if err != nil {
+		return err
+	}
+	hasPasskeys, err := ms.passkeyService.HasPasskeys(userId)
+	if err != nil {
+		return err
+	}
+	if required && !hasPasskeys {
+		return ErrRequiredByRole
+	}
+	if err := ms.VerifyCode(userId, code); err != nil {
+		return err
+	}
+	if err := ms.mfaRepository.DeleteFactor(userId); err != nil {
+		return err
+	}
+	ms.publish(DisabledEvent, userId, map[string]interface{}{})
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user, which takes a
+// current code.
+func (ms *MfaServiceImpl) RegenerateRecoveryCodes(userId int64, code string) ([]string, error) {
+	fmt.Println("Regenerating recovery codes in mfa service.")
+	if err := ms.VerifyCode(userId, code); err != nil {
+		return nil, err
+	}
+	return ms.newRecoveryCodes(userId)
+}
+
+func (ms *MfaServiceImpl) Status(userId int64) (*StatusResponse, error) {
+	fmt.Println("Fetching mfa status in mfa service.")
+	factor, err := ms.getFactor(userId)
+	if err != nil {
+		return nil, err
+	}
+	required, err := ms.userRoleRepository.RequiresMfa(userId)
+	if err != nil {
+		return nil, err
+	}
+	remaining, err := ms.mfaRepository.CountRecoveryCodes(userId)
+	if err != nil {
+		return nil, err
+	}
+	passkeys, err := ms.passkeyService.GetPasskeys(userId)
+	if err != nil {
+		return nil, err
+	}
+	return &StatusResponse{
+		Enabled:                factor != nil && factor.ConfirmedAt != nil,
+		Passkeys:               len(passkeys),
+		EnrollmentPending:      factor != nil && factor.ConfirmedAt == nil,
+		RequiredByRole:         required,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+// methods lists the second factors the user has: "totp" for a confirmed
+// authenticator app and "passkey" when they registered a passkey.
+func (ms *MfaServiceImpl) methods(userId int64) ([]string, error) {
+	methods := []string{}
+	factor, err := ms.getFactor(userId)
+	if err != nil {
+		return nil, err
+	}
+	if factor != nil && factor.ConfirmedAt != nil {
+		methods = append(methods, "totp")
+	}
+	hasPasskeys, err := ms.passkeyService.HasPasskeys(userId)
+	if err != nil {
+		return nil, err
+	}
+	if hasPasskeys {
+		methods = append(methods, "passkey")
+	}
+	return methods, nil
+}
+
+// verifyLoginCode checks the code completing a challenge. A pending authenticator
+// app is confirmed by it, which issues the first recovery codes, but only for a user
+// without a passkey: one who has a passkey must log in with it and confirm the app
+// from their session.
+func (ms *MfaServiceImpl) verifyLoginCode(userId int64, code string) ([]string, error) {
+	factor, err := ms.getFactor(userId)
+	if err != nil {
+		return nil, err
+	}
+	if factor != nil && factor.ConfirmedAt != nil {
+		return nil, ms.VerifyCode(userId, code)
+	}
+
+	hasPasskeys, err := ms.passkeyService.HasPasskeys(userId)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case hasPasskeys:
+		return nil, ErrNotEnabled
+	case factor == nil:
+		return nil, ErrEnrollmentRequired
+	}
+	return ms.confirm(userId, factor, code)
+}
+
+func (ms *MfaServiceImpl) startSession(userId int64, organizationId int64) (*LoginResponse, error) {
+	loggedIn, err := ms.userService.GetUserById(strconv.FormatInt(userId, 10))
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := ms.userService.StartSession(loggedIn, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	return sessionResponse(tokens), nil
+}
+
+// confirm checks a code from a pending enrollment, confirms it and issues the first
+// recovery codes.
+func (ms *MfaServiceImpl) confirm(userId int64, factor *TotpFactor, code string) ([]string, error) {
+	secret, err := decryptSecret(factor.SecretCiphertext)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := verifyTotp(secret, strings.TrimSpace(code), factor.LastUsedStep, time.Now())
+	if !ok {
+		return nil, ErrCodeInvalid
+	}
+	fresh, err := ms.mfaRepository.UseStep(factor.ID, step)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, ErrCodeInvalid
+	}
+	if err := ms.mfaRepository.ConfirmFactor(factor.ID); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := ms.newRecoveryCodes(userId)
+	if err != nil {
+		return nil, err
+	}
+	ms.publish(EnabledEvent, userId, map[string]interface{}{})
+	return recoveryCodes, nil
+}
+
+func (ms *MfaServiceImpl) newRecoveryCodes(userId int64) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashValue(normalizeRecoveryCode(code)))
+	}
+	if err := ms.mfaRepository.ReplaceRecoveryCodes(userId, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// getFactor returns the factor of the user, or nil when they have none.
+func (ms *MfaServiceImpl) getFactor(userId int64) (*TotpFactor, error) {
+	factor, err := ms.mfaRepository.GetFactor(userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		fmt.Printf("Error fetching totp factor: %v\n", err)
+		return nil, err
+	}
+	return factor, nil
+}
+
+func (ms *MfaServiceImpl) attemptChallenge(mfaToken string) (*MfaChallenge, error) {
+	challenge, err := ms.mfaRepository.AttemptChallenge(hashValue(mfaToken), maxChallengeAttempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrChallengeInvalid
+	}
+	return challenge, err
+}
+
+func (ms *MfaServiceImpl) publish(name string, userId int64, data map[string]interface{}) {
+	data["user_id"] = userId
+	ms.bus.Publish(events.Event{Name: name, Data: data})
+}
+
+func sessionResponse(tokens *user.TokenPair) *LoginResponse {
+	return &LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}
+}
+
+// newRecoveryCode returns ten random base32 characters as two groups of five.
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(buf))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode makes recovery codes match however they are typed.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
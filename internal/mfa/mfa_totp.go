@@ -0,0 +1,146 @@
+package mfa
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	env "go_project_structure/config/env"
+	"net/url"
+	"time"
+)
+
+// TOTP parameters (RFC 6238): SHA-1, six digits, 30 second steps. They are the
+// defaults of every authenticator app, which ignore anything else in practice.
+const (
+	totpDigits = 6
+	totpPeriod = 30
+	// totpSkew accepts codes one step early or late to allow for clock drift
+	totpSkew = 1
+)
+
+var errEncryptionKeyMissing = errors.New("MFA_ENCRYPTION_KEY is not set")
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// issuer names this service in authenticator apps.
+func issuer() string {
+	return env.GetString("MFA_ISSUER", "go_project_structure")
+}
+
+// newTotpSecret returns a random 160 bit secret, base32 encoded as authenticator
+// apps expect it.
+func newTotpSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return secretEncoding.EncodeToString(secret), nil
+}
+
+// provisioningURI is the otpauth URI authenticator apps enroll from, usually shown
+// as a QR code.
+func provisioningURI(accountName string, secret string) string {
+	label := url.PathEscape(issuer() + ":" + accountName)
+	params := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer()},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(totpDigits)},
+		"period":    {fmt.Sprint(totpPeriod)},
+	}
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// totpCode is the HOTP value (RFC 4226) of the secret for a time step.
+func totpCode(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// verifyTotp checks a code against the steps around now and returns the step it
+// matched. Steps up to lastUsedStep are refused so that a code works only once.
+func verifyTotp(secret string, code string, lastUsedStep int64, now time.Time) (int64, bool) {
+	key, err := secretEncoding.DecodeString(secret)
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastUsedStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// encryptionKey derives the AES-256 key TOTP secrets are stored with.
+func encryptionKey() ([]byte, error) {
+	key := env.GetString("MFA_ENCRYPTION_KEY", "")
+	if key == "" {
+		return nil, errEncryptionKeyMissing
+	}
+	sum := sha256.Sum256([]byte(key))
+	return sum[:], nil
+}
+
+// encryptSecret seals a TOTP secret with AES-GCM; the nonce is stored in front of
+// the ciphertext.
+func encryptSecret(secret string) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func decryptSecret(ciphertext string) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", errors.New("stored TOTP secret is malformed")
+	}
+	secret, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("stored TOTP secret cannot be decrypted: %w", err)
+	}
+	return string(secret), nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	key, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
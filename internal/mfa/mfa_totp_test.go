@@ -0,0 +1,178 @@
+package mfa
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the test vectors of RFC 4226 and RFC 6238.
+var rfcSecret = []byte("12345678901234567890")
+
+func TestTotpCodeRFC4226(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := totpCode(rfcSecret, int64(counter)); got != code {
+			t.Errorf("totpCode(counter %d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestTotpCodeRFC6238(t *testing.T) {
+	// the SHA-1 vectors of RFC 6238 appendix B, cut to the six digits used here
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	secret := secretEncoding.EncodeToString(rfcSecret)
+	for _, tt := range tests {
+		if got := totpCode(rfcSecret, tt.unix/totpPeriod); got != tt.code {
+			t.Errorf("totpCode at %d = %s, want %s", tt.unix, got, tt.code)
+		}
+		step, ok := verifyTotp(secret, tt.code, 0, time.Unix(tt.unix, 0))
+		if !ok || step != tt.unix/totpPeriod {
+			t.Errorf("verifyTotp at %d = %d, %v, want step %d", tt.unix, step, ok, tt.unix/totpPeriod)
+		}
+	}
+}
+
+func TestVerifyTotpSkew(t *testing.T) {
+	secret := secretEncoding.EncodeToString(rfcSecret)
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name   string
+		offset int64
+		want   bool
+	}{
+		{"current step", 0, true},
+		{"one step early", -1, true},
+		{"one step late", 1, true},
+		{"two steps early", -2, false},
+		{"two steps late", 2, false},
+	}
+	for _, tt := range tests {
+		step, ok := verifyTotp(secret, totpCode(rfcSecret, current+tt.offset), 0, now)
+		if ok != tt.want {
+			t.Errorf("%s: verifyTotp = %v, want %v", tt.name, ok, tt.want)
+		}
+		if ok && step != current+tt.offset {
+			t.Errorf("%s: matched step %d, want %d", tt.name, step, current+tt.offset)
+		}
+	}
+}
+
+func TestVerifyTotpRefusesUsedSteps(t *testing.T) {
+	secret := secretEncoding.EncodeToString(rfcSecret)
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / totpPeriod
+	code := totpCode(rfcSecret, current)
+
+	step, ok := verifyTotp(secret, code, 0, now)
+	if !ok {
+		t.Fatal("verifyTotp refused a fresh code")
+	}
+	// the step is recorded as used: the same code is refused within its window
+	if _, ok := verifyTotp(secret, code, step, now); ok {
+		t.Error("verifyTotp accepted a code twice")
+	}
+	if _, ok := verifyTotp(secret, code, step, now.Add(totpPeriod*time.Second)); ok {
+		t.Error("verifyTotp accepted a used code one step later")
+	}
+	// an earlier step is refused once a later one was used
+	if _, ok := verifyTotp(secret, totpCode(rfcSecret, current-1), current, now); ok {
+		t.Error("verifyTotp accepted a code older than the last used one")
+	}
+	// the next step still works
+	if _, ok := verifyTotp(secret, totpCode(rfcSecret, current+1), current, now); !ok {
+		t.Error("verifyTotp refused the code of the next step")
+	}
+}
+
+func TestVerifyTotpRejectsMalformedInput(t *testing.T) {
+	secret := secretEncoding.EncodeToString(rfcSecret)
+	now := time.Unix(1234567890, 0)
+	code := totpCode(rfcSecret, now.Unix()/totpPeriod)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+	}{
+		{"short code", secret, code[:5]},
+		{"long code", secret, code + "0"},
+		{"empty code", secret, ""},
+		{"wrong code", secret, "000000"},
+		{"secret that is not base32", "not base32!", code},
+	}
+	for _, tt := range tests {
+		if _, ok := verifyTotp(tt.secret, tt.code, 0, now); ok {
+			t.Errorf("%s: verifyTotp accepted %q", tt.name, tt.code)
+		}
+	}
+}
+
+func TestNewTotpSecret(t *testing.T) {
+	secret, err := newTotpSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := secretEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q decodes to %d bytes, %v; want 20", secret, len(key), err)
+	}
+	other, _ := newTotpSecret()
+	if other == secret {
+		t.Error("two secrets are the same")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	t.Setenv("MFA_ISSUER", "Example Co")
+	parsed, err := url.Parse(provisioningURI("user@example.com", "JBSWY3DPEHPK3PXP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" || parsed.Path != "/Example Co:user@example.com" {
+		t.Errorf("URI = %s", parsed)
+	}
+	query := parsed.Query()
+	if query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "Example Co" || query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("query = %v", query)
+	}
+}
+
+func TestSecretEncryption(t *testing.T) {
+	t.Setenv("MFA_ENCRYPTION_KEY", "")
+	if _, err := encryptSecret("JBSWY3DPEHPK3PXP"); err == nil {
+		t.Fatal("encryptSecret worked without MFA_ENCRYPTION_KEY")
+	}
+
+	t.Setenv("MFA_ENCRYPTION_KEY", "first key")
+	sealed, err := encryptSecret("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := encryptSecret("JBSWY3DPEHPK3PXP"); again == sealed {
+		t.Error("encrypting twice gave the same ciphertext")
+	}
+	if secret, err := decryptSecret(sealed); err != nil || secret != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("decryptSecret = %q, %v", secret, err)
+	}
+	if _, err := decryptSecret("AAAA"); err == nil {
+		t.Error("decryptSecret accepted a truncated ciphertext")
+	}
+
+	t.Setenv("MFA_ENCRYPTION_KEY", "second key")
+	if _, err := decryptSecret(sealed); err == nil {
+		t.Error("decryptSecret worked with another key")
+	}
+}
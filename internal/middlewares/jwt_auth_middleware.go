@@ -4,55 +4,61 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
-	env "go_project_structure/config/env"
-
-	"github.com/golang-jwt/jwt/v5"
+	"go_project_structure/internal/token"
 )
 
+// JwtAuthMiddleware authenticates the caller with the Bearer access token of the
+// Authorization header. An API key is accepted instead, as the Bearer credential or
+// in the X-API-Key header.
 func JwtAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
+		apiKey := r.Header.Get("X-API-Key")
+		if authHeader == "" && apiKey == "" {
 			http.Error(w, "Authorization header missing", http.StatusUnauthorized)
 			return
 		}
 
-		if !strings.HasPrefix(authHeader, "Bearer ") {
-			http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
-			return
-		}
-
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if token == "" {
-			http.Error(w, "Token missing in authorization header", http.StatusUnauthorized)
-			return
+		credential := apiKey
+		if authHeader != "" {
+			if !strings.HasPrefix(authHeader, "Bearer ") {
+				http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
+				return
+			}
+			credential = strings.TrimPrefix(authHeader, "Bearer ")
+			if credential == "" {
+				http.Error(w, "Token missing in authorization header", http.StatusUnauthorized)
+				return
+			}
 		}
 
-		fmt.Println("jwt token: ", token)
-
-		claims := jwt.MapClaims{}
-
-		_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(env.GetString("JWT_SECRET", "default_secret_key")), nil
-		})
-
+		principal, err := token.Authenticate(credential)
 		if err != nil {
 			http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
 			return
 		}
 
-		fmt.Println("claims: ", claims)
-
-		userEmail, okEmail := claims["email"].(string)
-		if !okEmail {
-			http.Error(w, "Invalid token claims: email not found", http.StatusUnauthorized)
-			return
+		subject := "user"
+		if principal.IsServiceAccount() {
+			subject = "service account"
+		}
+		if principal.ApiKeyID != 0 {
+			fmt.Printf("authenticated %s %d (api key %d)\n", subject, principal.UserID, principal.ApiKeyID)
+		} else {
+			fmt.Printf("authenticated %s %d (token %s)\n", subject, principal.UserID, principal.TokenID)
 		}
 
-		ctx := r.Context()
-		ctx = context.WithValue(ctx, "email", userEmail)
+		ctx := token.WithPrincipal(r.Context(), principal)
+		ctx = context.WithValue(ctx, "email", principal.Email)
+		// ProxyToService forwards the caller to upstream services as X-User-Id
+		ctx = context.WithValue(ctx, "userId", strconv.FormatInt(principal.UserID, 10))
+		// a token issued for an organization pins the tenant of every request it authenticates
+		if principal.OrganizationID > 0 {
+			ctx = context.WithValue(ctx, "token_organization_id", principal.OrganizationID)
+		}
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
@@ -0,0 +1,158 @@
+package oidc
+
+import (
+	"errors"
+	"go_project_structure/internal/token"
+	utils "go_project_structure/utils"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type OidcController struct {
+	OidcService OidcService
+}
+
+func NewOidcController(_oidcService OidcService) *OidcController {
+	return &OidcController{
+		OidcService: _oidcService,
+	}
+}
+
+func (oc *OidcController) Discovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.WriteJSONResponse(w, http.StatusOK, oc.OidcService.Discovery())
+}
+
+// Authorize shows the login form of a valid authorization request.
+func (oc *OidcController) Authorize(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("authorize_payload").(AuthorizeRequest)
+
+	client, err := oc.OidcService.ValidateAuthorizeRequest(requestPayload)
+	if err != nil {
+		oc.authorizeError(w, r, client, requestPayload, err)
+		return
+	}
+	writePage(w, http.StatusOK, loginPage, loginPageData{ClientName: client.Name, Request: requestPayload}, requestPayload.RedirectURI)
+}
+
+// AuthorizeLogin logs the user in with the posted form and redirects back to the
+// client with an authorization code. Wrong credentials, or a missing or wrong
+// authentication code, show the form again.
+func (oc *OidcController) AuthorizeLogin(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("authorize_payload").(AuthorizeRequest)
+	email := r.PostForm.Get("email")
+
+	redirectUrl, err := oc.OidcService.Authorize(requestPayload, email, r.PostForm.Get("password"), r.PostForm.Get("mfa_code"))
+	if errors.Is(err, ErrLoginFailed) || errors.Is(err, ErrMfaCodeRequired) || errors.Is(err, ErrMfaCodeInvalid) {
+		client, _ := oc.OidcService.ValidateAuthorizeRequest(requestPayload)
+		writePage(w, http.StatusUnauthorized, loginPage, loginPageData{ClientName: client.Name, Request: requestPayload, Email: email, Error: err.Error()}, requestPayload.RedirectURI)
+		return
+	}
+	if err != nil {
+		client, _ := oc.OidcService.ValidateAuthorizeRequest(requestPayload)
+		oc.authorizeError(w, r, client, requestPayload, err)
+		return
+	}
+	http.Redirect(w, r, redirectUrl, http.StatusFound)
+}
+
+// authorizeError redirects an error back to the client, or shows it when the client
+// or its redirect URI cannot be trusted.
+func (oc *OidcController) authorizeError(w http.ResponseWriter, r *http.Request, client *OidcClient, request AuthorizeRequest, err error) {
+	var oauthErr *OAuthError
+	if !errors.As(err, &oauthErr) {
+		oauthErr = &OAuthError{Code: "server_error", Description: "the request could not be completed"}
+	}
+	if client == nil {
+		writePage(w, http.StatusBadRequest, errorPage, oauthErr.Description, "")
+		return
+	}
+
+	params := url.Values{"error": {oauthErr.Code}, "error_description": {oauthErr.Description}}
+	if request.State != "" {
+		params.Set("state", request.State)
+	}
+	http.Redirect(w, r, RedirectURL(request.RedirectURI, params), http.StatusFound)
+}
+
+func (oc *OidcController) Token(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("oidc_token_payload").(TokenRequest)
+
+	tokens, err := oc.OidcService.Token(requestPayload)
+	if err != nil {
+		var oauthErr *OAuthError
+		if !errors.As(err, &oauthErr) {
+			writeOAuthError(w, http.StatusInternalServerError, &OAuthError{Code: "server_error", Description: "the request could not be completed"})
+			return
+		}
+		status := http.StatusBadRequest
+		if oauthErr.Code == "invalid_client" {
+			status = http.StatusUnauthorized
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		writeOAuthError(w, status, oauthErr)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSONResponse(w, http.StatusOK, tokens)
+}
+
+func (oc *OidcController) UserInfo(w http.ResponseWriter, r *http.Request) {
+	principal, ok := token.PrincipalFromContext(r.Context())
+	if !ok {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", errors.New("missing principal"))
+		return
+	}
+
+	userInfo, err := oc.OidcService.UserInfo(principal.UserID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "User info fetch failed.", err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSONResponse(w, http.StatusOK, userInfo)
+}
+
+func (oc *OidcController) CreateClient(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("create_client_payload").(CreateClientRequest)
+
+	client, err := oc.OidcService.RegisterClient(requestPayload.Name, requestPayload.RedirectURIs, requestPayload.Public)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Client registration failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusCreated, "Client registered; store the client secret now, it cannot be shown again", client)
+}
+
+func (oc *OidcController) GetAllClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := oc.OidcService.GetAllClients()
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Client fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Clients fetched successfully", clients)
+}
+
+func (oc *OidcController) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid client id", err)
+		return
+	}
+
+	err = oc.OidcService.DeleteClient(id)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Client delete failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Client deleted successfully", nil)
+}
+
+// writeOAuthError writes an error of the token endpoint in the format of RFC 6749 5.2.
+func writeOAuthError(w http.ResponseWriter, statusCode int, err *OAuthError) {
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSONResponse(w, statusCode, err)
+}
@@ -0,0 +1,87 @@
+package oidc
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// loginPage is the login form of the authorization endpoint. It posts the
+// authorization request back along with the credentials.
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Sign in to {{.ClientName}}</title>
+</head>
+<body>
+<h1>Sign in to {{.ClientName}}</h1>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="post" action="/oauth/authorize">
+<input type="hidden" name="response_type" value="{{.Request.ResponseType}}">
+<input type="hidden" name="client_id" value="{{.Request.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.Request.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Request.Scope}}">
+<input type="hidden" name="state" value="{{.Request.State}}">
+<input type="hidden" name="nonce" value="{{.Request.Nonce}}">
+<input type="hidden" name="code_challenge" value="{{.Request.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.Request.CodeChallengeMethod}}">
+<label>Email <input type="email" name="email" value="{{.Email}}" required autofocus></label>
+<label>Password <input type="password" name="password" required></label>
+<label>Authentication code <input type="text" name="mfa_code" autocomplete="one-time-code" placeholder="if enabled"></label>
+<button type="submit">Sign in</button>
+</form>
+</body>
+</html>
+`))
+
+// errorPage is shown when an authorization request cannot be redirected back to the client.
+var errorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Sign in failed</title>
+</head>
+<body>
+<h1>Sign in failed</h1>
+<p>{{.}}</p>
+</body>
+</html>
+`))
+
+type loginPageData struct {
+	ClientName string
+	Request    AuthorizeRequest
+	Email      string
+	Error      string
+}
+
+// writePage renders a page of the authorization flow. redirectUri is the validated
+// redirect URI of the client the login form sends the user back to, "" for pages
+// without a form.
+func writePage(w http.ResponseWriter, statusCode int, page *template.Template, data interface{}, redirectUri string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	// the form takes credentials: it must not be framed by another site. Browsers
+	// apply form-action to the redirect that answers the form as well, so the
+	// client the code is redirected to has to be allowed next to 'self'.
+	formAction := "'self'"
+	if source := formActionSource(redirectUri); source != "" {
+		formAction += " " + source
+	}
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; form-action "+formAction+"; frame-ancestors 'none'")
+	w.WriteHeader(statusCode)
+	page.Execute(w, data)
+}
+
+// formActionSource is the CSP source of the origin of a redirect URI, or "" when it
+// is not a plain http(s) origin.
+func formActionSource(redirectUri string) string {
+	target, err := url.Parse(redirectUri)
+	if err != nil || (target.Scheme != "https" && target.Scheme != "http") || target.Host == "" || strings.ContainsAny(target.Host, ";,' ") {
+		return ""
+	}
+	return target.Scheme + "://" + target.Host
+}
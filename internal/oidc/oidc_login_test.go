@@ -0,0 +1,26 @@
+package oidc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWritePageFormAction(t *testing.T) {
+	tests := []struct {
+		redirectUri string
+		want        string
+	}{
+		{"https://app.example.com/callback?x=1", "default-src 'none'; form-action 'self' https://app.example.com; frame-ancestors 'none'"},
+		{"http://localhost:8081/cb", "default-src 'none'; form-action 'self' http://localhost:8081; frame-ancestors 'none'"},
+		{"", "default-src 'none'; form-action 'self'; frame-ancestors 'none'"},
+		{"javascript:alert(1)", "default-src 'none'; form-action 'self'; frame-ancestors 'none'"},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		writePage(recorder, http.StatusOK, errorPage, "", tt.redirectUri)
+		if got := recorder.Header().Get("Content-Security-Policy"); got != tt.want {
+			t.Errorf("redirect uri %q: Content-Security-Policy = %q, want %q", tt.redirectUri, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"go_project_structure/internal/token"
+	utils "go_project_structure/utils"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxRedirectURIs bounds the redirect URIs of one client.
+const maxRedirectURIs = 10
+
+// ClientTokenAuthMiddleware authenticates the caller of the userinfo endpoint with the
+// Bearer access token a client was issued at /oauth/token (RFC 6750). Access tokens
+// of the first-party API and API keys are refused here.
+func ClientTokenAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") || strings.TrimPrefix(authHeader, "Bearer ") == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer`)
+			http.Error(w, "Bearer token missing", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := token.ParseClientAccessToken(strings.TrimPrefix(authHeader, "Bearer "), UserInfoAudience())
+		if err == nil {
+			var principal *token.Principal
+			if principal, err = claims.Principal(); err == nil {
+				fmt.Printf("authenticated user %d (client %s, token %s)\n", principal.UserID, claims.ClientID, principal.TokenID)
+				next.ServeHTTP(w, r.WithContext(token.WithPrincipal(r.Context(), principal)))
+				return
+			}
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
+	})
+}
+
+func CreateClientRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = CreateClientRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("create client payload received.")
+
+		RequestPayload.Name = strings.TrimSpace(RequestPayload.Name)
+		if RequestPayload.Name == "" || len(RequestPayload.Name) > 255 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("name is required and must be at most 255 characters"))
+			return
+		}
+		if len(RequestPayload.RedirectURIs) == 0 || len(RequestPayload.RedirectURIs) > maxRedirectURIs {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("between 1 and %d redirect_uris are required", maxRedirectURIs))
+			return
+		}
+		for _, redirectUri := range RequestPayload.RedirectURIs {
+			if err := validateRedirectURI(redirectUri); err != nil {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", err)
+				return
+			}
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "create_client_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AuthorizeRequestValidator reads the authorization request from the query, or from
+// the login form when it is posted back.
+func AuthorizeRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 16*1024)
+		if err := r.ParseForm(); err != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", err)
+			return
+		}
+
+		RequestPayload := AuthorizeRequest{
+			ResponseType:        r.Form.Get("response_type"),
+			ClientID:            r.Form.Get("client_id"),
+			RedirectURI:         r.Form.Get("redirect_uri"),
+			Scope:               r.Form.Get("scope"),
+			State:               r.Form.Get("state"),
+			Nonce:               r.Form.Get("nonce"),
+			CodeChallenge:       r.Form.Get("code_challenge"),
+			CodeChallengeMethod: r.Form.Get("code_challenge_method"),
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "authorize_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TokenRequestValidator reads the form of a token request. Client credentials sent
+// with HTTP basic authentication take precedence over the ones in the form.
+func TokenRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 16*1024)
+		if err := r.ParseForm(); err != nil {
+			writeOAuthError(w, http.StatusBadRequest, &OAuthError{Code: "invalid_request", Description: "the request body must be form encoded"})
+			return
+		}
+
+		RequestPayload := TokenRequest{
+			GrantType:    r.PostForm.Get("grant_type"),
+			Code:         r.PostForm.Get("code"),
+			RedirectURI:  r.PostForm.Get("redirect_uri"),
+			CodeVerifier: r.PostForm.Get("code_verifier"),
+			RefreshToken: r.PostForm.Get("refresh_token"),
+			ClientID:     r.PostForm.Get("client_id"),
+			ClientSecret: r.PostForm.Get("client_secret"),
+		}
+		if clientId, clientSecret, ok := r.BasicAuth(); ok {
+			// credentials are form encoded before they are put in the header (RFC 6749 2.3.1)
+			RequestPayload.ClientID, _ = url.QueryUnescape(clientId)
+			RequestPayload.ClientSecret, _ = url.QueryUnescape(clientSecret)
+		}
+		if RequestPayload.GrantType == "" {
+			writeOAuthError(w, http.StatusBadRequest, &OAuthError{Code: "invalid_request", Description: "grant_type is required"})
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "oidc_token_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validateRedirectURI accepts absolute https URIs without a fragment, and http ones
+// on the loopback interface for local development.
+func validateRedirectURI(redirectUri string) error {
+	target, err := url.Parse(redirectUri)
+	if err != nil || !target.IsAbs() || target.Host == "" || target.Fragment != "" || len(redirectUri) > 2048 || strings.ContainsAny(redirectUri, " \t\r\n") {
+		return fmt.Errorf("redirect uri %q must be an absolute URI without a fragment", redirectUri)
+	}
+	switch target.Scheme {
+	case "https":
+		return nil
+	case "http":
+		host := target.Hostname()
+		if host == "localhost" || host == "127.0.0.1" || host == "::1" {
+			return nil
+		}
+	}
+	return fmt.Errorf("redirect uri %q must use https", redirectUri)
+}
@@ -0,0 +1,38 @@
+package oidc
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OidcClient is an application registered to log its users in through this provider.
+type OidcClient struct {
+	gorm.Model
+	ClientID string `gorm:"size:64;not null;uniqueIndex"`
+	// SecretHash is the SHA-256 of the secret of a confidential client. Public clients
+	// have none and must use PKCE.
+	SecretHash string `gorm:"size:64;not null;default:''"`
+	Name       string `gorm:"size:255;not null"`
+	// RedirectURIs are the exact redirect URIs of the client, space separated
+	RedirectURIs string `gorm:"not null"`
+}
+
+// OidcAuthorizationCode is an authorization code handed to a client after its user
+// logged in. Only its SHA-256 hash is stored; it is exchanged once, shortly after it
+// is issued, by the client and with the redirect URI it was issued for. SessionID is
+// the session its exchange started, revoked if the code is presented again.
+type OidcAuthorizationCode struct {
+	gorm.Model
+	CodeHash      string    `gorm:"size:64;not null;uniqueIndex"`
+	ClientID      string    `gorm:"size:64;not null;index"`
+	UserID        uint      `gorm:"not null;index"`
+	RedirectURI   string    `gorm:"not null"`
+	Scope         string    `gorm:"size:255;not null"`
+	Nonce         string    `gorm:"size:255;not null;default:''"`
+	CodeChallenge string    `gorm:"size:128;not null;default:''"`
+	SessionID     string    `gorm:"size:64;not null;default:''"`
+	AuthTime      time.Time `gorm:"not null"`
+	ExpiresAt     time.Time `gorm:"not null"`
+	UsedAt        *time.Time
+}
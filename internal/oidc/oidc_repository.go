@@ -0,0 +1,233 @@
+package oidc
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OidcRepository interface {
+	CreateClient(clientId string, secretHash string, name string, redirectUris string) (*OidcClient, error)
+	GetClientByClientID(clientId string) (*OidcClient, error)
+	GetAllClients() ([]*OidcClient, error)
+	DeleteClient(id int64) error
+	CreateAuthorizationCode(code *OidcAuthorizationCode) error
+	ConsumeAuthorizationCode(codeHash string) (*OidcAuthorizationCode, error)
+	SetCodeSession(id uint, sessionId string) error
+}
+
+type OidcRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewOidcRepository(_db *gorm.DB) OidcRepository {
+	return &OidcRepositoryImpl{
+		db: _db,
+	}
+}
+
+const oidcClientColumns = "c.id, c.client_id, c.secret_hash, c.name, c.redirect_uris, c.created_at, c.updated_at"
+
+const authorizationCodeColumns = "a.id, a.code_hash, a.client_id, a.user_id, a.redirect_uri, a.scope, a.nonce, a.code_challenge, a.session_id, a.auth_time, a.expires_at, a.used_at, a.created_at, a.updated_at"
+
+func (u *OidcRepositoryImpl) CreateClient(clientId string, secretHash string, name string, redirectUris string) (*OidcClient, error) {
+	fmt.Println("Creating client in oidc repository.")
+
+	// step 1: prepare the query
+	query := `INSERT INTO oidc_clients AS c (client_id, secret_hash, name, redirect_uris) VALUES (?, ?, ?, ?)
+		RETURNING ` + oidcClientColumns
+
+	// step 2: execute the query
+	row := u.db.Raw(query, clientId, secretHash, name, redirectUris).Row()
+
+	// step 3: process the result
+	client, err := scanOidcClient(row)
+	if err != nil {
+		fmt.Printf("Error creating client: %v\n", err)
+		return nil, err
+	}
+
+	// step 4: return the result
+	fmt.Printf("Created client %s (%s)\n", clientId, name)
+	return client, nil
+}
+
+func (u *OidcRepositoryImpl) GetClientByClientID(clientId string) (*OidcClient, error) {
+	fmt.Println("Fetching client by client id in oidc repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + oidcClientColumns + " FROM oidc_clients c WHERE c.deleted_at IS NULL AND c.client_id = ?"
+
+	// step 2: execute the query
+	row := u.db.Raw(query, clientId).Row()
+
+	// step 3: process the result
+	client, err := scanOidcClient(row)
+	if err != nil {
+		fmt.Printf("Error fetching client: %v\n", err)
+		return nil, err
+	}
+
+	// step 4: return the result
+	return client, nil
+}
+
+func (u *OidcRepositoryImpl) GetAllClients() ([]*OidcClient, error) {
+	fmt.Println("Fetching all clients in oidc repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + oidcClientColumns + " FROM oidc_clients c WHERE c.deleted_at IS NULL ORDER BY c.id"
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	clients := []*OidcClient{}
+	for rows.Next() {
+		client := &OidcClient{}
+		err := rows.Scan(&client.ID, &client.ClientID, &client.SecretHash, &client.Name, &client.RedirectURIs, &client.CreatedAt, &client.UpdatedAt)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+
+	// step 4: return the result
+	return clients, rows.Err()
+}
+
+// DeleteClient unregisters a client. Its pending authorization codes can no longer be
+// exchanged since exchanging one looks the client up.
+func (u *OidcRepositoryImpl) DeleteClient(id int64) error {
+	fmt.Println("Deleting client in oidc repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE oidc_clients SET deleted_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND id = ?"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, id)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error deleting client: %v\n", result.Error)
+		return result.Error
+	}
+
+	// step 4: evaluate the result
+	if result.RowsAffected == 0 {
+		fmt.Println("No client was deleted.")
+		return fmt.Errorf("No client was deleted.")
+	}
+
+	// step 5: return the result
+	return nil
+}
+
+func (u *OidcRepositoryImpl) CreateAuthorizationCode(code *OidcAuthorizationCode) error {
+	fmt.Println("Creating authorization code in oidc repository.")
+
+	// step 1: prepare the query
+	query := `INSERT INTO oidc_authorization_codes (code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, auth_time, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// step 2: execute the query
+	result := u.db.Exec(query, code.CodeHash, code.ClientID, code.UserID, code.RedirectURI, code.Scope, code.Nonce, code.CodeChallenge, code.AuthTime, code.ExpiresAt)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error creating authorization code: %v\n", result.Error)
+		return result.Error
+	}
+
+	// step 4: return the result
+	fmt.Printf("Created authorization code for user %d and client %s\n", code.UserID, code.ClientID)
+	return nil
+}
+
+// ConsumeAuthorizationCode marks the code with the given hash as used and returns it.
+// A code that is unknown or expired is refused with ErrAuthorizationCodeInvalid; one
+// that was already used is returned with ErrAuthorizationCodeReused.
+func (u *OidcRepositoryImpl) ConsumeAuthorizationCode(codeHash string) (*OidcAuthorizationCode, error) {
+	fmt.Println("Consuming authorization code in oidc repository.")
+
+	var code *OidcAuthorizationCode
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		// step 1: lock the code so concurrent exchanges of it serialize
+		row := tx.Raw("SELECT "+authorizationCodeColumns+" FROM oidc_authorization_codes a WHERE a.deleted_at IS NULL AND a.code_hash = ? FOR UPDATE", codeHash).Row()
+		var err error
+		code, err = scanAuthorizationCode(row)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAuthorizationCodeInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		// step 2: refuse codes that may not be exchanged
+		switch {
+		case code.UsedAt != nil:
+			return ErrAuthorizationCodeReused
+		case !code.ExpiresAt.After(time.Now()):
+			return ErrAuthorizationCodeInvalid
+		}
+
+		// step 3: use it up
+		return tx.Exec("UPDATE oidc_authorization_codes SET used_at = NOW(), updated_at = NOW() WHERE id = ?", code.ID).Error
+	})
+
+	if errors.Is(err, ErrAuthorizationCodeReused) {
+		fmt.Printf("Authorization code %d was presented again\n", code.ID)
+		return code, err
+	}
+	if err != nil {
+		fmt.Printf("Error consuming authorization code: %v\n", err)
+		return nil, err
+	}
+	return code, nil
+}
+
+// SetCodeSession records the session the exchange of a code started.
+func (u *OidcRepositoryImpl) SetCodeSession(id uint, sessionId string) error {
+	fmt.Println("Setting authorization code session in oidc repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE oidc_authorization_codes SET session_id = ?, updated_at = NOW() WHERE id = ?"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, sessionId, id)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error setting authorization code session: %v\n", result.Error)
+		return result.Error
+	}
+	return nil
+}
+
+func scanOidcClient(row *sql.Row) (*OidcClient, error) {
+	client := &OidcClient{}
+	err := row.Scan(&client.ID, &client.ClientID, &client.SecretHash, &client.Name, &client.RedirectURIs, &client.CreatedAt, &client.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func scanAuthorizationCode(row *sql.Row) (*OidcAuthorizationCode, error) {
+	code := &OidcAuthorizationCode{}
+	err := row.Scan(&code.ID, &code.CodeHash, &code.ClientID, &code.UserID, &code.RedirectURI, &code.Scope, &code.Nonce, &code.CodeChallenge,
+		&code.SessionID, &code.AuthTime, &code.ExpiresAt, &code.UsedAt, &code.CreatedAt, &code.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return code, nil
+}
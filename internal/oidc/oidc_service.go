@@ -0,0 +1,564 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	env "go_project_structure/config/env"
+	"go_project_structure/internal/mfa"
+	refreshtoken "go_project_structure/internal/refresh_token"
+	serviceaccount "go_project_structure/internal/service_account"
+	"go_project_structure/internal/token"
+	"go_project_structure/internal/user"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrAuthorizationCodeInvalid = errors.New("authorization code is invalid or expired")
+	ErrAuthorizationCodeReused  = errors.New("authorization code was already used")
+	ErrLoginFailed              = errors.New("invalid email or password")
+	ErrMfaCodeRequired          = errors.New("enter the code from your authenticator app")
+	ErrMfaCodeInvalid           = errors.New("invalid authentication code")
+)
+
+// supportedScopes are the scopes a client may request; others are ignored.
+var supportedScopes = []string{"openid", "profile", "email"}
+
+type OidcService interface {
+	RegisterClient(name string, redirectUris []string, public bool) (*ClientResponse, error)
+	GetAllClients() ([]*ClientResponse, error)
+	DeleteClient(id int64) error
+	Discovery() DiscoveryDocument
+	ValidateAuthorizeRequest(request AuthorizeRequest) (*OidcClient, error)
+	Authorize(request AuthorizeRequest, email string, password string, mfaCode string) (string, error)
+	Token(request TokenRequest) (*TokenResponse, error)
+	UserInfo(userId int64) (*UserInfoResponse, error)
+}
+
+type OidcServiceImpl struct {
+	oidcRepository        OidcRepository
+	userService           user.UserService
+	refreshTokenService   refreshtoken.RefreshTokenService
+	serviceAccountService serviceaccount.ServiceAccountService
+	mfaService            mfa.MfaService
+}
+
+func NewOidcService(_oidcRepository OidcRepository, _userService user.UserService, _refreshTokenService refreshtoken.RefreshTokenService, _serviceAccountService serviceaccount.ServiceAccountService, _mfaService mfa.MfaService) OidcService {
+	return &OidcServiceImpl{
+		oidcRepository:        _oidcRepository,
+		userService:           _userService,
+		refreshTokenService:   _refreshTokenService,
+		serviceAccountService: _serviceAccountService,
+		mfaService:            _mfaService,
+	}
+}
+
+// Issuer is the issuer identifier of the provider, the URL this service is reached at.
+func Issuer() string {
+	return strings.TrimSuffix(env.GetString("OIDC_ISSUER", "http://localhost:8080"), "/")
+}
+
+// Enabled reports whether this service acts as an OpenID Connect provider, which
+// OIDC_PROVIDER_ENABLED turns on.
+func Enabled() bool {
+	return env.GetBool("OIDC_PROVIDER_ENABLED", false)
+}
+
+// CheckSigningKeys refuses to run the provider while tokens are signed with the
+// shared JWT_SECRET: clients verify ID tokens against the published JWKS, which
+// then holds no key, and anyone able to verify them could also forge them.
+func CheckSigningKeys() error {
+	if Enabled() && !token.AsymmetricSigning() {
+		return errors.New("OIDC_PROVIDER_ENABLED needs signing keys in JWT_KEYS_DIR")
+	}
+	return nil
+}
+
+// UserInfoAudience is the audience of the access tokens issued to clients: they
+// are good for the userinfo endpoint only, not for the rest of the API.
+func UserInfoAudience() string {
+	return Issuer() + "/userinfo"
+}
+
+func authorizationCodeTTL() time.Duration {
+	return time.Duration(env.GetInt("OIDC_CODE_TTL_SECONDS", 60)) * time.Second
+}
+
+// RegisterClient registers a client and returns it with its secret, which is not
+// stored and cannot be shown again. Public clients get no secret.
+func (op *OidcServiceImpl) RegisterClient(name string, redirectUris []string, public bool) (*ClientResponse, error) {
+	fmt.Println("Registering client in oidc service.")
+	clientId, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	secret, secretHash := "", ""
+	if !public {
+		secret, err = randomToken(32)
+		if err != nil {
+			return nil, err
+		}
+		secretHash = hashToken(secret)
+	}
+
+	client, err := op.oidcRepository.CreateClient(clientId, secretHash, name, strings.Join(redirectUris, " "))
+	if err != nil {
+		fmt.Printf("Error registering client: %v\n", err)
+		return nil, err
+	}
+	response := clientResponse(client)
+	response.ClientSecret = secret
+	return response, nil
+}
+
+func (op *OidcServiceImpl) GetAllClients() ([]*ClientResponse, error) {
+	fmt.Println("Fetching all clients in oidc service.")
+	clients, err := op.oidcRepository.GetAllClients()
+	if err != nil {
+		fmt.Printf("Error fetching clients: %v\n", err)
+		return nil, err
+	}
+	responses := []*ClientResponse{}
+	for _, client := range clients {
+		responses = append(responses, clientResponse(client))
+	}
+	return responses, nil
+}
+
+func (op *OidcServiceImpl) DeleteClient(id int64) error {
+	fmt.Println("Deleting client in oidc service.")
+	if err := op.oidcRepository.DeleteClient(id); err != nil {
+		fmt.Printf("Error deleting client: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func (op *OidcServiceImpl) Discovery() DiscoveryDocument {
+	issuer := Issuer()
+	return DiscoveryDocument{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/token",
+		UserInfoEndpoint:                  issuer + "/userinfo",
+		JwksURI:                           issuer + "/.well-known/jwks.json",
+		ScopesSupported:                   supportedScopes,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token", "client_credentials"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{token.SigningAlgorithm()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "azp", "sid", "name", "email"},
+	}
+}
+
+// ValidateAuthorizeRequest checks an authorization request. The client is returned
+// only once the redirect URI is known to be one of its own, so that errors can be
+// redirected to it; before that they must be shown to the user instead.
+func (op *OidcServiceImpl) ValidateAuthorizeRequest(request AuthorizeRequest) (*OidcClient, error) {
+	client, err := op.oidcRepository.GetClientByClientID(request.ClientID)
+	if err != nil {
+		return nil, &OAuthError{Code: "invalid_request", Description: "unknown client_id"}
+	}
+	if !hasRedirectURI(client, request.RedirectURI) {
+		return nil, &OAuthError{Code: "invalid_request", Description: "redirect_uri is not registered for the client"}
+	}
+
+	if request.ResponseType != "code" {
+		return client, &OAuthError{Code: "unsupported_response_type", Description: "only the code response type is supported"}
+	}
+	if _, err := parseScope(request.Scope); err != nil {
+		return client, err
+	}
+	if request.CodeChallenge != "" {
+		if request.CodeChallengeMethod != "S256" {
+			return client, &OAuthError{Code: "invalid_request", Description: "code_challenge_method must be S256"}
+		}
+		if len(request.CodeChallenge) != 43 {
+			return client, &OAuthError{Code: "invalid_request", Description: "code_challenge must be a base64url encoded SHA-256 hash"}
+		}
+	} else if client.SecretHash == "" {
+		return client, &OAuthError{Code: "invalid_request", Description: "public clients must use PKCE"}
+	}
+	if len(request.Nonce) > 255 || len(request.State) > 1024 {
+		return client, &OAuthError{Code: "invalid_request", Description: "nonce or state is too long"}
+	}
+	return client, nil
+}
+
+// Authorize logs the user in and returns the redirect URI of the client with an
+// authorization code and the state of the request. Users who need a second factor
+// must send a code from their authenticator app or a recovery code along.
+func (op *OidcServiceImpl) Authorize(request AuthorizeRequest, email string, password string, mfaCode string) (string, error) {
+	fmt.Println("Authorizing client in oidc service.")
+	client, err := op.ValidateAuthorizeRequest(request)
+	if err != nil {
+		return "", err
+	}
+
+	loggedIn, err := op.userService.Authenticate(email, password)
+	if err != nil {
+		return "", ErrLoginFailed
+	}
+	if err := op.verifyMfa(int64(loggedIn.ID), mfaCode); err != nil {
+		return "", err
+	}
+
+	code, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	scopes, _ := parseScope(request.Scope)
+	now := time.Now()
+	err = op.oidcRepository.CreateAuthorizationCode(&OidcAuthorizationCode{
+		CodeHash:      hashToken(code),
+		ClientID:      client.ClientID,
+		UserID:        loggedIn.ID,
+		RedirectURI:   request.RedirectURI,
+		Scope:         strings.Join(scopes, " "),
+		Nonce:         request.Nonce,
+		CodeChallenge: request.CodeChallenge,
+		AuthTime:      now,
+		ExpiresAt:     now.Add(authorizationCodeTTL()),
+	})
+	if err != nil {
+		fmt.Printf("Error creating authorization code: %v\n", err)
+		return "", err
+	}
+
+	params := url.Values{"code": {code}}
+	if request.State != "" {
+		params.Set("state", request.State)
+	}
+	return RedirectURL(request.RedirectURI, params), nil
+}
+
+// verifyMfa checks the second factor of a user who needs one. Users a role requires
+// to use MFA but who have not enrolled yet are sent to enroll at /login first.
+func (op *OidcServiceImpl) verifyMfa(userId int64, mfaCode string) error {
+	required, err := op.mfaService.Required(userId)
+	if err != nil {
+		return err
+	}
+	if !required {
+		return nil
+	}
+	if strings.TrimSpace(mfaCode) == "" {
+		return ErrMfaCodeRequired
+	}
+	err = op.mfaService.VerifyCode(userId, mfaCode)
+	switch {
+	case errors.Is(err, mfa.ErrCodeInvalid):
+		return ErrMfaCodeInvalid
+	case errors.Is(err, mfa.ErrNotEnabled):
+		// passkeys need the browser API this form does not run
+		return &OAuthError{Code: "access_denied", Description: "multi-factor authentication is required and this form takes authenticator app codes; enroll an authenticator app by logging in at /login"}
+	}
+	return err
+}
+
+// Token serves the token endpoint for the authorization_code and refresh_token grants
+// of registered clients, and the client_credentials grant of service accounts.
+func (op *OidcServiceImpl) Token(request TokenRequest) (*TokenResponse, error) {
+	fmt.Println("Issuing tokens in oidc service.")
+	if request.GrantType == "client_credentials" {
+		return op.clientCredentials(request)
+	}
+	client, err := op.authenticateClient(request.ClientID, request.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch request.GrantType {
+	case "authorization_code":
+		return op.exchangeCode(client, request)
+	case "refresh_token":
+		return op.refresh(client, request)
+	default:
+		return nil, &OAuthError{Code: "unsupported_grant_type", Description: "grant_type must be authorization_code, refresh_token or client_credentials"}
+	}
+}
+
+func (op *OidcServiceImpl) UserInfo(userId int64) (*UserInfoResponse, error) {
+	fmt.Println("Fetching user info in oidc service.")
+	found, err := op.userService.GetUserById(strconv.FormatInt(userId, 10))
+	if err != nil {
+		fmt.Printf("Error fetching user: %v\n", err)
+		return nil, err
+	}
+	return &UserInfoResponse{
+		Subject: strconv.FormatUint(uint64(found.ID), 10),
+		Name:    found.Name,
+		Email:   found.Email,
+	}, nil
+}
+
+// authenticateClient checks the secret of a confidential client. Public clients only
+// name themselves; PKCE binds their codes to them.
+func (op *OidcServiceImpl) authenticateClient(clientId string, secret string) (*OidcClient, error) {
+	if clientId == "" {
+		return nil, &OAuthError{Code: "invalid_client", Description: "client authentication failed"}
+	}
+	client, err := op.oidcRepository.GetClientByClientID(clientId)
+	if err != nil {
+		return nil, &OAuthError{Code: "invalid_client", Description: "client authentication failed"}
+	}
+	if client.SecretHash != "" && subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(client.SecretHash)) != 1 {
+		return nil, &OAuthError{Code: "invalid_client", Description: "client authentication failed"}
+	}
+	return client, nil
+}
+
+// clientCredentials issues an access token to the service account the client
+// credentials belong to. No refresh token or ID token is issued (RFC 6749 4.4.3).
+func (op *OidcServiceImpl) clientCredentials(request TokenRequest) (*TokenResponse, error) {
+	if request.ClientID == "" || request.ClientSecret == "" {
+		return nil, &OAuthError{Code: "invalid_client", Description: "client authentication failed"}
+	}
+	accessToken, _, err := op.serviceAccountService.IssueAccessToken(request.ClientID, request.ClientSecret)
+	if errors.Is(err, serviceaccount.ErrClientAuthenticationFailed) {
+		return nil, &OAuthError{Code: "invalid_client", Description: "client authentication failed"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(token.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// exchangeCode redeems an authorization code for a new session and an ID token.
+// A code presented twice revokes the session its first exchange started.
+func (op *OidcServiceImpl) exchangeCode(client *OidcClient, request TokenRequest) (*TokenResponse, error) {
+	code, err := op.oidcRepository.ConsumeAuthorizationCode(hashToken(request.Code))
+	if errors.Is(err, ErrAuthorizationCodeReused) {
+		if code.SessionID != "" {
+			if err := op.refreshTokenService.RevokeFamily(code.SessionID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, &OAuthError{Code: "invalid_grant", Description: err.Error()}
+	}
+	if errors.Is(err, ErrAuthorizationCodeInvalid) {
+		return nil, &OAuthError{Code: "invalid_grant", Description: err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if code.ClientID != client.ClientID || code.RedirectURI != request.RedirectURI {
+		return nil, &OAuthError{Code: "invalid_grant", Description: "the code was issued to another client or redirect_uri"}
+	}
+	if code.CodeChallenge != "" && !verifyCodeChallenge(code.CodeChallenge, request.CodeVerifier) {
+		return nil, &OAuthError{Code: "invalid_grant", Description: "code_verifier does not match the code_challenge"}
+	}
+
+	found, err := op.userService.GetUserById(strconv.FormatUint(uint64(code.UserID), 10))
+	if err != nil {
+		return nil, &OAuthError{Code: "invalid_grant", Description: "the user no longer exists"}
+	}
+	refreshToken, session, err := op.refreshTokenService.Issue(int64(found.ID), 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := op.oidcRepository.SetCodeSession(code.ID, session.FamilyID); err != nil {
+		return nil, err
+	}
+	// only this client may refresh the session (RFC 6749 6)
+	if err := op.refreshTokenService.BindFamily(session.FamilyID, client.ClientID); err != nil {
+		return nil, err
+	}
+	accessToken, err := issueClientAccessToken(client, int64(found.ID), session.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := strings.Fields(code.Scope)
+	claims := newIDTokenClaims(client, found.ID, session.FamilyID)
+	claims.Nonce = code.Nonce
+	claims.AuthTime = code.AuthTime.Unix()
+	if hasScope(scopes, "profile") {
+		claims.Name = found.Name
+	}
+	if hasScope(scopes, "email") {
+		claims.Email = found.Email
+	}
+	idToken, err := token.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(token.AccessTokenTTL().Seconds()),
+		RefreshToken: refreshToken,
+		IDToken:      idToken,
+		Scope:        code.Scope,
+	}, nil
+}
+
+// refresh rotates a refresh token and issues a fresh access token and ID token.
+// Only the client the token was issued to may present it (RFC 6749 6); tokens of
+// /login sessions were issued to no client and are refused.
+func (op *OidcServiceImpl) refresh(client *OidcClient, request TokenRequest) (*TokenResponse, error) {
+	issued, err := op.refreshTokenService.Lookup(request.RefreshToken)
+	if errors.Is(err, refreshtoken.ErrRefreshTokenInvalid) {
+		return nil, &OAuthError{Code: "invalid_grant", Description: err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if issued.ClientID == nil || *issued.ClientID != client.ClientID {
+		return nil, &OAuthError{Code: "invalid_grant", Description: "the refresh token was issued to another client"}
+	}
+
+	refreshToken, rotated, err := op.refreshTokenService.Rotate(request.RefreshToken)
+	if errors.Is(err, refreshtoken.ErrRefreshTokenInvalid) || errors.Is(err, refreshtoken.ErrRefreshTokenReused) {
+		return nil, &OAuthError{Code: "invalid_grant", Description: err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the session ends if the user has been deleted since
+	if _, err := op.userService.GetUserById(strconv.FormatUint(uint64(rotated.UserID), 10)); err != nil {
+		if err := op.refreshTokenService.RevokeFamily(rotated.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, &OAuthError{Code: "invalid_grant", Description: "the user no longer exists"}
+	}
+
+	accessToken, err := issueClientAccessToken(client, int64(rotated.UserID), rotated.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := token.Sign(newIDTokenClaims(client, rotated.UserID, rotated.FamilyID))
+	if err != nil {
+		return nil, err
+	}
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(token.AccessTokenTTL().Seconds()),
+		RefreshToken: refreshToken,
+		IDToken:      idToken,
+	}, nil
+}
+
+// issueClientAccessToken issues the access token of a client session: it names the
+// client and is only good for the userinfo endpoint, never for the first-party API.
+func issueClientAccessToken(client *OidcClient, userId int64, sessionId string) (string, error) {
+	claims, err := token.NewClientAccessClaims(userId, client.ClientID, UserInfoAudience(), sessionId)
+	if err != nil {
+		return "", err
+	}
+	return token.SignAccessToken(claims)
+}
+
+func newIDTokenClaims(client *OidcClient, userId uint, sessionId string) *IDTokenClaims {
+	now := time.Now()
+	return &IDTokenClaims{
+		AuthorizedParty: client.ClientID,
+		SessionID:       sessionId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(userId), 10),
+			Issuer:    Issuer(),
+			Audience:  jwt.ClaimStrings{client.ClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(token.AccessTokenTTL())),
+		},
+	}
+}
+
+// RedirectURL appends the parameters to the query of a redirect URI.
+func RedirectURL(redirectUri string, params url.Values) string {
+	target, err := url.Parse(redirectUri)
+	if err != nil {
+		return redirectUri
+	}
+	query := target.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+func clientResponse(client *OidcClient) *ClientResponse {
+	return &ClientResponse{
+		ID:           client.ID,
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		RedirectURIs: strings.Fields(client.RedirectURIs),
+		Public:       client.SecretHash == "",
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+func hasRedirectURI(client *OidcClient, redirectUri string) bool {
+	for _, registered := range strings.Fields(client.RedirectURIs) {
+		if registered == redirectUri {
+			return true
+		}
+	}
+	return false
+}
+
+// parseScope keeps the supported scopes of a request, which must include openid.
+func parseScope(scope string) ([]string, error) {
+	scopes := []string{}
+	for _, requested := range strings.Fields(scope) {
+		if hasScope(supportedScopes, requested) && !hasScope(scopes, requested) {
+			scopes = append(scopes, requested)
+		}
+	}
+	if !hasScope(scopes, "openid") {
+		return nil, &OAuthError{Code: "invalid_scope", Description: "the openid scope is required"}
+	}
+	return scopes, nil
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyCodeChallenge checks a PKCE code verifier against its S256 challenge.
+func verifyCodeChallenge(challenge string, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+// randomToken returns size random bytes, URL-safe base64 encoded.
+func randomToken(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// hashToken is the SHA-256 of a secret as stored in the database.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
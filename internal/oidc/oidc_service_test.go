@@ -0,0 +1,15 @@
+package oidc
+
+import "testing"
+
+func TestCheckSigningKeys(t *testing.T) {
+	// no JWT_KEYS_DIR is loaded, so tokens are signed with JWT_SECRET
+	t.Setenv("OIDC_PROVIDER_ENABLED", "false")
+	if err := CheckSigningKeys(); err != nil {
+		t.Errorf("CheckSigningKeys with the provider disabled: %v", err)
+	}
+	t.Setenv("OIDC_PROVIDER_ENABLED", "true")
+	if err := CheckSigningKeys(); err == nil {
+		t.Error("CheckSigningKeys accepted HS256 signing for the provider")
+	}
+}
@@ -0,0 +1,13 @@
+package organization
+
+type CreateOrganizationRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Slug        string `json:"slug" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name        *string `json:"name"`
+	Slug        *string `json:"slug"`
+	Description *string `json:"description"`
+}
@@ -0,0 +1,99 @@
+package organization
+
+import (
+	"errors"
+	utils "go_project_structure/utils"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type OrganizationController struct {
+	OrganizationService OrganizationService
+}
+
+func NewOrganizationController(_organizationService OrganizationService) *OrganizationController {
+	return &OrganizationController{
+		OrganizationService: _organizationService,
+	}
+}
+
+// organizationErrorStatus maps organization service errors to http status codes.
+func organizationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrOrganizationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrOrganizationSlugTaken), errors.Is(err, ErrOrganizationInUse):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidSlug):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (oc *OrganizationController) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("create_organization_payload").(CreateOrganizationRequest)
+
+	organization, err := oc.OrganizationService.CreateOrganization(requestPayload.Name, requestPayload.Slug, requestPayload.Description)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, organizationErrorStatus(err), "Organization creation failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusCreated, "Organization created successfully", organization)
+}
+
+func (oc *OrganizationController) GetOrganizationById(w http.ResponseWriter, r *http.Request) {
+	organizationId := chi.URLParam(r, "id")
+
+	organization, err := oc.OrganizationService.GetOrganizationById(organizationId)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, organizationErrorStatus(err), "Organization fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get organization by id end point", organization)
+}
+
+func (oc *OrganizationController) GetAllOrganizations(w http.ResponseWriter, r *http.Request) {
+	organizations, err := oc.OrganizationService.GetAllOrganizations()
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, organizationErrorStatus(err), "Organization fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get all organizations end point", organizations)
+}
+
+func (oc *OrganizationController) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	organizationId := chi.URLParam(r, "id")
+
+	requestPayload := r.Context().Value("update_organization_payload").(UpdateOrganizationRequest)
+
+	message, err := oc.OrganizationService.UpdateOrganization(organizationId, requestPayload.Name, requestPayload.Slug, requestPayload.Description)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, organizationErrorStatus(err), "Organization update failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, message, nil)
+}
+
+func (oc *OrganizationController) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	organizationId := chi.URLParam(r, "id")
+
+	message, err := oc.OrganizationService.DeleteOrganization(organizationId)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, organizationErrorStatus(err), "Organization delete failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, message, nil)
+}
+
+func (oc *OrganizationController) PermanentlyDeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	organizationId := chi.URLParam(r, "id")
+
+	message, err := oc.OrganizationService.PermanentlyDeleteOrganization(organizationId)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, organizationErrorStatus(err), "Organization delete failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, message, nil)
+}
@@ -0,0 +1,88 @@
+package organization
+
+import (
+	"context"
+	"fmt"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strings"
+)
+
+func CreateOrganizationRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = CreateOrganizationRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("create organization payload received.")
+
+		RequestPayload.Name = strings.TrimSpace(RequestPayload.Name)
+		RequestPayload.Slug = strings.TrimSpace(RequestPayload.Slug)
+		RequestPayload.Description = strings.TrimSpace(RequestPayload.Description)
+		if RequestPayload.Name == "" || len(RequestPayload.Name) > 255 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("name is required and must be at most 255 characters"))
+			return
+		}
+		if !slugPattern.MatchString(RequestPayload.Slug) || len(RequestPayload.Slug) > 100 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", ErrInvalidSlug)
+			return
+		}
+		if len(RequestPayload.Description) > 255 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("description must be at most 255 characters"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "create_organization_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func UpdateOrganizationRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = UpdateOrganizationRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("update organization payload received.")
+
+		if RequestPayload.Name == nil && RequestPayload.Slug == nil && RequestPayload.Description == nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("at least one field is required"))
+			return
+		}
+		if RequestPayload.Name != nil {
+			name := strings.TrimSpace(*RequestPayload.Name)
+			if name == "" || len(name) > 255 {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("name must be between 1 and 255 characters"))
+				return
+			}
+			RequestPayload.Name = &name
+		}
+		if RequestPayload.Slug != nil {
+			slug := strings.TrimSpace(*RequestPayload.Slug)
+			if !slugPattern.MatchString(slug) || len(slug) > 100 {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", ErrInvalidSlug)
+				return
+			}
+			RequestPayload.Slug = &slug
+		}
+		if RequestPayload.Description != nil {
+			description := strings.TrimSpace(*RequestPayload.Description)
+			if len(description) > 255 {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("description must be at most 255 characters"))
+				return
+			}
+			RequestPayload.Description = &description
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "update_organization_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
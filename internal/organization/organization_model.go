@@ -0,0 +1,14 @@
+package organization
+
+import (
+	"gorm.io/gorm"
+)
+
+// Organization is a tenant. Roles and role assignments may be scoped to one,
+// so a user can hold different roles in different organizations.
+type Organization struct {
+	gorm.Model
+	Name        string `gorm:"size:255;not null"`
+	Slug        string `gorm:"size:100;not null;uniqueIndex:idx_organizations_slug_live,where:deleted_at IS NULL"`
+	Description string `gorm:"size:255;not null;default:''"`
+}
@@ -0,0 +1,235 @@
+package organization
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+type OrganizationRepository interface {
+	Create(name string, slug string, description string) error
+	GetByID(id string) (*Organization, error)
+	GetAll() ([]*Organization, error)
+	Update(id string, name *string, slug *string, description *string) (string, error)
+	SoftDelete(id string) (string, error)
+	HardDelete(id string) (string, error)
+
+	GetBySlug(slug string) (*Organization, error)
+}
+
+type OrganizationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(_db *gorm.DB) OrganizationRepository {
+	return &OrganizationRepositoryImpl{
+		db: _db,
+	}
+}
+
+func (o *OrganizationRepositoryImpl) Create(name string, slug string, description string) error {
+	fmt.Println("creating organization in organization repository.")
+
+	// step 1: prepare the query
+	query := "INSERT INTO organizations (name, slug, description) VALUES (?, ?, ?)"
+
+	// step 2: execute the query
+	result := o.db.Exec(query, name, slug, description)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(result.Error, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // unique_violation
+				return fmt.Errorf("unique constraint violation")
+			case "23502": // not_null_violation
+				return fmt.Errorf("not null violation.")
+			default:
+				return fmt.Errorf("database error: %v", pgErr.Message)
+			}
+		}
+		return result.Error
+	}
+
+	// step 4: evaluate the result
+	fmt.Printf("Created organization (rows affected: %d)\n", result.RowsAffected)
+	return nil
+}
+
+func (o *OrganizationRepositoryImpl) GetByID(id string) (*Organization, error) {
+	fmt.Println("Fetching organization by id in organization repository.")
+
+	// step 1: prepare the query
+	query := "SELECT id, name, slug, description, created_at, updated_at FROM organizations WHERE deleted_at IS NULL AND id = ?"
+
+	// step 2: execute the query
+	row := o.db.Raw(query, id).Row()
+
+	// step 3: process the result
+	organization := &Organization{}
+	err := row.Scan(&organization.ID, &organization.Name, &organization.Slug, &organization.Description, &organization.CreatedAt, &organization.UpdatedAt)
+	if err != nil {
+		fmt.Printf("Error fetching organization: %v\n", err)
+		return nil, err
+	}
+
+	// step 4: return the result
+	return organization, nil
+}
+
+func (o *OrganizationRepositoryImpl) GetBySlug(slug string) (*Organization, error) {
+	fmt.Println("Fetching organization by slug in organization repository.")
+
+	// step 1: prepare the query
+	query := "SELECT id, name, slug, description, created_at, updated_at FROM organizations WHERE deleted_at IS NULL AND slug = ?"
+
+	// step 2: execute the query
+	row := o.db.Raw(query, slug).Row()
+
+	// step 3: process the result
+	organization := &Organization{}
+	err := row.Scan(&organization.ID, &organization.Name, &organization.Slug, &organization.Description, &organization.CreatedAt, &organization.UpdatedAt)
+	if err != nil {
+		fmt.Printf("Error fetching organization: %v\n", err)
+		return nil, err
+	}
+
+	// step 4: return the result
+	return organization, nil
+}
+
+func (o *OrganizationRepositoryImpl) GetAll() ([]*Organization, error) {
+	fmt.Println("Fetching all organizations in organization repository.")
+
+	// step 1: prepare the query
+	query := "SELECT id, name, slug, description, created_at, updated_at FROM organizations WHERE deleted_at IS NULL ORDER BY id"
+
+	// step 2: execute the query
+	rows, err := o.db.Raw(query).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	return scanOrganizations(rows)
+}
+
+func (o *OrganizationRepositoryImpl) Update(id string, name *string, slug *string, description *string) (string, error) {
+	fmt.Println("updating organization in organization repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE organizations SET "
+	args := []interface{}{}
+	if name != nil {
+		query += "name = ?, "
+		args = append(args, *name)
+	}
+	if slug != nil {
+		query += "slug = ?, "
+		args = append(args, *slug)
+	}
+	if description != nil {
+		query += "description = ?, "
+		args = append(args, *description)
+	}
+
+	query += "updated_at = NOW() "
+	query += "WHERE deleted_at IS NULL AND id = ?"
+	args = append(args, id)
+
+	// step 2: execute the query
+	result := o.db.Exec(query, args...)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(result.Error, &pgErr) && pgErr.Code == "23505" {
+			return "", fmt.Errorf("unique constraint violation")
+		}
+		fmt.Printf("Error updating organization: %v\n", result.Error)
+		return "", result.Error
+	}
+
+	// step 4: evaluate the result
+	if result.RowsAffected == 0 {
+		fmt.Println("No organization was updated.")
+		return "", fmt.Errorf("No organization was updated.")
+	}
+
+	// step 5: return the result
+	return fmt.Sprintf("Organization updated successfully (rows affected: %d)", result.RowsAffected), nil
+}
+
+func (o *OrganizationRepositoryImpl) SoftDelete(id string) (string, error) {
+	fmt.Println("deleting organization in organization repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE organizations SET deleted_at = NOW() WHERE deleted_at IS NULL AND id = ?"
+
+	// step 2: execute the query
+	result := o.db.Exec(query, id)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error deleting organization: %v\n", result.Error)
+		return "", result.Error
+	}
+
+	// step 4: evaluate the result
+	if result.RowsAffected == 0 {
+		fmt.Println("No organization was deleted.")
+		return "", fmt.Errorf("No organization was deleted.")
+	}
+
+	// step 5: return the result
+	return fmt.Sprintf("Deleted organization (rows affected: %d)", result.RowsAffected), nil
+}
+
+func (o *OrganizationRepositoryImpl) HardDelete(id string) (string, error) {
+	fmt.Println("permanently deleting organization in organization repository.")
+
+	// step 1: prepare the query
+	query := "DELETE FROM organizations WHERE id = ?"
+
+	// step 2: execute the query
+	result := o.db.Exec(query, id)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(result.Error, &pgErr) && pgErr.Code == "23503" {
+			return "", fmt.Errorf("foreign key violation.")
+		}
+		fmt.Printf("Error deleting organization: %v\n", result.Error)
+		return "", result.Error
+	}
+
+	// step 4: evaluate the result
+	if result.RowsAffected == 0 {
+		fmt.Println("No organization was deleted.")
+		return "", fmt.Errorf("No organization was deleted.")
+	}
+
+	// step 5: return the result
+	return fmt.Sprintf("Deleted organization (rows affected: %d)", result.RowsAffected), nil
+}
+
+func scanOrganizations(rows *sql.Rows) ([]*Organization, error) {
+	organizations := []*Organization{}
+	for rows.Next() {
+		organization := &Organization{}
+		err := rows.Scan(&organization.ID, &organization.Name, &organization.Slug, &organization.Description, &organization.CreatedAt, &organization.UpdatedAt)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		organizations = append(organizations, organization)
+	}
+	return organizations, rows.Err()
+}
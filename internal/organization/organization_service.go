@@ -0,0 +1,158 @@
+package organization
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	ErrOrganizationNotFound  = errors.New("organization not found")
+	ErrOrganizationSlugTaken = errors.New("organization slug already exists")
+	ErrOrganizationInUse     = errors.New("organization still has roles or role assignments")
+	ErrInvalidSlug           = errors.New("slug must be lowercase letters, digits and dashes")
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type OrganizationService interface {
+	CreateOrganization(name string, slug string, description string) (*Organization, error)
+	GetOrganizationById(id string) (*Organization, error)
+	GetAllOrganizations() ([]*Organization, error)
+	UpdateOrganization(id string, name *string, slug *string, description *string) (string, error)
+	DeleteOrganization(id string) (string, error)
+	PermanentlyDeleteOrganization(id string) (string, error)
+}
+
+type OrganizationServiceImpl struct {
+	organizationRepository OrganizationRepository
+}
+
+func NewOrganizationService(_organizationRepository OrganizationRepository) OrganizationService {
+	return &OrganizationServiceImpl{
+		organizationRepository: _organizationRepository,
+	}
+}
+
+func (orgs *OrganizationServiceImpl) CreateOrganization(name string, slug string, description string) (*Organization, error) {
+	fmt.Println("Creating organization in organization service.")
+
+	if !slugPattern.MatchString(slug) {
+		return nil, ErrInvalidSlug
+	}
+	if err := orgs.ensureSlugAvailable(slug, ""); err != nil {
+		return nil, err
+	}
+
+	err := orgs.organizationRepository.Create(name, slug, description)
+	if err != nil {
+		fmt.Printf("Error creating organization: %v\n", err)
+		if err.Error() == "unique constraint violation" {
+			return nil, ErrOrganizationSlugTaken
+		}
+		return nil, err
+	}
+
+	organization, err := orgs.organizationRepository.GetBySlug(slug)
+	if err != nil {
+		fmt.Printf("Error fetching created organization: %v\n", err)
+		return nil, err
+	}
+	return organization, nil
+}
+
+func (orgs *OrganizationServiceImpl) GetOrganizationById(id string) (*Organization, error) {
+	fmt.Println("Getting organization by id in organization service.")
+	organization, err := orgs.organizationRepository.GetByID(id)
+	if err != nil {
+		fmt.Printf("Error fetching organization by id: %v\n", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return organization, nil
+}
+
+func (orgs *OrganizationServiceImpl) GetAllOrganizations() ([]*Organization, error) {
+	fmt.Println("Getting all organizations in organization service.")
+	organizations, err := orgs.organizationRepository.GetAll()
+	if err != nil {
+		fmt.Printf("Error fetching organizations: %v\n", err)
+		return nil, err
+	}
+	return organizations, nil
+}
+
+func (orgs *OrganizationServiceImpl) UpdateOrganization(id string, name *string, slug *string, description *string) (string, error) {
+	fmt.Println("Updating organization in organization service.")
+
+	if slug != nil {
+		if !slugPattern.MatchString(*slug) {
+			return "", ErrInvalidSlug
+		}
+		if err := orgs.ensureSlugAvailable(*slug, id); err != nil {
+			return "", err
+		}
+	}
+
+	message, err := orgs.organizationRepository.Update(id, name, slug, description)
+	if err != nil {
+		fmt.Printf("Error updating organization: %v\n", err)
+		switch err.Error() {
+		case "No organization was updated.":
+			return "", ErrOrganizationNotFound
+		case "unique constraint violation":
+			return "", ErrOrganizationSlugTaken
+		}
+		return "", err
+	}
+	return message, nil
+}
+
+func (orgs *OrganizationServiceImpl) DeleteOrganization(id string) (string, error) {
+	fmt.Println("Deleting organization in organization service.")
+
+	message, err := orgs.organizationRepository.SoftDelete(id)
+	if err != nil {
+		fmt.Printf("Error deleting organization: %v\n", err)
+		if err.Error() == "No organization was deleted." {
+			return "", ErrOrganizationNotFound
+		}
+		return "", err
+	}
+	return message, nil
+}
+
+func (orgs *OrganizationServiceImpl) PermanentlyDeleteOrganization(id string) (string, error) {
+	fmt.Println("Permanently deleting organization in organization service.")
+
+	message, err := orgs.organizationRepository.HardDelete(id)
+	if err != nil {
+		fmt.Printf("Error permanently deleting organization: %v\n", err)
+		switch err.Error() {
+		case "No organization was deleted.":
+			return "", ErrOrganizationNotFound
+		case "foreign key violation.":
+			return "", ErrOrganizationInUse
+		}
+		return "", err
+	}
+	return message, nil
+}
+
+// ensureSlugAvailable fails when a live organization other than exceptId already uses the slug.
+func (orgs *OrganizationServiceImpl) ensureSlugAvailable(slug string, exceptId string) error {
+	existing, err := orgs.organizationRepository.GetBySlug(slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if fmt.Sprint(existing.ID) == exceptId {
+		return nil
+	}
+	return ErrOrganizationSlugTaken
+}
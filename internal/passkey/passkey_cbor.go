@@ -0,0 +1,172 @@
+package passkey
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var errMalformedCbor = errors.New("malformed CBOR")
+
+// maxCborDepth bounds the nesting of the CBOR authenticators send; theirs is shallow.
+const maxCborDepth = 8
+
+// decodeCbor decodes the first CBOR data item in data, the subset WebAuthn uses:
+// integers, byte and text strings, arrays, maps and simple values. It returns the
+// item and the number of bytes it took, since the credential public key is
+// followed by extensions in the authenticator data. Integers decode to int64, maps
+// to map[interface{}]interface{} keyed by int64 or string.
+func decodeCbor(data []byte) (interface{}, int, error) {
+	d := &cborDecoder{data: data}
+	item, err := d.decode(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return item, d.offset, nil
+}
+
+type cborDecoder struct {
+	data   []byte
+	offset int
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxCborDepth {
+		return nil, errMalformedCbor
+	}
+	if d.offset >= len(d.data) {
+		return nil, errMalformedCbor
+	}
+	initial := d.data[d.offset]
+	d.offset++
+	major, info := initial>>5, initial&0x1f
+
+	// simple values and floats carry no length
+	if major == 7 {
+		return d.simple(info)
+	}
+	argument, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		if argument > math.MaxInt64 {
+			return nil, errMalformedCbor
+		}
+		return int64(argument), nil
+	case 1:
+		if argument > math.MaxInt64 {
+			return nil, errMalformedCbor
+		}
+		return -1 - int64(argument), nil
+	case 2, 3:
+		raw, err := d.take(argument)
+		if err != nil {
+			return nil, err
+		}
+		if major == 3 {
+			return string(raw), nil
+		}
+		return append([]byte(nil), raw...), nil
+	case 4:
+		if argument > uint64(len(d.data)) {
+			return nil, errMalformedCbor
+		}
+		items := make([]interface{}, 0, argument)
+		for i := uint64(0); i < argument; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case 5:
+		if argument > uint64(len(d.data)) {
+			return nil, errMalformedCbor
+		}
+		entries := make(map[interface{}]interface{}, argument)
+		for i := uint64(0); i < argument; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, errMalformedCbor
+			}
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			entries[key] = value
+		}
+		return entries, nil
+	}
+	// tags and indefinite lengths do not occur in WebAuthn structures
+	return nil, errMalformedCbor
+}
+
+// argument reads the length or value that follows the initial byte.
+func (d *cborDecoder) argument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		raw, err := d.take(1)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(raw[0]), nil
+	case info == 25:
+		raw, err := d.take(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(raw)), nil
+	case info == 26:
+		raw, err := d.take(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(raw)), nil
+	case info == 27:
+		raw, err := d.take(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(raw), nil
+	}
+	return 0, errMalformedCbor
+}
+
+func (d *cborDecoder) simple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25, 26, 27:
+		// floats are skipped over; nothing WebAuthn verifies is a float
+		size := map[byte]uint64{25: 2, 26: 4, 27: 8}[info]
+		if _, err := d.take(size); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return nil, errMalformedCbor
+}
+
+func (d *cborDecoder) take(size uint64) ([]byte, error) {
+	if size > uint64(len(d.data)-d.offset) {
+		return nil, errMalformedCbor
+	}
+	raw := d.data[d.offset : d.offset+int(size)]
+	d.offset += int(size)
+	return raw, nil
+}
@@ -0,0 +1,147 @@
+package passkey
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// cborHead encodes the initial byte and argument of a CBOR item.
+func cborHead(major byte, argument uint64) []byte {
+	switch {
+	case argument < 24:
+		return []byte{major<<5 | byte(argument)}
+	case argument <= 0xff:
+		return []byte{major<<5 | 24, byte(argument)}
+	case argument <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(argument))
+	case argument <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(argument))
+	}
+	return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, argument)
+}
+
+// cborEncode encodes the values decodeCbor decodes: int64, int, []byte, string,
+// bool and map[interface{}]interface{} with keys in the order of keys.
+func cborEncode(value interface{}, keys ...interface{}) []byte {
+	switch v := value.(type) {
+	case int:
+		return cborEncode(int64(v))
+	case int64:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	case bool:
+		if v {
+			return []byte{0xf5}
+		}
+		return []byte{0xf4}
+	case map[interface{}]interface{}:
+		out := cborHead(5, uint64(len(v)))
+		for _, key := range keys {
+			out = append(out, cborEncode(key)...)
+			out = append(out, cborEncode(v[key])...)
+		}
+		return out
+	}
+	panic("cborEncode: unsupported value")
+}
+
+func TestDecodeCbor(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want interface{}
+	}{
+		{"small integer", []byte{0x17}, int64(23)},
+		{"one byte integer", []byte{0x18, 0xff}, int64(255)},
+		{"four byte integer", []byte{0x1a, 0x00, 0x01, 0x00, 0x00}, int64(65536)},
+		{"negative integer", []byte{0x26}, int64(-7)},
+		{"two byte negative integer", []byte{0x39, 0x01, 0x00}, int64(-257)},
+		{"byte string", []byte{0x43, 1, 2, 3}, []byte{1, 2, 3}},
+		{"text string", []byte{0x64, 'n', 'o', 'n', 'e'}, "none"},
+		{"array", []byte{0x82, 0x01, 0x61, 'a'}, []interface{}{int64(1), "a"}},
+		{"map", []byte{0xa2, 0x01, 0x02, 0x61, 'k', 0xf5}, map[interface{}]interface{}{int64(1): int64(2), "k": true}},
+		{"false", []byte{0xf4}, false},
+		{"null", []byte{0xf6}, nil},
+		{"half float is skipped", []byte{0xf9, 0x3c, 0x00}, nil},
+	}
+	for _, tt := range tests {
+		item, size, err := decodeCbor(tt.data)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(item, tt.want) || size != len(tt.data) {
+			t.Errorf("%s: decodeCbor = %#v, %d, want %#v, %d", tt.name, item, size, tt.want, len(tt.data))
+		}
+	}
+}
+
+func TestDecodeCborStopsAfterFirstItem(t *testing.T) {
+	// the credential public key is followed by the extensions in authenticator data
+	key := cborEncode(map[interface{}]interface{}{int64(1): int64(2)}, int64(1))
+	data := append(bytes.Clone(key), 0xa0)
+	_, size, err := decodeCbor(data)
+	if err != nil || size != len(key) {
+		t.Errorf("decodeCbor = %d, %v, want %d", size, err, len(key))
+	}
+}
+
+func TestDecodeCborRejectsTruncated(t *testing.T) {
+	object := cborEncode(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": bytes.Repeat([]byte{0xab}, 300),
+	}, "fmt", "attStmt", "authData")
+	for end := 0; end < len(object); end++ {
+		if _, _, err := decodeCbor(object[:end]); !errors.Is(err, errMalformedCbor) {
+			t.Fatalf("decodeCbor of %d of %d bytes = %v, want errMalformedCbor", end, len(object), err)
+		}
+	}
+	if _, _, err := decodeCbor(object); err != nil {
+		t.Fatalf("decodeCbor of the whole object: %v", err)
+	}
+}
+
+func TestDecodeCborRejectsOversized(t *testing.T) {
+	nested := append(bytes.Repeat([]byte{0x81}, maxCborDepth+1), 0x01)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"byte string longer than the data", append(cborHead(2, 1<<32), 1, 2, 3)},
+		{"byte string of 2^64-1 bytes", append(cborHead(2, 1<<64-1), 1, 2, 3)},
+		{"text string longer than the data", append(cborHead(3, 10), 'a')},
+		{"array with more items than bytes", append(cborHead(4, 1<<62), 0x01)},
+		{"map with more entries than bytes", append(cborHead(5, 1<<40), 0x01, 0x01)},
+		{"integer above int64", cborHead(0, 1<<63)},
+		{"negative integer below int64", cborHead(1, 1<<63)},
+		{"nested deeper than the limit", nested},
+		{"indefinite length byte string", []byte{0x5f, 0x41, 0x01, 0xff}},
+		{"indefinite length array", []byte{0x9f, 0x01, 0xff}},
+		{"tag", []byte{0xc0, 0x61, 'a'}},
+		{"reserved argument", []byte{0x1c}},
+		{"reserved simple value", []byte{0xf0}},
+		{"array as a map key", []byte{0xa1, 0x80, 0x01}},
+		{"empty", []byte{}},
+	}
+	for _, tt := range tests {
+		if _, _, err := decodeCbor(tt.data); !errors.Is(err, errMalformedCbor) {
+			t.Errorf("%s: decodeCbor = %v, want errMalformedCbor", tt.name, err)
+		}
+	}
+
+	// nesting up to the limit is fine
+	allowed := append(bytes.Repeat([]byte{0x81}, maxCborDepth), 0x01)
+	if _, _, err := decodeCbor(allowed); err != nil {
+		t.Errorf("decodeCbor nested %d deep: %v", maxCborDepth, err)
+	}
+}
@@ -0,0 +1,132 @@
+package passkey
+
+import (
+	"errors"
+	"fmt"
+	"go_project_structure/internal/token"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type PasskeyController struct {
+	PasskeyService PasskeyService
+}
+
+func NewPasskeyController(_passkeyService PasskeyService) *PasskeyController {
+	return &PasskeyController{
+		PasskeyService: _passkeyService,
+	}
+}
+
+func NewPasskeyView(passkey *PasskeyCredential) *PasskeyView {
+	return &PasskeyView{
+		ID:         passkey.ID,
+		Name:       passkey.Name,
+		Transports: strings.Fields(passkey.Transports),
+		CreatedAt:  passkey.CreatedAt,
+		LastUsedAt: passkey.LastUsedAt,
+	}
+}
+
+func (pc *PasskeyController) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	principal, ok := passkeyPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	options, err := pc.PasskeyService.BeginRegistration(principal.UserID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Passkey registration failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Pass the options to navigator.credentials.create", &CreationOptionsResponse{PublicKey: options})
+}
+
+func (pc *PasskeyController) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	principal, ok := passkeyPrincipal(w, r)
+	if !ok {
+		return
+	}
+	requestPayload := r.Context().Value("register_passkey_payload").(RegisterPasskeyRequest)
+
+	passkey, err := pc.PasskeyService.FinishRegistration(principal.UserID, requestPayload.Name, requestPayload.Credential)
+	if err != nil {
+		status := StatusFor(err)
+		// the caller is logged in: a bad registration is a bad request, not a failed login
+		if status == http.StatusUnauthorized {
+			status = http.StatusBadRequest
+		}
+		utils.WriteJsonErrorResponse(w, status, "Passkey registration failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusCreated, "Passkey registered successfully", NewPasskeyView(passkey))
+}
+
+func (pc *PasskeyController) GetPasskeys(w http.ResponseWriter, r *http.Request) {
+	principal, ok := passkeyPrincipal(w, r)
+	if !ok {
+		return
+	}
+
+	passkeys, err := pc.PasskeyService.GetPasskeys(principal.UserID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Passkeys fetch failed.", err)
+		return
+	}
+	views := []*PasskeyView{}
+	for _, passkey := range passkeys {
+		views = append(views, NewPasskeyView(passkey))
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get passkeys end point", views)
+}
+
+func (pc *PasskeyController) DeletePasskey(w http.ResponseWriter, r *http.Request) {
+	principal, ok := passkeyPrincipal(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid passkey id", fmt.Errorf("invalid id"))
+		return
+	}
+
+	err = pc.PasskeyService.DeletePasskey(id, principal.UserID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, StatusFor(err), "Passkey delete failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Passkey deleted successfully", nil)
+}
+
+// passkeyPrincipal returns the user managing their own passkeys. Neither API keys
+// nor service accounts can have one, so they are turned away.
+func passkeyPrincipal(w http.ResponseWriter, r *http.Request) (*token.Principal, bool) {
+	principal, ok := token.PrincipalFromContext(r.Context())
+	if !ok {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", errors.New("missing principal"))
+		return nil, false
+	}
+	if principal.ApiKeyID != 0 || principal.IsServiceAccount() {
+		utils.WriteJsonErrorResponse(w, http.StatusForbidden, "Passkeys are managed by users on their own session", errors.New("API keys and service accounts cannot have passkeys"))
+		return nil, false
+	}
+	return principal, true
+}
+
+// StatusFor maps the errors of the passkey ceremonies to a response status.
+func StatusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrCredentialInvalid), errors.Is(err, ErrCeremonyInvalid):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrPasskeyExists):
+		return http.StatusConflict
+	case errors.Is(err, ErrPasskeyNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,44 @@
+package passkey
+
+import (
+	"context"
+	"fmt"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strings"
+)
+
+func RegisterPasskeyRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = RegisterPasskeyRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("register passkey payload received.")
+
+		RequestPayload.Name = strings.TrimSpace(RequestPayload.Name)
+		if RequestPayload.Name == "" || len(RequestPayload.Name) > 255 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("name is required and must be at most 255 characters"))
+			return
+		}
+		credential := RequestPayload.Credential
+		if credential.RawID == "" || credential.Response.ClientDataJSON == "" || credential.Response.AttestationObject == "" {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("credential must be the registration response of the authenticator"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "register_passkey_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ValidAssertion reports whether the credential looks like the response of an
+// authenticator to a login; FinishAssertion verifies it.
+func ValidAssertion(credential *AssertionCredential) bool {
+	return credential != nil && credential.RawID != "" && credential.Response.ClientDataJSON != "" &&
+		credential.Response.AuthenticatorData != "" && credential.Response.Signature != ""
+}
@@ -0,0 +1,39 @@
+package passkey
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PasskeyCredential is a passkey a user registered. CredentialID is the base64url ID
+// the authenticator knows it by and PublicKey its COSE encoded public key. UserHandle
+// is the base64url handle of the user the authenticator stores with the passkey;
+// every passkey of a user shares it. SignCount is the last signature counter the
+// authenticator reported, which must only grow.
+type PasskeyCredential struct {
+	gorm.Model
+	UserID       uint   `gorm:"not null;index"`
+	Name         string `gorm:"size:255;not null"`
+	CredentialID string `gorm:"size:1400;not null;uniqueIndex"`
+	UserHandle   string `gorm:"size:128;not null"`
+	PublicKey    []byte `gorm:"not null"`
+	SignCount    int64  `gorm:"not null;default:0"`
+	// Transports are the transports the authenticator said it is reachable over, space separated
+	Transports string `gorm:"size:255;not null;default:''"`
+	LastUsedAt *time.Time
+}
+
+// PasskeyCeremony is a registration or login waiting for the authenticator. Only the
+// SHA-256 hash of its challenge is stored; the challenge comes back inside the
+// client data and is accepted once, before it expires. UserID is the user the
+// ceremony is for, unset for a passwordless login where the passkey tells.
+type PasskeyCeremony struct {
+	gorm.Model
+	ChallengeHash string    `gorm:"size:64;not null;uniqueIndex"`
+	Purpose       string    `gorm:"size:32;not null"`
+	UserID        *uint     `gorm:"index"`
+	UserHandle    string    `gorm:"size:128;not null;default:''"`
+	ExpiresAt     time.Time `gorm:"not null"`
+	UsedAt        *time.Time
+}
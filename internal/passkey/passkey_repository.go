@@ -0,0 +1,218 @@
+package passkey
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasskeyRepository interface {
+	GetCredentials(userId int64) ([]*PasskeyCredential, error)
+	GetByCredentialID(credentialId string) (*PasskeyCredential, error)
+	CountCredentials(userId int64) (int64, error)
+	GetUserHandle(userId int64) (string, error)
+	Create(credential *PasskeyCredential) error
+	UseCredential(id uint, signCount int64) (bool, error)
+	Delete(id int64, userId int64) error
+
+	CreateCeremony(challengeHash string, purpose string, userId int64, userHandle string, expiresAt time.Time) error
+	ConsumeCeremony(challengeHash string, purpose string) (*PasskeyCeremony, error)
+}
+
+type PasskeyRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewPasskeyRepository(_db *gorm.DB) PasskeyRepository {
+	return &PasskeyRepositoryImpl{
+		db: _db,
+	}
+}
+
+const passkeyColumns = "id, user_id, name, credential_id, user_handle, public_key, sign_count, transports, last_used_at, created_at, updated_at"
+
+func (u *PasskeyRepositoryImpl) GetCredentials(userId int64) ([]*PasskeyCredential, error) {
+	// step 1: prepare the query
+	query := "SELECT " + passkeyColumns + " FROM passkey_credentials WHERE deleted_at IS NULL AND user_id = ? ORDER BY id"
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query, userId).Rows()
+	if err != nil {
+		fmt.Printf("Error fetching passkeys: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	credentials := []*PasskeyCredential{}
+	for rows.Next() {
+		credential := &PasskeyCredential{}
+		err := rows.Scan(&credential.ID, &credential.UserID, &credential.Name, &credential.CredentialID, &credential.UserHandle,
+			&credential.PublicKey, &credential.SignCount, &credential.Transports, &credential.LastUsedAt, &credential.CreatedAt, &credential.UpdatedAt)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		credentials = append(credentials, credential)
+	}
+
+	// step 4: return the result
+	return credentials, rows.Err()
+}
+
+func (u *PasskeyRepositoryImpl) GetByCredentialID(credentialId string) (*PasskeyCredential, error) {
+	// step 1: prepare the query
+	query := "SELECT " + passkeyColumns + " FROM passkey_credentials WHERE deleted_at IS NULL AND credential_id = ?"
+
+	// step 2: execute the query
+	row := u.db.Raw(query, credentialId).Row()
+
+	// step 3: process the result
+	return scanPasskey(row)
+}
+
+func (u *PasskeyRepositoryImpl) CountCredentials(userId int64) (int64, error) {
+	// step 1: prepare the query
+	query := "SELECT COUNT(*) FROM passkey_credentials WHERE deleted_at IS NULL AND user_id = ?"
+
+	// step 2: execute the query
+	var count int64
+	err := u.db.Raw(query, userId).Row().Scan(&count)
+
+	// step 3: check for errors
+	if err != nil {
+		fmt.Printf("Error counting passkeys: %v\n", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetUserHandle returns the handle the passkeys of the user were registered with,
+// deleted ones included so that it stays the same. It returns sql.ErrNoRows for a
+// user who never registered one.
+func (u *PasskeyRepositoryImpl) GetUserHandle(userId int64) (string, error) {
+	// step 1: prepare the query
+	query := "SELECT user_handle FROM passkey_credentials WHERE user_id = ? ORDER BY id LIMIT 1"
+
+	// step 2: execute the query
+	var userHandle string
+	err := u.db.Raw(query, userId).Row().Scan(&userHandle)
+
+	// step 3: return the result
+	return userHandle, err
+}
+
+func (u *PasskeyRepositoryImpl) Create(credential *PasskeyCredential) error {
+	fmt.Println("Creating passkey in passkey repository.")
+
+	// step 1: prepare the query
+	query := `INSERT INTO passkey_credentials (user_id, name, credential_id, user_handle, public_key, sign_count, transports)
+		VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id, created_at, updated_at`
+
+	// step 2: execute the query
+	row := u.db.Raw(query, credential.UserID, credential.Name, credential.CredentialID, credential.UserHandle,
+		credential.PublicKey, credential.SignCount, credential.Transports).Row()
+
+	// step 3: check for errors
+	if err := row.Scan(&credential.ID, &credential.CreatedAt, &credential.UpdatedAt); err != nil {
+		fmt.Printf("Error creating passkey: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// UseCredential records a login with the passkey and the signature counter it
+// reported. It reports false when the counter did not grow, which means the passkey
+// was cloned, unless the authenticator does not keep a counter at all.
+func (u *PasskeyRepositoryImpl) UseCredential(id uint, signCount int64) (bool, error) {
+	// step 1: prepare the query
+	query := `UPDATE passkey_credentials SET sign_count = ?, last_used_at = NOW(), updated_at = NOW()
+		WHERE deleted_at IS NULL AND id = ? AND ((sign_count = 0 AND ? = 0) OR sign_count < ?)`
+
+	// step 2: execute the query
+	result := u.db.Exec(query, signCount, id, signCount, signCount)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error using passkey: %v\n", result.Error)
+		return false, result.Error
+	}
+
+	// step 4: return the result
+	return result.RowsAffected == 1, nil
+}
+
+// Delete removes a passkey of the user. It returns sql.ErrNoRows when the user has
+// no such passkey.
+func (u *PasskeyRepositoryImpl) Delete(id int64, userId int64) error {
+	fmt.Println("Deleting passkey in passkey repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE passkey_credentials SET deleted_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND id = ? AND user_id = ?"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, id, userId)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error deleting passkey: %v\n", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (u *PasskeyRepositoryImpl) CreateCeremony(challengeHash string, purpose string, userId int64, userHandle string, expiresAt time.Time) error {
+	// step 1: prepare the query
+	query := "INSERT INTO passkey_ceremonies (challenge_hash, purpose, user_id, user_handle, expires_at) VALUES (?, ?, ?, ?, ?)"
+
+	// step 2: execute the query
+	var user interface{}
+	if userId != 0 {
+		user = userId
+	}
+	result := u.db.Exec(query, challengeHash, purpose, user, userHandle, expiresAt)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error creating passkey ceremony: %v\n", result.Error)
+		return result.Error
+	}
+	return nil
+}
+
+// ConsumeCeremony closes the open ceremony of the challenge and returns it. It
+// returns sql.ErrNoRows for a challenge that is unknown, used, expired or issued
+// for another purpose.
+func (u *PasskeyRepositoryImpl) ConsumeCeremony(challengeHash string, purpose string) (*PasskeyCeremony, error) {
+	// step 1: prepare the query
+	query := `UPDATE passkey_ceremonies SET used_at = NOW(), updated_at = NOW()
+		WHERE deleted_at IS NULL AND challenge_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > NOW()
+		RETURNING id, challenge_hash, purpose, user_id, user_handle, expires_at, used_at, created_at, updated_at`
+
+	// step 2: execute the query
+	row := u.db.Raw(query, challengeHash, purpose).Row()
+
+	// step 3: process the result
+	ceremony := &PasskeyCeremony{}
+	err := row.Scan(&ceremony.ID, &ceremony.ChallengeHash, &ceremony.Purpose, &ceremony.UserID, &ceremony.UserHandle,
+		&ceremony.ExpiresAt, &ceremony.UsedAt, &ceremony.CreatedAt, &ceremony.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return ceremony, nil
+}
+
+func scanPasskey(row *sql.Row) (*PasskeyCredential, error) {
+	credential := &PasskeyCredential{}
+	err := row.Scan(&credential.ID, &credential.UserID, &credential.Name, &credential.CredentialID, &credential.UserHandle,
+		&credential.PublicKey, &credential.SignCount, &credential.Transports, &credential.LastUsedAt, &credential.CreatedAt, &credential.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
@@ -0,0 +1,364 @@
+package passkey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	env "go_project_structure/config/env"
+	"go_project_structure/internal/events"
+	"go_project_structure/internal/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCeremonyInvalid = errors.New("passkey challenge is invalid or has expired")
+	ErrPasskeyExists   = errors.New("this passkey is already registered")
+	ErrPasskeyNotFound = errors.New("passkey not found")
+)
+
+// Ceremony purposes: a registration, a passwordless login, and a login that uses the
+// passkey as its second factor.
+const (
+	purposeRegistration = "registration"
+	purposeLogin        = "login"
+	purposeSecondFactor = "second_factor"
+)
+
+// Events published about passkeys.
+const (
+	RegisteredEvent = "passkey.registered"
+	DeletedEvent    = "passkey.deleted"
+)
+
+type PasskeyService interface {
+	BeginRegistration(userId int64) (*CreationOptions, error)
+	FinishRegistration(userId int64, name string, credential RegistrationCredential) (*PasskeyCredential, error)
+	GetPasskeys(userId int64) ([]*PasskeyCredential, error)
+	HasPasskeys(userId int64) (bool, error)
+	DeletePasskey(id int64, userId int64) error
+
+	BeginAssertion(userId int64) (*RequestOptions, error)
+	FinishAssertion(credential AssertionCredential, userId int64) (int64, error)
+}
+
+type PasskeyServiceImpl struct {
+	passkeyRepository PasskeyRepository
+	userService       user.UserService
+	bus               *events.Bus
+}
+
+func NewPasskeyService(_passkeyRepository PasskeyRepository, _userService user.UserService, _bus *events.Bus) PasskeyService {
+	return &PasskeyServiceImpl{
+		passkeyRepository: _passkeyRepository,
+		userService:       _userService,
+		bus:               _bus,
+	}
+}
+
+// ceremonyTTL is how long a ceremony waits for the authenticator.
+func ceremonyTTL() time.Duration {
+	return time.Duration(env.GetInt("WEBAUTHN_CEREMONY_TTL_SECONDS", 300)) * time.Second
+}
+
+// BeginRegistration starts the registration of a passkey for the user. The passkey
+// is discoverable, so that it can log the user in without their email, and must
+// verify the user, so that it counts as two factors on its own.
+func (ps *PasskeyServiceImpl) BeginRegistration(userId int64) (*CreationOptions, error) {
+	fmt.Println("Beginning passkey registration in passkey service.")
+	registering, err := ps.userService.GetUserById(strconv.FormatInt(userId, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	userHandle, err := ps.passkeyRepository.GetUserHandle(userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		userHandle, err = randomToken(32)
+	}
+	if err != nil {
+		return nil, err
+	}
+	existing, err := ps.passkeyRepository.GetCredentials(userId)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := ps.startCeremony(purposeRegistration, userId, userHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	params := []CredentialParameter{}
+	for _, alg := range supportedAlgorithms {
+		params = append(params, CredentialParameter{Type: "public-key", Alg: alg})
+	}
+	return &CreationOptions{
+		Rp:                 RelyingParty{ID: RpID(), Name: rpName()},
+		User:               UserEntity{ID: userHandle, Name: registering.Email, DisplayName: registering.Name},
+		Challenge:          challenge,
+		PubKeyCredParams:   params,
+		Timeout:            ceremonyTTL().Milliseconds(),
+		ExcludeCredentials: descriptors(existing),
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   "required",
+		},
+		Attestation: "none",
+	}, nil
+}
+
+// FinishRegistration verifies the response of the authenticator to a registration
+// the user began and stores the passkey.
+func (ps *PasskeyServiceImpl) FinishRegistration(userId int64, name string, credential RegistrationCredential) (*PasskeyCredential, error) {
+	fmt.Println("Finishing passkey registration in passkey service.")
+	clientDataJSON, err := decodeBase64URL(credential.Response.ClientDataJSON)
+	if err != nil || credential.Type != "public-key" {
+		return nil, ErrCredentialInvalid
+	}
+	clientData, err := parseClientData(clientDataJSON, "webauthn.create")
+	if err != nil {
+		return nil, err
+	}
+	ceremony, err := ps.consumeCeremony(clientData.Challenge, purposeRegistration)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony.UserID == nil || int64(*ceremony.UserID) != userId {
+		return nil, ErrCeremonyInvalid
+	}
+
+	attestationObject, err := decodeBase64URL(credential.Response.AttestationObject)
+	if err != nil {
+		return nil, ErrCredentialInvalid
+	}
+	rawAuthData, err := parseAttestationObject(attestationObject)
+	if err != nil {
+		return nil, err
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := authData.check(true); err != nil {
+		return nil, err
+	}
+	if authData.CredentialID == nil {
+		return nil, fmt.Errorf("%w: registration has no credential", ErrCredentialInvalid)
+	}
+	rawId, err := decodeBase64URL(credential.RawID)
+	if err != nil || string(rawId) != string(authData.CredentialID) {
+		return nil, fmt.Errorf("%w: credential ID does not match", ErrCredentialInvalid)
+	}
+	if _, _, err := parsePublicKey(authData.PublicKey); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCredentialInvalid, err)
+	}
+
+	credentialId := base64.RawURLEncoding.EncodeToString(authData.CredentialID)
+	if _, err := ps.passkeyRepository.GetByCredentialID(credentialId); err == nil {
+		return nil, ErrPasskeyExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	passkey := &PasskeyCredential{
+		UserID:       uint(userId),
+		Name:         name,
+		CredentialID: credentialId,
+		UserHandle:   ceremony.UserHandle,
+		PublicKey:    authData.PublicKey,
+		SignCount:    int64(authData.SignCount),
+		Transports:   strings.Join(credential.Response.Transports, " "),
+	}
+	if err := ps.passkeyRepository.Create(passkey); err != nil {
+		return nil, err
+	}
+	ps.bus.Publish(events.Event{Name: RegisteredEvent, Data: map[string]interface{}{
+		"user_id":    userId,
+		"passkey_id": passkey.ID,
+	}})
+	return passkey, nil
+}
+
+func (ps *PasskeyServiceImpl) GetPasskeys(userId int64) ([]*PasskeyCredential, error) {
+	fmt.Println("Fetching passkeys in passkey service.")
+	return ps.passkeyRepository.GetCredentials(userId)
+}
+
+func (ps *PasskeyServiceImpl) HasPasskeys(userId int64) (bool, error) {
+	count, err := ps.passkeyRepository.CountCredentials(userId)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (ps *PasskeyServiceImpl) DeletePasskey(id int64, userId int64) error {
+	fmt.Println("Deleting passkey in passkey service.")
+	err := ps.passkeyRepository.Delete(id, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPasskeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	ps.bus.Publish(events.Event{Name: DeletedEvent, Data: map[string]interface{}{
+		"user_id":    userId,
+		"passkey_id": id,
+	}})
+	return nil
+}
+
+// BeginAssertion starts a login with a passkey. Without a user it is a passwordless
+// login with any passkey, which must verify the user; with one it is the second
+// factor of that user's login, with one of their passkeys.
+func (ps *PasskeyServiceImpl) BeginAssertion(userId int64) (*RequestOptions, error) {
+	fmt.Println("Beginning passkey assertion in passkey service.")
+	purpose, userVerification := purposeLogin, "required"
+	allowed := []CredentialDescriptor{}
+	if userId != 0 {
+		purpose, userVerification = purposeSecondFactor, "preferred"
+		existing, err := ps.passkeyRepository.GetCredentials(userId)
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) == 0 {
+			return nil, ErrPasskeyNotFound
+		}
+		allowed = descriptors(existing)
+	}
+
+	challenge, err := ps.startCeremony(purpose, userId, "")
+	if err != nil {
+		return nil, err
+	}
+	return &RequestOptions{
+		Challenge:        challenge,
+		Timeout:          ceremonyTTL().Milliseconds(),
+		RpID:             RpID(),
+		AllowCredentials: allowed,
+		UserVerification: userVerification,
+	}, nil
+}
+
+// FinishAssertion verifies the response of the authenticator to a login begun by
+// BeginAssertion for the same user, or for none, and returns the user the passkey
+// belongs to.
+func (ps *PasskeyServiceImpl) FinishAssertion(credential AssertionCredential, userId int64) (int64, error) {
+	fmt.Println("Finishing passkey assertion in passkey service.")
+	clientDataJSON, err := decodeBase64URL(credential.Response.ClientDataJSON)
+	if err != nil || credential.Type != "public-key" {
+		return 0, ErrCredentialInvalid
+	}
+	clientData, err := parseClientData(clientDataJSON, "webauthn.get")
+	if err != nil {
+		return 0, err
+	}
+	purpose := purposeLogin
+	if userId != 0 {
+		purpose = purposeSecondFactor
+	}
+	ceremony, err := ps.consumeCeremony(clientData.Challenge, purpose)
+	if err != nil {
+		return 0, err
+	}
+	if userId != 0 && (ceremony.UserID == nil || int64(*ceremony.UserID) != userId) {
+		return 0, ErrCeremonyInvalid
+	}
+
+	rawId, err := decodeBase64URL(credential.RawID)
+	if err != nil {
+		return 0, ErrCredentialInvalid
+	}
+	passkey, err := ps.passkeyRepository.GetByCredentialID(base64.RawURLEncoding.EncodeToString(rawId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: unknown passkey", ErrCredentialInvalid)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if userId != 0 && int64(passkey.UserID) != userId {
+		return 0, fmt.Errorf("%w: passkey belongs to another user", ErrCredentialInvalid)
+	}
+	if credential.Response.UserHandle != "" && strings.TrimRight(credential.Response.UserHandle, "=") != passkey.UserHandle {
+		return 0, fmt.Errorf("%w: user handle does not match", ErrCredentialInvalid)
+	}
+
+	rawAuthData, err := decodeBase64URL(credential.Response.AuthenticatorData)
+	if err != nil {
+		return 0, ErrCredentialInvalid
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+	if err := authData.check(userId == 0); err != nil {
+		return 0, err
+	}
+	signature, err := decodeBase64URL(credential.Response.Signature)
+	if err != nil {
+		return 0, ErrCredentialInvalid
+	}
+	if err := verifyAssertionSignature(passkey.PublicKey, rawAuthData, clientDataJSON, signature); err != nil {
+		return 0, err
+	}
+
+	fresh, err := ps.passkeyRepository.UseCredential(passkey.ID, int64(authData.SignCount))
+	if err != nil {
+		return 0, err
+	}
+	if !fresh {
+		fmt.Printf("Passkey %d reported a stale signature counter\n", passkey.ID)
+		return 0, fmt.Errorf("%w: signature counter did not increase; the passkey may have been cloned", ErrCredentialInvalid)
+	}
+	return int64(passkey.UserID), nil
+}
+
+// startCeremony stores a new challenge and returns it.
+func (ps *PasskeyServiceImpl) startCeremony(purpose string, userId int64, userHandle string) (string, error) {
+	challenge, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	if err := ps.passkeyRepository.CreateCeremony(hashToken(challenge), purpose, userId, userHandle, time.Now().Add(ceremonyTTL())); err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+func (ps *PasskeyServiceImpl) consumeCeremony(challenge string, purpose string) (*PasskeyCeremony, error) {
+	ceremony, err := ps.passkeyRepository.ConsumeCeremony(hashToken(strings.TrimRight(challenge, "=")), purpose)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCeremonyInvalid
+	}
+	return ceremony, err
+}
+
+func descriptors(passkeys []*PasskeyCredential) []CredentialDescriptor {
+	list := []CredentialDescriptor{}
+	for _, passkey := range passkeys {
+		list = append(list, CredentialDescriptor{
+			Type:       "public-key",
+			ID:         passkey.CredentialID,
+			Transports: strings.Fields(passkey.Transports),
+		})
+	}
+	return list
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
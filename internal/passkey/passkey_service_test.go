@@ -0,0 +1,215 @@
+package passkey
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"go_project_structure/internal/events"
+)
+
+// fakePasskeyRepository keeps ceremonies and passkeys in memory, with the rules of
+// the queries: a ceremony is consumed once, for its purpose, before it expires, and
+// a signature counter must grow unless the authenticator keeps none.
+type fakePasskeyRepository struct {
+	PasskeyRepository
+	ceremonies map[string]*PasskeyCeremony
+	passkeys   map[string]*PasskeyCredential
+}
+
+func newFakePasskeyRepository() *fakePasskeyRepository {
+	return &fakePasskeyRepository{ceremonies: map[string]*PasskeyCeremony{}, passkeys: map[string]*PasskeyCredential{}}
+}
+
+func (f *fakePasskeyRepository) CreateCeremony(challengeHash string, purpose string, userId int64, userHandle string, expiresAt time.Time) error {
+	ceremony := &PasskeyCeremony{ChallengeHash: challengeHash, Purpose: purpose, UserHandle: userHandle, ExpiresAt: expiresAt}
+	if userId != 0 {
+		id := uint(userId)
+		ceremony.UserID = &id
+	}
+	f.ceremonies[challengeHash] = ceremony
+	return nil
+}
+
+func (f *fakePasskeyRepository) ConsumeCeremony(challengeHash string, purpose string) (*PasskeyCeremony, error) {
+	ceremony, ok := f.ceremonies[challengeHash]
+	if !ok || ceremony.Purpose != purpose || ceremony.UsedAt != nil || time.Now().After(ceremony.ExpiresAt) {
+		return nil, sql.ErrNoRows
+	}
+	now := time.Now()
+	ceremony.UsedAt = &now
+	return ceremony, nil
+}
+
+func (f *fakePasskeyRepository) GetCredentials(userId int64) ([]*PasskeyCredential, error) {
+	list := []*PasskeyCredential{}
+	for _, passkey := range f.passkeys {
+		if int64(passkey.UserID) == userId {
+			list = append(list, passkey)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakePasskeyRepository) GetByCredentialID(credentialId string) (*PasskeyCredential, error) {
+	passkey, ok := f.passkeys[credentialId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return passkey, nil
+}
+
+func (f *fakePasskeyRepository) UseCredential(id uint, signCount int64) (bool, error) {
+	for _, passkey := range f.passkeys {
+		if passkey.ID == id && ((passkey.SignCount == 0 && signCount == 0) || passkey.SignCount < signCount) {
+			passkey.SignCount = signCount
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// assertionTest is a passkey of user 7 registered with the service under test.
+type assertionTest struct {
+	t             *testing.T
+	service       PasskeyService
+	repository    *fakePasskeyRepository
+	authenticator *testAuthenticator
+}
+
+func newAssertionTest(t *testing.T, signCount int64) *assertionTest {
+	authenticator := newTestAuthenticator(t)
+	repository := newFakePasskeyRepository()
+	passkey := &PasskeyCredential{
+		UserID:       7,
+		CredentialID: base64.RawURLEncoding.EncodeToString(authenticator.credentialId),
+		UserHandle:   "handle-7",
+		PublicKey:    authenticator.publicKey(),
+		SignCount:    signCount,
+	}
+	passkey.ID = 1
+	repository.passkeys[passkey.CredentialID] = passkey
+	return &assertionTest{
+		t:             t,
+		service:       NewPasskeyService(repository, nil, events.NewBus()),
+		repository:    repository,
+		authenticator: authenticator,
+	}
+}
+
+// begin starts a passwordless login and returns its challenge.
+func (a *assertionTest) begin() string {
+	options, err := a.service.BeginAssertion(0)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return options.Challenge
+}
+
+// credential is the response of the authenticator to a challenge, from the origin.
+func (a *assertionTest) credential(challenge string, origin string, signCount uint32) AssertionCredential {
+	authData := a.authenticator.authData("localhost", flagUserPresent|flagUserVerified, signCount, false)
+	clientData := clientDataJSON("webauthn.get", challenge, origin)
+	credential := AssertionCredential{Type: "public-key", RawID: base64.RawURLEncoding.EncodeToString(a.authenticator.credentialId)}
+	credential.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(clientData)
+	credential.Response.AuthenticatorData = base64.RawURLEncoding.EncodeToString(authData)
+	credential.Response.Signature = base64.RawURLEncoding.EncodeToString(a.authenticator.sign(a.t, authData, clientData))
+	credential.Response.UserHandle = "handle-7"
+	return credential
+}
+
+const testOrigin = "http://localhost:3010"
+
+func TestFinishAssertion(t *testing.T) {
+	test := newAssertionTest(t, 4)
+	userId, err := test.service.FinishAssertion(test.credential(test.begin(), testOrigin, 5), 0)
+	if err != nil || userId != 7 {
+		t.Fatalf("FinishAssertion = %d, %v, want user 7", userId, err)
+	}
+	if count := test.repository.passkeys[base64.RawURLEncoding.EncodeToString(test.authenticator.credentialId)].SignCount; count != 5 {
+		t.Errorf("sign count = %d, want 5", count)
+	}
+}
+
+func TestFinishAssertionChecksChallenge(t *testing.T) {
+	test := newAssertionTest(t, 0)
+
+	if _, err := test.service.FinishAssertion(test.credential("bm90IGlzc3VlZA", testOrigin, 0), 0); !errors.Is(err, ErrCeremonyInvalid) {
+		t.Errorf("a challenge that was never issued = %v, want ErrCeremonyInvalid", err)
+	}
+
+	challenge := test.begin()
+	if _, err := test.service.FinishAssertion(test.credential(challenge, testOrigin, 0), 0); err != nil {
+		t.Fatalf("FinishAssertion: %v", err)
+	}
+	if _, err := test.service.FinishAssertion(test.credential(challenge, testOrigin, 0), 0); !errors.Is(err, ErrCeremonyInvalid) {
+		t.Errorf("a replayed challenge = %v, want ErrCeremonyInvalid", err)
+	}
+
+	// a challenge of a passwordless login does not finish a second factor
+	if _, err := test.service.FinishAssertion(test.credential(test.begin(), testOrigin, 0), 7); !errors.Is(err, ErrCeremonyInvalid) {
+		t.Errorf("a challenge of another purpose = %v, want ErrCeremonyInvalid", err)
+	}
+
+	expired := test.begin()
+	for _, ceremony := range test.repository.ceremonies {
+		ceremony.ExpiresAt = time.Now().Add(-time.Second)
+	}
+	if _, err := test.service.FinishAssertion(test.credential(expired, testOrigin, 0), 0); !errors.Is(err, ErrCeremonyInvalid) {
+		t.Errorf("an expired challenge = %v, want ErrCeremonyInvalid", err)
+	}
+}
+
+func TestFinishAssertionChecksOrigin(t *testing.T) {
+	test := newAssertionTest(t, 0)
+	challenge := test.begin()
+	if _, err := test.service.FinishAssertion(test.credential(challenge, "https://evil.example", 0), 0); !errors.Is(err, ErrCredentialInvalid) {
+		t.Errorf("another origin = %v, want ErrCredentialInvalid", err)
+	}
+	// the ceremony was not consumed by the refused response
+	if _, err := test.service.FinishAssertion(test.credential(challenge, testOrigin, 0), 0); err != nil {
+		t.Errorf("FinishAssertion after a refused origin: %v", err)
+	}
+}
+
+func TestFinishAssertionRefusesSignCountRegression(t *testing.T) {
+	tests := []struct {
+		name     string
+		stored   int64
+		reported uint32
+		ok       bool
+	}{
+		{"counter grows", 10, 11, true},
+		{"counter repeats", 10, 10, false},
+		{"counter goes back", 10, 3, false},
+		{"counter drops to zero", 10, 0, false},
+		{"authenticator keeps no counter", 0, 0, true},
+		{"counter starts", 0, 1, true},
+	}
+	for _, tt := range tests {
+		test := newAssertionTest(t, tt.stored)
+		_, err := test.service.FinishAssertion(test.credential(test.begin(), testOrigin, tt.reported), 0)
+		if tt.ok && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrCredentialInvalid) {
+			t.Errorf("%s: FinishAssertion = %v, want ErrCredentialInvalid", tt.name, err)
+		}
+	}
+}
+
+func TestFinishAssertionRefusesBadSignature(t *testing.T) {
+	test := newAssertionTest(t, 0)
+	credential := test.credential(test.begin(), testOrigin, 1)
+	signature, _ := decodeBase64URL(credential.Response.Signature)
+	signature[len(signature)-1] ^= 0x01
+	credential.Response.Signature = base64.RawURLEncoding.EncodeToString(signature)
+	if _, err := test.service.FinishAssertion(credential, 0); !errors.Is(err, ErrCredentialInvalid) {
+		t.Errorf("an altered signature = %v, want ErrCredentialInvalid", err)
+	}
+	if count := test.repository.passkeys[base64.RawURLEncoding.EncodeToString(test.authenticator.credentialId)].SignCount; count != 0 {
+		t.Errorf("sign count = %d after a refused assertion, want 0", count)
+	}
+}
@@ -0,0 +1,240 @@
+package passkey
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	env "go_project_structure/config/env"
+)
+
+var ErrCredentialInvalid = errors.New("passkey response is invalid")
+
+// COSE algorithms of the credential public keys this relying party accepts, in
+// order of preference.
+const (
+	algES256 int64 = -7
+	algEdDSA int64 = -8
+	algRS256 int64 = -257
+)
+
+var supportedAlgorithms = []int64{algES256, algEdDSA, algRS256}
+
+// authenticator data flags
+const (
+	flagUserPresent      byte = 0x01
+	flagUserVerified     byte = 0x04
+	flagAttestedCredData byte = 0x40
+)
+
+// RpID is the relying party ID credentials are scoped to: the domain of the site,
+// without scheme or port.
+func RpID() string {
+	return env.GetString("WEBAUTHN_RP_ID", "localhost")
+}
+
+func rpName() string {
+	return env.GetString("WEBAUTHN_RP_NAME", "go_project_structure")
+}
+
+// allowedOrigins are the origins, with scheme and port, the ceremonies may run on.
+// They are set with WEBAUTHN_ORIGINS, a comma separated list.
+func allowedOrigins() []string {
+	origins := []string{}
+	for _, origin := range strings.Split(env.GetString("WEBAUTHN_ORIGINS", "http://localhost:3010"), ",") {
+		if origin = strings.TrimSuffix(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// decodeBase64URL decodes the base64url fields browsers send, padded or not.
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// parseClientData checks the client data of a ceremony of the given type
+// ("webauthn.create" or "webauthn.get") ran on an allowed origin.
+func parseClientData(raw []byte, ceremonyType string) (*clientData, error) {
+	data := &clientData{}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, fmt.Errorf("%w: client data is not JSON", ErrCredentialInvalid)
+	}
+	if data.Type != ceremonyType {
+		return nil, fmt.Errorf("%w: client data is not of type %s", ErrCredentialInvalid, ceremonyType)
+	}
+	if data.Challenge == "" {
+		return nil, fmt.Errorf("%w: client data has no challenge", ErrCredentialInvalid)
+	}
+	for _, origin := range allowedOrigins() {
+		if data.Origin == origin {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: origin %q is not allowed", ErrCredentialInvalid, data.Origin)
+}
+
+type authenticatorData struct {
+	RpIDHash  []byte
+	Flags     byte
+	SignCount uint32
+	// CredentialID and PublicKey, the COSE encoded credential public key, are only
+	// present in the authenticator data of a registration.
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+func parseAuthenticatorData(raw []byte) (*authenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, fmt.Errorf("%w: authenticator data is too short", ErrCredentialInvalid)
+	}
+	data := &authenticatorData{
+		RpIDHash:  raw[:32],
+		Flags:     raw[32],
+		SignCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	if data.Flags&flagAttestedCredData == 0 {
+		return data, nil
+	}
+
+	// attested credential data: AAGUID, credential ID length, credential ID, public key
+	rest := raw[37:]
+	if len(rest) < 18 {
+		return nil, fmt.Errorf("%w: attested credential data is too short", ErrCredentialInvalid)
+	}
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+		return nil, fmt.Errorf("%w: credential ID is malformed", ErrCredentialInvalid)
+	}
+	data.CredentialID = rest[:idLength]
+	_, size, err := decodeCbor(rest[idLength:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: credential public key is malformed", ErrCredentialInvalid)
+	}
+	data.PublicKey = rest[idLength : idLength+size]
+	return data, nil
+}
+
+// check verifies the authenticator data belongs to this relying party and the user
+// was present, and verified to the authenticator when userVerification is set.
+func (a *authenticatorData) check(userVerification bool) error {
+	expected := sha256.Sum256([]byte(RpID()))
+	if subtle.ConstantTimeCompare(a.RpIDHash, expected[:]) != 1 {
+		return fmt.Errorf("%w: credential is scoped to another relying party", ErrCredentialInvalid)
+	}
+	if a.Flags&flagUserPresent == 0 {
+		return fmt.Errorf("%w: user was not present", ErrCredentialInvalid)
+	}
+	if userVerification && a.Flags&flagUserVerified == 0 {
+		return fmt.Errorf("%w: user was not verified", ErrCredentialInvalid)
+	}
+	return nil
+}
+
+// parseAttestationObject returns the authenticator data of a registration. The
+// attestation statement is not verified: registrations ask for "none", so a
+// passkey proves possession of its key, not the make of the authenticator.
+func parseAttestationObject(raw []byte) ([]byte, error) {
+	item, _, err := decodeCbor(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: attestation object is malformed", ErrCredentialInvalid)
+	}
+	object, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: attestation object is malformed", ErrCredentialInvalid)
+	}
+	authData, ok := object["authData"].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("%w: attestation object has no authenticator data", ErrCredentialInvalid)
+	}
+	return authData, nil
+}
+
+// parsePublicKey decodes a COSE encoded credential public key and returns its
+// algorithm and key.
+func parsePublicKey(raw []byte) (int64, crypto.PublicKey, error) {
+	item, _, err := decodeCbor(raw)
+	if err != nil {
+		return 0, nil, err
+	}
+	key, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return 0, nil, errMalformedCbor
+	}
+	kty, _ := key[int64(1)].(int64)
+	alg, _ := key[int64(3)].(int64)
+	crv, _ := key[int64(-1)].(int64)
+	x, _ := key[int64(-2)].([]byte)
+
+	switch {
+	case alg == algES256 && kty == 2 && crv == 1:
+		y, _ := key[int64(-3)].([]byte)
+		if len(x) != 32 || len(y) != 32 {
+			return 0, nil, errors.New("malformed P-256 key")
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return 0, nil, errors.New("P-256 key is not on the curve")
+		}
+		return alg, pub, nil
+	case alg == algEdDSA && kty == 1 && crv == 6:
+		if len(x) != ed25519.PublicKeySize {
+			return 0, nil, errors.New("malformed Ed25519 key")
+		}
+		return alg, ed25519.PublicKey(x), nil
+	case alg == algRS256 && kty == 3:
+		n, _ := key[int64(-1)].([]byte)
+		e, _ := key[int64(-2)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return 0, nil, errors.New("malformed RSA key")
+		}
+		return alg, &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	}
+	return 0, nil, fmt.Errorf("unsupported key type %d with algorithm %d", kty, alg)
+}
+
+// verifyAssertionSignature checks the signature of an assertion, which covers the
+// authenticator data and the hash of the client data.
+func verifyAssertionSignature(publicKey []byte, authData []byte, clientDataJSON []byte, signature []byte) error {
+	alg, pub, err := parsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(bytes.Clone(authData), clientDataHash[:]...)
+	digest := sha256.Sum256(signed)
+
+	valid := false
+	switch alg {
+	case algES256:
+		valid = ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), digest[:], signature)
+	case algEdDSA:
+		valid = ed25519.Verify(pub.(ed25519.PublicKey), signed, signature)
+	case algRS256:
+		valid = rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	}
+	if !valid {
+		return fmt.Errorf("%w: signature does not verify", ErrCredentialInvalid)
+	}
+	return nil
+}
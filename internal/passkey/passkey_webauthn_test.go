@@ -0,0 +1,273 @@
+package passkey
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// testAuthenticator is an ES256 passkey, the algorithm authenticators use most.
+type testAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialId []byte
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testAuthenticator{key: key, credentialId: []byte("credential-1")}
+}
+
+func (a *testAuthenticator) publicKey() []byte {
+	x, y := make([]byte, 32), make([]byte, 32)
+	a.key.X.FillBytes(x)
+	a.key.Y.FillBytes(y)
+	return cborEncode(map[interface{}]interface{}{
+		int64(1): int64(2), int64(3): algES256, int64(-1): int64(1), int64(-2): x, int64(-3): y,
+	}, int64(1), int64(3), int64(-1), int64(-2), int64(-3))
+}
+
+// authData builds authenticator data for the relying party rpId; attested adds the
+// credential ID and public key, as in a registration.
+func (a *testAuthenticator) authData(rpId string, flags byte, signCount uint32, attested bool) []byte {
+	rpIdHash := sha256.Sum256([]byte(rpId))
+	data := append(rpIdHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, signCount)
+	if attested {
+		data[32] |= flagAttestedCredData
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialId)))
+		data = append(data, a.credentialId...)
+		data = append(data, a.publicKey()...)
+	}
+	return data
+}
+
+func (a *testAuthenticator) sign(t *testing.T, authData []byte, clientDataJSON []byte) []byte {
+	t.Helper()
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(bytes.Clone(authData), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signature
+}
+
+func clientDataJSON(ceremonyType string, challenge string, origin string) []byte {
+	raw, _ := json.Marshal(clientData{Type: ceremonyType, Challenge: challenge, Origin: origin})
+	return raw
+}
+
+func TestParseClientData(t *testing.T) {
+	t.Setenv("WEBAUTHN_ORIGINS", "https://example.com, https://app.example.com/")
+
+	tests := []struct {
+		name string
+		raw  []byte
+		ok   bool
+	}{
+		{"allowed origin", clientDataJSON("webauthn.get", "abc", "https://example.com"), true},
+		{"second allowed origin", clientDataJSON("webauthn.get", "abc", "https://app.example.com"), true},
+		{"other origin", clientDataJSON("webauthn.get", "abc", "https://evil.example"), false},
+		{"origin with another port", clientDataJSON("webauthn.get", "abc", "https://example.com:8443"), false},
+		{"origin with another scheme", clientDataJSON("webauthn.get", "abc", "http://example.com"), false},
+		{"no origin", clientDataJSON("webauthn.get", "abc", ""), false},
+		{"registration client data", clientDataJSON("webauthn.create", "abc", "https://example.com"), false},
+		{"no challenge", clientDataJSON("webauthn.get", "", "https://example.com"), false},
+		{"not JSON", []byte("{"), false},
+	}
+	for _, tt := range tests {
+		_, err := parseClientData(tt.raw, "webauthn.get")
+		if tt.ok && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrCredentialInvalid) {
+			t.Errorf("%s: parseClientData = %v, want ErrCredentialInvalid", tt.name, err)
+		}
+	}
+}
+
+func TestParseAuthenticatorData(t *testing.T) {
+	authenticator := newTestAuthenticator(t)
+
+	data, err := parseAuthenticatorData(authenticator.authData("localhost", flagUserPresent, 42, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.SignCount != 42 || !bytes.Equal(data.CredentialID, authenticator.credentialId) || !bytes.Equal(data.PublicKey, authenticator.publicKey()) {
+		t.Errorf("parseAuthenticatorData = %+v", data)
+	}
+
+	// the public key is followed by extensions, which are not part of it
+	withExtensions := append(authenticator.authData("localhost", flagUserPresent|0x80, 42, true), 0xa0)
+	if data, err := parseAuthenticatorData(withExtensions); err != nil || !bytes.Equal(data.PublicKey, authenticator.publicKey()) {
+		t.Errorf("parseAuthenticatorData with extensions = %v", err)
+	}
+
+	attested := authenticator.authData("localhost", flagUserPresent, 0, true)
+	noCredentialId := append(authenticator.authData("localhost", flagUserPresent|flagAttestedCredData, 0, false), make([]byte, 18)...)
+	tests := []struct {
+		name string
+		raw  []byte
+	}{
+		{"too short", attested[:36]},
+		{"attested data cut short", attested[:37+17]},
+		{"credential ID cut short", attested[:37+18+len(authenticator.credentialId)-1]},
+		{"public key cut short", attested[:len(attested)-1]},
+		{"empty credential ID", noCredentialId},
+	}
+	for _, tt := range tests {
+		if _, err := parseAuthenticatorData(tt.raw); !errors.Is(err, ErrCredentialInvalid) {
+			t.Errorf("%s: parseAuthenticatorData = %v, want ErrCredentialInvalid", tt.name, err)
+		}
+	}
+}
+
+func TestAuthenticatorDataCheck(t *testing.T) {
+	t.Setenv("WEBAUTHN_RP_ID", "example.com")
+	authenticator := newTestAuthenticator(t)
+
+	tests := []struct {
+		name             string
+		rpId             string
+		flags            byte
+		userVerification bool
+		ok               bool
+	}{
+		{"present and verified", "example.com", flagUserPresent | flagUserVerified, true, true},
+		{"present, verification not asked", "example.com", flagUserPresent, false, true},
+		{"other relying party", "evil.example", flagUserPresent | flagUserVerified, true, false},
+		{"parent domain", "com", flagUserPresent | flagUserVerified, true, false},
+		{"user not present", "example.com", flagUserVerified, true, false},
+		{"user not verified", "example.com", flagUserPresent, true, false},
+	}
+	for _, tt := range tests {
+		data, err := parseAuthenticatorData(authenticator.authData(tt.rpId, tt.flags, 1, false))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		err = data.check(tt.userVerification)
+		if tt.ok && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrCredentialInvalid) {
+			t.Errorf("%s: check = %v, want ErrCredentialInvalid", tt.name, err)
+		}
+	}
+}
+
+func TestParseAttestationObject(t *testing.T) {
+	authData := []byte{1, 2, 3}
+	object := cborEncode(map[interface{}]interface{}{"fmt": "none", "authData": authData}, "fmt", "authData")
+	if got, err := parseAttestationObject(object); err != nil || !bytes.Equal(got, authData) {
+		t.Errorf("parseAttestationObject = %v, %v", got, err)
+	}
+
+	for name, raw := range map[string][]byte{
+		"not a map":             cborEncode("none"),
+		"no authData":           cborEncode(map[interface{}]interface{}{"fmt": "none"}, "fmt"),
+		"authData not bytes":    cborEncode(map[interface{}]interface{}{"authData": "abc"}, "authData"),
+		"truncated":             object[:len(object)-1],
+		"oversized byte string": append(cborHead(2, 1<<31), 0x00),
+	} {
+		if _, err := parseAttestationObject(raw); !errors.Is(err, ErrCredentialInvalid) {
+			t.Errorf("%s: parseAttestationObject = %v, want ErrCredentialInvalid", name, err)
+		}
+	}
+}
+
+func TestParsePublicKeyRejectsBadKeys(t *testing.T) {
+	authenticator := newTestAuthenticator(t)
+	x, y := make([]byte, 32), make([]byte, 32)
+	authenticator.key.X.FillBytes(x)
+	authenticator.key.Y.FillBytes(y)
+	offCurve := bytes.Clone(y)
+	offCurve[31] ^= 0x01
+	ec2 := func(alg int64, x []byte, y []byte) []byte {
+		return cborEncode(map[interface{}]interface{}{
+			int64(1): int64(2), int64(3): alg, int64(-1): int64(1), int64(-2): x, int64(-3): y,
+		}, int64(1), int64(3), int64(-1), int64(-2), int64(-3))
+	}
+
+	if alg, _, err := parsePublicKey(ec2(algES256, x, y)); err != nil || alg != algES256 {
+		t.Fatalf("parsePublicKey = %d, %v", alg, err)
+	}
+	for name, raw := range map[string][]byte{
+		"point off the curve":   ec2(algES256, x, offCurve),
+		"short coordinate":      ec2(algES256, x[1:], y),
+		"unsupported algorithm": ec2(-35, x, y),
+		"not a map":             cborEncode(int64(1)),
+		"truncated":             ec2(algES256, x, y)[:40],
+	} {
+		if _, _, err := parsePublicKey(raw); err == nil {
+			t.Errorf("%s: parsePublicKey accepted the key", name)
+		}
+	}
+}
+
+func TestVerifyAssertionSignature(t *testing.T) {
+	authenticator := newTestAuthenticator(t)
+	authData := authenticator.authData("localhost", flagUserPresent, 1, false)
+	clientData := clientDataJSON("webauthn.get", "abc", "http://localhost:3010")
+	signature := authenticator.sign(t, authData, clientData)
+
+	if err := verifyAssertionSignature(authenticator.publicKey(), authData, clientData, signature); err != nil {
+		t.Fatalf("verifyAssertionSignature: %v", err)
+	}
+
+	other := newTestAuthenticator(t)
+	flipped := bytes.Clone(signature)
+	flipped[len(flipped)-1] ^= 0x01
+	raisedCounter := authenticator.authData("localhost", flagUserPresent, 2, false)
+	tests := []struct {
+		name       string
+		publicKey  []byte
+		authData   []byte
+		clientData []byte
+		signature  []byte
+	}{
+		{"altered signature", authenticator.publicKey(), authData, clientData, flipped},
+		{"empty signature", authenticator.publicKey(), authData, clientData, nil},
+		{"signature of another key", other.publicKey(), authData, clientData, signature},
+		{"altered authenticator data", authenticator.publicKey(), raisedCounter, clientData, signature},
+		{"other client data", authenticator.publicKey(), authData, clientDataJSON("webauthn.get", "abd", "http://localhost:3010"), signature},
+	}
+	for _, tt := range tests {
+		if err := verifyAssertionSignature(tt.publicKey, tt.authData, tt.clientData, tt.signature); !errors.Is(err, ErrCredentialInvalid) {
+			t.Errorf("%s: verifyAssertionSignature = %v, want ErrCredentialInvalid", tt.name, err)
+		}
+	}
+}
+
+func TestVerifyAssertionSignatureEd25519(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := cborEncode(map[interface{}]interface{}{
+		int64(1): int64(1), int64(3): algEdDSA, int64(-1): int64(6), int64(-2): []byte(public),
+	}, int64(1), int64(3), int64(-1), int64(-2))
+	authData := bytes.Repeat([]byte{0x01}, 37)
+	clientData := clientDataJSON("webauthn.get", "abc", "http://localhost:3010")
+	clientDataHash := sha256.Sum256(clientData)
+	signature := ed25519.Sign(private, append(bytes.Clone(authData), clientDataHash[:]...))
+
+	if err := verifyAssertionSignature(publicKey, authData, clientData, signature); err != nil {
+		t.Errorf("verifyAssertionSignature: %v", err)
+	}
+	signature[0] ^= 0x01
+	if err := verifyAssertionSignature(publicKey, authData, clientData, signature); !errors.Is(err, ErrCredentialInvalid) {
+		t.Errorf("verifyAssertionSignature of an altered signature = %v, want ErrCredentialInvalid", err)
+	}
+}
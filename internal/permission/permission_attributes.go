@@ -0,0 +1,92 @@
+package permission
+
+import (
+	"go_project_structure/internal/token"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ResourceAttributeProvider returns the attributes of one object of a resource type,
+// e.g. the owner_id of the user with id 17. They are exposed to conditions as resource.*.
+type ResourceAttributeProvider func(resourceId string) map[string]interface{}
+
+var (
+	resourceAttributesMu       sync.RWMutex
+	resourceAttributeProviders = map[string]ResourceAttributeProvider{}
+)
+
+// RegisterResourceAttributes sets the attribute provider of a resource type. A later
+// provider replaces an earlier one.
+func RegisterResourceAttributes(resource string, provider ResourceAttributeProvider) {
+	resourceAttributesMu.Lock()
+	defer resourceAttributesMu.Unlock()
+	resourceAttributeProviders[resource] = provider
+}
+
+// RequestAttributes builds the attributes of an HTTP request made by or checked for a
+// user. When the user is the caller, subject.type tells a service account apart from
+// a person.
+func RequestAttributes(r *http.Request, userId int64) Attributes {
+	attributes := NewAttributes(userId, r.RemoteAddr, r.Method, r.URL.Path)
+	if principal, ok := token.PrincipalFromContext(r.Context()); ok && principal.UserID == userId {
+		attributes["subject"].(map[string]interface{})["type"] = principal.SubjectType
+	}
+	return attributes
+}
+
+// NewAttributes builds the attributes of a call made by or checked for a user:
+//
+//	subject.id
+//	request.ip, request.method, request.path
+//	time.hour, time.minute, time.weekday (UTC, Sunday is 0)
+//
+// remoteAddr may carry a port, which is dropped.
+func NewAttributes(userId int64, remoteAddr string, method string, path string) Attributes {
+	ip := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		ip = host
+	}
+	now := time.Now().UTC()
+
+	return Attributes{
+		"subject": map[string]interface{}{
+			"id": userId,
+		},
+		"request": map[string]interface{}{
+			"ip":     ip,
+			"method": method,
+			"path":   path,
+		},
+		"time": map[string]interface{}{
+			"hour":    now.Hour(),
+			"minute":  now.Minute(),
+			"weekday": int(now.Weekday()),
+		},
+	}
+}
+
+// WithResource returns a copy of the attributes with resource.type, resource.id and
+// whatever the provider registered for the resource type adds.
+func WithResource(attributes Attributes, resource string, resourceId string) Attributes {
+	withResource := Attributes{}
+	for key, value := range attributes {
+		withResource[key] = value
+	}
+
+	values := map[string]interface{}{}
+	resourceAttributesMu.RLock()
+	provider, ok := resourceAttributeProviders[resource]
+	resourceAttributesMu.RUnlock()
+	if ok {
+		for key, value := range provider(resourceId) {
+			values[key] = value
+		}
+	}
+	values["type"] = resource
+	values["id"] = resourceId
+
+	withResource["resource"] = values
+	return withResource
+}
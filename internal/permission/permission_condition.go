@@ -0,0 +1,660 @@
+package permission
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Grant conditions. A role permission may carry a condition that has to hold
+// for the grant to take part in a decision, e.g.
+//
+//	request.ip in 10.0.0.0/8
+//	resource.owner_id == subject.id
+//	time.hour between 9 and 18 and time.weekday in [1, 2, 3, 4, 5]
+//
+// The language only reads attributes and compares values: there are no function
+// calls, assignments or loops, and the length and nesting depth of a condition are
+// bounded, so evaluating one is cheap and cannot reach outside the attributes.
+//
+//	expr       = or
+//	or         = and { ("or" | "||") and }
+//	and        = not { ("and" | "&&") not }
+//	not        = ("not" | "!") not | comparison
+//	comparison = operand [ ("==" | "!=" | "<" | "<=" | ">" | ">=") operand
+//	                     | ["not"] "in" operand
+//	                     | "between" operand "and" operand ]
+//	operand    = number | string | address | "true" | "false" | "null"
+//	           | attribute | "[" [ operand { "," operand } ] "]" | "(" expr ")"
+//
+// Attributes are dotted paths into the evaluation attributes (subject.id,
+// request.ip). An address is an IP (10.0.0.1) or a CIDR block (10.0.0.0/8);
+// "in" tests list membership or whether an IP lies inside a CIDR block.
+
+const (
+	maxConditionLength = 1024
+	maxConditionDepth  = 32
+)
+
+var ErrInvalidCondition = errors.New("invalid condition")
+
+// Attributes are the facts a condition is evaluated against, nested by namespace:
+// subject, request, resource and time.
+type Attributes map[string]interface{}
+
+// Condition is a parsed grant condition.
+type Condition struct {
+	source string
+	root   conditionNode
+}
+
+func (c *Condition) String() string {
+	return c.source
+}
+
+// Evaluate reports whether the condition holds. Referencing an attribute that is
+// not set or comparing values of different kinds is an error.
+func (c *Condition) Evaluate(attributes Attributes) (bool, error) {
+	value, err := c.root.eval(attributes)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("condition %q does not evaluate to true or false", c.source)
+	}
+	return result, nil
+}
+
+// conditionCache keeps the parsed form of every condition seen at check time.
+var conditionCache sync.Map
+
+// CompileCondition parses a condition, reusing an earlier parse of the same source.
+func CompileCondition(source string) (*Condition, error) {
+	if cached, ok := conditionCache.Load(source); ok {
+		return cached.(*Condition), nil
+	}
+	if len(source) > maxConditionLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrInvalidCondition, maxConditionLength)
+	}
+
+	tokens, err := lexCondition(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &conditionParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidCondition, p.peek().text)
+	}
+
+	condition := &Condition{source: source, root: root}
+	conditionCache.Store(source, condition)
+	return condition, nil
+}
+
+// conditionHolds evaluates the condition of a grant; an empty condition always holds.
+func conditionHolds(source string, attributes Attributes) (bool, error) {
+	if strings.TrimSpace(source) == "" {
+		return true, nil
+	}
+	condition, err := CompileCondition(source)
+	if err != nil {
+		return false, err
+	}
+	return condition.Evaluate(attributes)
+}
+
+// lexer
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenAddress
+	tokenSymbol
+)
+
+type conditionToken struct {
+	kind tokenKind
+	text string
+}
+
+func lexCondition(source string) ([]conditionToken, error) {
+	tokens := []conditionToken{}
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentStart(c):
+			start := i
+			for i < len(source) && (isIdentStart(source[i]) || isDigit(source[i]) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, conditionToken{kind: tokenIdent, text: source[start:i]})
+		case isDigit(c) || (c == '-' && i+1 < len(source) && isDigit(source[i+1])):
+			start := i
+			i++
+			for i < len(source) && (isDigit(source[i]) || source[i] == '.' || source[i] == ':' || source[i] == '/') {
+				i++
+			}
+			text := source[start:i]
+			kind := tokenNumber
+			if strings.ContainsAny(text, "/:") || strings.Count(text, ".") > 1 {
+				kind = tokenAddress
+			}
+			tokens = append(tokens, conditionToken{kind: kind, text: text})
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(source[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated string", ErrInvalidCondition)
+			}
+			tokens = append(tokens, conditionToken{kind: tokenString, text: source[i+1 : i+1+end]})
+			i += end + 2
+		default:
+			symbol := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]", ","} {
+				if strings.HasPrefix(source[i:], candidate) {
+					symbol = candidate
+					break
+				}
+			}
+			if symbol == "" {
+				return nil, fmt.Errorf("%w: unexpected character %q", ErrInvalidCondition, c)
+			}
+			tokens = append(tokens, conditionToken{kind: tokenSymbol, text: symbol})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, conditionToken{kind: tokenEOF}), nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parser
+
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+	depth  int
+}
+
+func (p *conditionParser) peek() conditionToken {
+	return p.tokens[p.pos]
+}
+
+func (p *conditionParser) next() conditionToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+// accept consumes the next token when it is one of the given symbols or keywords.
+func (p *conditionParser) accept(texts ...string) bool {
+	token := p.peek()
+	if token.kind != tokenSymbol && token.kind != tokenIdent {
+		return false
+	}
+	for _, text := range texts {
+		if token.text == text {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *conditionParser) enter() error {
+	p.depth++
+	if p.depth > maxConditionDepth {
+		return fmt.Errorf("%w: nested deeper than %d levels", ErrInvalidCondition, maxConditionDepth)
+	}
+	return nil
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or", "||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and", "&&") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseNot() (conditionNode, error) {
+	if p.accept("not", "!") {
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		operand, err := p.parseNot()
+		p.depth--
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (conditionNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	token := p.peek()
+	switch {
+	case token.kind == tokenSymbol && (token.text == "==" || token.text == "!=" || token.text == "<" || token.text == "<=" || token.text == ">" || token.text == ">="):
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: token.text, left: left, right: right}, nil
+	case p.accept("in"):
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return inNode{left: left, right: right}, nil
+	case token.kind == tokenIdent && token.text == "not" && p.tokens[p.pos+1].kind == tokenIdent && p.tokens[p.pos+1].text == "in":
+		p.pos += 2
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: inNode{left: left, right: right}}, nil
+	case p.accept("between"):
+		low, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept("and") {
+			return nil, fmt.Errorf("%w: between needs an and", ErrInvalidCondition)
+		}
+		high, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return betweenNode{value: left, low: low, high: high}, nil
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseOperand() (conditionNode, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+
+	token := p.next()
+	switch token.kind {
+	case tokenNumber:
+		number, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad number %q", ErrInvalidCondition, token.text)
+		}
+		return literalNode{value: number}, nil
+	case tokenString:
+		return literalNode{value: token.text}, nil
+	case tokenAddress:
+		if strings.Contains(token.text, "/") {
+			_, network, err := net.ParseCIDR(token.text)
+			if err != nil {
+				return nil, fmt.Errorf("%w: bad CIDR block %q", ErrInvalidCondition, token.text)
+			}
+			return literalNode{value: network}, nil
+		}
+		ip := net.ParseIP(token.text)
+		if ip == nil {
+			return nil, fmt.Errorf("%w: bad address %q", ErrInvalidCondition, token.text)
+		}
+		return literalNode{value: ip.String()}, nil
+	case tokenIdent:
+		switch token.text {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		case "and", "or", "not", "in", "between":
+			return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidCondition, token.text)
+		}
+		path := strings.Split(token.text, ".")
+		for _, segment := range path {
+			if segment == "" {
+				return nil, fmt.Errorf("%w: bad attribute %q", ErrInvalidCondition, token.text)
+			}
+		}
+		return attributeNode{path: path}, nil
+	case tokenSymbol:
+		switch token.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept(")") {
+				return nil, fmt.Errorf("%w: missing )", ErrInvalidCondition)
+			}
+			return inner, nil
+		case "[":
+			items := []conditionNode{}
+			if p.accept("]") {
+				return listNode{items: items}, nil
+			}
+			for {
+				item, err := p.parseOperand()
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+				if p.accept("]") {
+					return listNode{items: items}, nil
+				}
+				if !p.accept(",") {
+					return nil, fmt.Errorf("%w: missing , or ]", ErrInvalidCondition)
+				}
+			}
+		}
+	case tokenEOF:
+		return nil, fmt.Errorf("%w: unexpected end", ErrInvalidCondition)
+	}
+	return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidCondition, token.text)
+}
+
+// evaluation
+
+type conditionNode interface {
+	eval(attributes Attributes) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) eval(Attributes) (interface{}, error) {
+	return n.value, nil
+}
+
+type attributeNode struct {
+	path []string
+}
+
+func (n attributeNode) eval(attributes Attributes) (interface{}, error) {
+	var current interface{} = map[string]interface{}(attributes)
+	for _, segment := range n.path {
+		var values map[string]interface{}
+		switch typed := current.(type) {
+		case map[string]interface{}:
+			values = typed
+		case Attributes:
+			values = typed
+		}
+		value, ok := values[segment]
+		if !ok {
+			return nil, fmt.Errorf("unknown attribute %s", strings.Join(n.path, "."))
+		}
+		current = value
+	}
+	return normalizeValue(current), nil
+}
+
+type listNode struct {
+	items []conditionNode
+}
+
+func (n listNode) eval(attributes Attributes) (interface{}, error) {
+	values := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(attributes)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+type logicalNode struct {
+	and         bool
+	left, right conditionNode
+}
+
+func (n logicalNode) eval(attributes Attributes) (interface{}, error) {
+	left, err := evalBool(n.left, attributes)
+	if err != nil {
+		return nil, err
+	}
+	if left != n.and {
+		return left, nil
+	}
+	return evalBool(n.right, attributes)
+}
+
+type notNode struct {
+	operand conditionNode
+}
+
+func (n notNode) eval(attributes Attributes) (interface{}, error) {
+	value, err := evalBool(n.operand, attributes)
+	if err != nil {
+		return nil, err
+	}
+	return !value, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right conditionNode
+}
+
+func (n compareNode) eval(attributes Attributes) (interface{}, error) {
+	left, err := n.left.eval(attributes)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return valuesEqual(left, right), nil
+	case "!=":
+		return !valuesEqual(left, right), nil
+	}
+
+	order, err := compareOrdered(left, right)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "<":
+		return order < 0, nil
+	case "<=":
+		return order <= 0, nil
+	case ">":
+		return order > 0, nil
+	default:
+		return order >= 0, nil
+	}
+}
+
+type inNode struct {
+	left, right conditionNode
+}
+
+func (n inNode) eval(attributes Attributes) (interface{}, error) {
+	left, err := n.left.eval(attributes)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	if text, ok := right.(string); ok {
+		if _, network, err := net.ParseCIDR(text); err == nil {
+			right = network
+		}
+	}
+
+	switch container := right.(type) {
+	case []interface{}:
+		for _, item := range container {
+			if valuesEqual(left, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case *net.IPNet:
+		text, ok := left.(string)
+		ip := net.ParseIP(text)
+		if !ok || ip == nil {
+			return nil, fmt.Errorf("%v is not an IP address", left)
+		}
+		return container.Contains(ip), nil
+	}
+	return nil, fmt.Errorf("in needs a list or a CIDR block, got %v", right)
+}
+
+type betweenNode struct {
+	value, low, high conditionNode
+}
+
+func (n betweenNode) eval(attributes Attributes) (interface{}, error) {
+	value, err := n.value.eval(attributes)
+	if err != nil {
+		return nil, err
+	}
+	low, err := n.low.eval(attributes)
+	if err != nil {
+		return nil, err
+	}
+	high, err := n.high.eval(attributes)
+	if err != nil {
+		return nil, err
+	}
+	aboveLow, err := compareOrdered(value, low)
+	if err != nil {
+		return nil, err
+	}
+	belowHigh, err := compareOrdered(value, high)
+	if err != nil {
+		return nil, err
+	}
+	return aboveLow >= 0 && belowHigh <= 0, nil
+}
+
+func evalBool(node conditionNode, attributes Attributes) (bool, error) {
+	value, err := node.eval(attributes)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%v is not true or false", value)
+	}
+	return result, nil
+}
+
+// normalizeValue turns every number into a float64 so values of different integer types compare equal.
+func normalizeValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case int:
+		return float64(typed)
+	case int32:
+		return float64(typed)
+	case int64:
+		return float64(typed)
+	case uint:
+		return float64(typed)
+	case uint32:
+		return float64(typed)
+	case uint64:
+		return float64(typed)
+	case float32:
+		return float64(typed)
+	}
+	return value
+}
+
+func valuesEqual(left interface{}, right interface{}) bool {
+	switch l := left.(type) {
+	case nil:
+		return right == nil
+	case float64:
+		r, ok := right.(float64)
+		return ok && l == r
+	case string:
+		r, ok := right.(string)
+		return ok && l == r
+	case bool:
+		r, ok := right.(bool)
+		return ok && l == r
+	}
+	return false
+}
+
+func compareOrdered(left interface{}, right interface{}) (int, error) {
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			switch {
+			case l < r:
+				return -1, nil
+			case l > r:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot order %v and %v", left, right)
+}
@@ -0,0 +1,225 @@
+package permission
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func conditionAttributes() Attributes {
+	return Attributes{
+		"subject":  map[string]interface{}{"id": int64(7), "type": "user", "mfa": true},
+		"request":  map[string]interface{}{"ip": "10.1.2.3", "method": "GET"},
+		"resource": map[string]interface{}{"owner_id": 7, "tags": "public"},
+		"time":     map[string]interface{}{"hour": 10, "weekday": 3},
+	}
+}
+
+func TestConditionEvaluate(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		// comparisons
+		{`subject.id == 7`, true},
+		{`subject.id != 7`, false},
+		{`resource.owner_id == subject.id`, true},
+		{`subject.type == "user"`, true},
+		{`subject.type == 'service_account'`, false},
+		{`subject.mfa == true`, true},
+		{`time.hour < 10`, false},
+		{`time.hour <= 10`, true},
+		{`time.hour > 9`, true},
+		{`time.hour >= 11`, false},
+		{`request.method < "POST"`, true},
+		{`subject.id == -7`, false},
+		{`subject.id == 7.0`, true},
+		{`null == null`, true},
+		// in, not in and between
+		{`request.ip in 10.0.0.0/8`, true},
+		{`request.ip in 192.168.0.0/16`, false},
+		{`request.ip in "10.1.0.0/16"`, true},
+		{`request.ip == 10.1.2.3`, true},
+		{`time.weekday in [1, 2, 3, 4, 5]`, true},
+		{`time.weekday not in [1, 2, 3, 4, 5]`, false},
+		{`subject.type in ["user", "service_account"]`, true},
+		{`subject.type in []`, false},
+		{`time.hour between 9 and 18`, true},
+		{`time.hour between 11 and 18`, false},
+		{`time.hour between 10 and 10`, true},
+		// precedence: not binds tighter than and, and tighter than or
+		{`true or false and false`, true},
+		{`(true or false) and false`, false},
+		{`false and false or true`, true},
+		{`false and (false or true)`, false},
+		{`not false and false`, false},
+		{`not (false and false)`, true},
+		{`!true || true`, true},
+		{`!(true || true)`, false},
+		{`not not true`, true},
+		{`time.hour between 9 and 18 and subject.mfa == true`, true},
+		{`time.hour between 11 and 18 or subject.type == "user"`, true},
+		// short circuit: the right side is not evaluated
+		{`false and missing.attribute == 1`, false},
+		{`true or missing.attribute == 1`, true},
+	}
+	for _, tt := range tests {
+		condition, err := CompileCondition(tt.source)
+		if err != nil {
+			t.Errorf("CompileCondition(%q): %v", tt.source, err)
+			continue
+		}
+		got, err := condition.Evaluate(conditionAttributes())
+		if err != nil {
+			t.Errorf("Evaluate(%q): %v", tt.source, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Evaluate(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestConditionEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"unknown attribute", `subject.name == "x"`},
+		{"unknown namespace", `tenant.id == 1`},
+		{"path through a value", `subject.id.value == 1`},
+		{"ordering a number and a string", `time.hour < "10"`},
+		{"ordering booleans", `subject.mfa < true`},
+		{"between of mixed kinds", `time.hour between "9" and 18`},
+		{"in something other than a list", `subject.id in 7`},
+		{"ip in a block that is not one", `subject.type in 10.0.0.0/8`},
+		{"not a boolean", `subject.id`},
+		{"and of a number", `subject.id and true`},
+		{"not of a string", `not subject.type`},
+	}
+	for _, tt := range tests {
+		condition, err := CompileCondition(tt.source)
+		if err != nil {
+			t.Errorf("%s: CompileCondition(%q): %v", tt.name, tt.source, err)
+			continue
+		}
+		if got, err := condition.Evaluate(conditionAttributes()); err == nil {
+			t.Errorf("%s: Evaluate(%q) = %v, want an error", tt.name, tt.source, got)
+		}
+	}
+
+	// a mismatch of kinds is unequal rather than an error
+	for _, source := range []string{`subject.id == "7"`, `subject.mfa == 1`, `request.ip == null`} {
+		condition, err := CompileCondition(source)
+		if err != nil {
+			t.Fatalf("CompileCondition(%q): %v", source, err)
+		}
+		if got, err := condition.Evaluate(conditionAttributes()); err != nil || got {
+			t.Errorf("Evaluate(%q) = %v, %v, want false", source, got, err)
+		}
+	}
+}
+
+func TestCompileConditionRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"empty", ``},
+		{"unterminated string", `subject.type == "user`},
+		{"unexpected character", `subject.id == 7 ; true`},
+		{"trailing tokens", `true true`},
+		{"missing operand", `subject.id ==`},
+		{"missing parenthesis", `(true or false`},
+		{"stray parenthesis", `true)`},
+		{"unterminated list", `subject.id in [1, 2`},
+		{"list without commas", `subject.id in [1 2]`},
+		{"between without and", `time.hour between 9 18`},
+		{"keyword as operand", `and == 1`},
+		{"bad number", `subject.id == 1.2.3.4.5`},
+		{"bad address", `request.ip == 999.1.1.1`},
+		{"bad CIDR block", `request.ip in 10.0.0.0/99`},
+		{"empty path segment", `subject..id == 1`},
+		{"too long", `subject.id == 7` + strings.Repeat(" ", maxConditionLength)},
+		{"nested too deep with parentheses", strings.Repeat("(", maxConditionDepth+1) + "true" + strings.Repeat(")", maxConditionDepth+1)},
+		{"nested too deep with not", strings.Repeat("not ", maxConditionDepth+1) + "true"},
+		{"nested too deep with lists", `1 in ` + strings.Repeat("[", maxConditionDepth+1) + strings.Repeat("]", maxConditionDepth+1)},
+	}
+	for _, tt := range tests {
+		if _, err := CompileCondition(tt.source); !errors.Is(err, ErrInvalidCondition) {
+			t.Errorf("%s: CompileCondition(%q) = %v, want ErrInvalidCondition", tt.name, tt.source, err)
+		}
+	}
+
+	// the limits themselves are allowed
+	atDepth := strings.Repeat("(", maxConditionDepth-1) + "true" + strings.Repeat(")", maxConditionDepth-1)
+	if _, err := CompileCondition(atDepth); err != nil {
+		t.Errorf("CompileCondition at depth %d: %v", maxConditionDepth-1, err)
+	}
+	atLength := `subject.id == 7` + strings.Repeat(" ", maxConditionLength-len(`subject.id == 7`))
+	if _, err := CompileCondition(atLength); err != nil {
+		t.Errorf("CompileCondition of %d characters: %v", maxConditionLength, err)
+	}
+}
+
+func TestConditionHolds(t *testing.T) {
+	if holds, err := conditionHolds("  ", nil); err != nil || !holds {
+		t.Errorf("an empty condition = %v, %v, want it to hold", holds, err)
+	}
+	if holds, err := conditionHolds(`subject.id == 7`, nil); err == nil || holds {
+		t.Errorf("a condition without attributes = %v, %v, want an error", holds, err)
+	}
+}
+
+// FuzzCompileCondition checks that no input makes parsing or evaluating panic; the
+// seeds run with go test, go test -fuzz explores further.
+func FuzzCompileCondition(f *testing.F) {
+	for _, seed := range []string{
+		`request.ip in 10.0.0.0/8`,
+		`time.hour between 9 and 18 and time.weekday in [1, 2, 3, 4, 5]`,
+		`not (subject.id == 7 || resource.owner_id != subject.id)`,
+		`subject.id not in`,
+		`not`,
+		`[`,
+		`(`,
+		`"`,
+		`1..2`,
+		`-`,
+		`::1 in ::/0`,
+		`a.b.c.d between`,
+		`between and`,
+		"\x00\xff",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, source string) {
+		condition, err := CompileCondition(source)
+		if err != nil {
+			return
+		}
+		condition.Evaluate(conditionAttributes())
+		condition.Evaluate(nil)
+	})
+}
+
+func TestCompileConditionNeverPanics(t *testing.T) {
+	// every prefix of valid conditions is a malformed one
+	sources := []string{
+		`time.hour between 9 and 18 and time.weekday not in [1, 2, 3, 4, 5] or request.ip in 10.0.0.0/8`,
+		`!(subject.id == 7 && resource.owner_id != "x") || (null == null)`,
+	}
+	for _, source := range sources {
+		for end := 0; end <= len(source); end++ {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Errorf("CompileCondition(%q) panicked: %v", source[:end], r)
+					}
+				}()
+				if condition, err := CompileCondition(source[:end]); err == nil {
+					condition.Evaluate(conditionAttributes())
+				}
+			}()
+		}
+	}
+}
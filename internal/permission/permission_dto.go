@@ -1 +1,15 @@
 package permission
+
+type CreatePermissionRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description" validate:"required,max=255"`
+	Resource    string `json:"resource"`
+	Action      string `json:"action"`
+}
+
+type UpdatePermissionRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Resource    *string `json:"resource"`
+	Action      *string `json:"action"`
+}
@@ -1 +1,113 @@
 package permission
+
+import (
+	"errors"
+	utils "go_project_structure/utils"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type PermissionController struct {
+	PermissionService PermissionService
+}
+
+func NewPermissionController(_permissionService PermissionService) *PermissionController {
+	return &PermissionController{
+		PermissionService: _permissionService,
+	}
+}
+
+// permissionErrorStatus maps permission service errors to http status codes.
+func permissionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrPermissionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrPermissionNameTaken):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidPermissionName):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (pc *PermissionController) CreatePermission(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("create_permission_payload").(CreatePermissionRequest)
+
+	permission, err := pc.PermissionService.CreatePermission(
+		requestPayload.Name,
+		requestPayload.Description,
+		requestPayload.Resource,
+		requestPayload.Action,
+	)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, permissionErrorStatus(err), "Permission creation failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusCreated, "Permission created successfully", permission)
+}
+
+func (pc *PermissionController) GetPermissionById(w http.ResponseWriter, r *http.Request) {
+	permissionId := chi.URLParam(r, "id")
+
+	permission, err := pc.PermissionService.GetPermissionById(permissionId)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, permissionErrorStatus(err), "Permission fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get permission by id end point", permission)
+}
+
+func (pc *PermissionController) GetAllPermissions(w http.ResponseWriter, r *http.Request) {
+	resource := r.URL.Query().Get("resource")
+	action := r.URL.Query().Get("action")
+
+	permissions, err := pc.PermissionService.GetAllPermissions(resource, action)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, permissionErrorStatus(err), "Permission fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get all permissions end point", permissions)
+}
+
+func (pc *PermissionController) UpdatePermission(w http.ResponseWriter, r *http.Request) {
+	permissionId := chi.URLParam(r, "id")
+
+	requestPayload := r.Context().Value("update_permission_payload").(UpdatePermissionRequest)
+
+	message, err := pc.PermissionService.UpdatePermission(
+		permissionId,
+		requestPayload.Name,
+		requestPayload.Description,
+		requestPayload.Resource,
+		requestPayload.Action,
+	)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, permissionErrorStatus(err), "Permission update failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, message, nil)
+}
+
+func (pc *PermissionController) DeletePermission(w http.ResponseWriter, r *http.Request) {
+	permissionId := chi.URLParam(r, "id")
+
+	message, err := pc.PermissionService.DeletePermission(permissionId)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, permissionErrorStatus(err), "Permission delete failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, message, nil)
+}
+
+func (pc *PermissionController) PermanentlyDeletePermission(w http.ResponseWriter, r *http.Request) {
+	permissionId := chi.URLParam(r, "id")
+
+	message, err := pc.PermissionService.PermanentlyDeletePermission(permissionId)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, permissionErrorStatus(err), "Permission delete failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, message, nil)
+}
@@ -0,0 +1,180 @@
+package permission
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Wildcard stands for any resource or any action in a permission name.
+// "*" on its own is shorthand for "*:*" and grants everything.
+const Wildcard = "*"
+
+// Grant precedence. When several grants match the same request the most
+// specific one decides it, in this order:
+//
+//	SpecificityExact       user:read
+//	SpecificityAnyAction   user:*
+//	SpecificityAnyResource *:read
+//	SpecificityAll         *
+const (
+	SpecificityInvalid = iota - 1
+	SpecificityAll
+	SpecificityAnyResource
+	SpecificityAnyAction
+	SpecificityExact
+)
+
+// splitPermissionName returns the resource and action of a permission name.
+func splitPermissionName(name string) (string, string, bool) {
+	if name == Wildcard {
+		return Wildcard, Wildcard, true
+	}
+	resource, action, found := strings.Cut(name, ":")
+	if !found || resource == "" || action == "" {
+		return "", "", false
+	}
+	return resource, action, true
+}
+
+// FormatPermissionName builds the canonical name of a resource and action.
+func FormatPermissionName(resource string, action string) string {
+	if resource == Wildcard && action == Wildcard {
+		return Wildcard
+	}
+	return resource + ":" + action
+}
+
+// MatchPermission reports whether the granted permission covers the requested one.
+func MatchPermission(granted string, requested string) bool {
+	grantedResource, grantedAction, ok := splitPermissionName(granted)
+	if !ok {
+		return false
+	}
+	requestedResource, requestedAction, ok := splitPermissionName(requested)
+	if !ok {
+		return false
+	}
+	return (grantedResource == Wildcard || grantedResource == requestedResource) &&
+		(grantedAction == Wildcard || grantedAction == requestedAction)
+}
+
+// PermissionSpecificity ranks a granted permission name by how narrowly it applies.
+func PermissionSpecificity(granted string) int {
+	resource, action, ok := splitPermissionName(granted)
+	switch {
+	case !ok:
+		return SpecificityInvalid
+	case resource == Wildcard && action == Wildcard:
+		return SpecificityAll
+	case resource == Wildcard:
+		return SpecificityAnyResource
+	case action == Wildcard:
+		return SpecificityAnyAction
+	default:
+		return SpecificityExact
+	}
+}
+
+// CandidateGrants lists every permission name that could cover the requested one,
+// so callers can narrow a lookup before matching.
+func CandidateGrants(requested string) []string {
+	resource, action, ok := splitPermissionName(requested)
+	if !ok {
+		return []string{requested}
+	}
+
+	candidates := []string{}
+	seen := map[string]bool{}
+	for _, name := range []string{
+		requested,
+		FormatPermissionName(resource, Wildcard),
+		FormatPermissionName(Wildcard, action),
+		Wildcard,
+	} {
+		if !seen[name] {
+			seen[name] = true
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
+// MostSpecificMatch returns the granted permission with the highest precedence
+// that covers the requested one.
+func MostSpecificMatch(grants []string, requested string) (string, bool) {
+	best, bestSpecificity := "", SpecificityInvalid
+	for _, granted := range grants {
+		if !MatchPermission(granted, requested) {
+			continue
+		}
+		if specificity := PermissionSpecificity(granted); specificity > bestSpecificity {
+			best, bestSpecificity = granted, specificity
+		}
+	}
+	return best, bestSpecificity != SpecificityInvalid
+}
+
+// Effects a role can attach to a granted permission.
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Grant is a permission name held through a role, with the effect it was granted
+// with and an optional condition that has to hold for the grant to apply.
+type Grant struct {
+	Name      string
+	Effect    string
+	Condition string
+}
+
+// Decision is the outcome of evaluating grants against a requested permission.
+// Matched is the grant that decided it and is empty when nothing matched.
+type Decision struct {
+	Allowed bool
+	Matched Grant
+}
+
+// Decide evaluates grants with deny-overrides semantics: any deny grant that
+// covers the request refuses it, whatever allows also match. Otherwise the most
+// specific matching allow grant permits it, and with no match at all the request
+// is refused. Conditions are evaluated without attributes, so only grants whose
+// condition needs no attribute can permit a request.
+func Decide(grants []Grant, requested string) Decision {
+	return DecideWithAttributes(grants, requested, nil)
+}
+
+// DecideWithAttributes is Decide with grant conditions evaluated against the given
+// attributes. A grant whose condition does not hold is left out. A condition that
+// cannot be evaluated fails closed: the allow grant is left out, the deny grant applies.
+func DecideWithAttributes(grants []Grant, requested string, attributes Attributes) Decision {
+	allows := []Grant{}
+	names := []string{}
+	for _, grant := range grants {
+		if !MatchPermission(grant.Name, requested) {
+			continue
+		}
+		holds, err := conditionHolds(grant.Condition, attributes)
+		if err != nil {
+			fmt.Printf("Condition %q of grant %s could not be evaluated: %v\n", grant.Condition, grant.Name, err)
+			holds = grant.Effect == EffectDeny
+		}
+		if !holds {
+			continue
+		}
+		if grant.Effect == EffectDeny {
+			return Decision{Allowed: false, Matched: grant}
+		}
+		allows = append(allows, grant)
+		names = append(names, grant.Name)
+	}
+
+	if matched, ok := MostSpecificMatch(names, requested); ok {
+		for _, grant := range allows {
+			if grant.Name == matched {
+				return Decision{Allowed: true, Matched: grant}
+			}
+		}
+	}
+	return Decision{Allowed: false}
+}
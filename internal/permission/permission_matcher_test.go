@@ -0,0 +1,144 @@
+package permission
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchPermission(t *testing.T) {
+	tests := []struct {
+		granted   string
+		requested string
+		want      bool
+	}{
+		{"user:read", "user:read", true},
+		{"user:read", "user:update", false},
+		{"user:read", "role:read", false},
+		{"user:*", "user:read", true},
+		{"user:*", "user:delete", true},
+		{"user:*", "role:read", false},
+		{"*:read", "user:read", true},
+		{"*:read", "role:read", true},
+		{"*:read", "user:update", false},
+		{"*", "user:read", true},
+		{"*", "role:delete", true},
+		{"*:*", "user:read", true},
+		// a wildcard request is covered only by a grant at least as wide
+		{"user:*", "user:*", true},
+		{"*", "user:*", true},
+		{"user:read", "user:*", false},
+		{"*:read", "user:*", false},
+		{"user:*", "*", false},
+		// malformed names match nothing
+		{"user", "user:read", false},
+		{"user:read", "user", false},
+		{":read", "user:read", false},
+		{"user:", "user:read", false},
+		{"", "user:read", false},
+	}
+	for _, tt := range tests {
+		if got := MatchPermission(tt.granted, tt.requested); got != tt.want {
+			t.Errorf("MatchPermission(%q, %q) = %v, want %v", tt.granted, tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestPermissionSpecificity(t *testing.T) {
+	tests := []struct {
+		granted string
+		want    int
+	}{
+		{"user:read", SpecificityExact},
+		{"user:*", SpecificityAnyAction},
+		{"*:read", SpecificityAnyResource},
+		{"*", SpecificityAll},
+		{"*:*", SpecificityAll},
+		{"user", SpecificityInvalid},
+		{"", SpecificityInvalid},
+	}
+	for _, tt := range tests {
+		if got := PermissionSpecificity(tt.granted); got != tt.want {
+			t.Errorf("PermissionSpecificity(%q) = %d, want %d", tt.granted, got, tt.want)
+		}
+	}
+
+	if !(SpecificityExact > SpecificityAnyAction && SpecificityAnyAction > SpecificityAnyResource &&
+		SpecificityAnyResource > SpecificityAll && SpecificityAll > SpecificityInvalid) {
+		t.Errorf("specificities are out of order")
+	}
+}
+
+func TestCandidateGrants(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      []string
+	}{
+		{"user:read", []string{"user:read", "user:*", "*:read", "*"}},
+		{"user:*", []string{"user:*", "*"}},
+		{"*:read", []string{"*:read", "*"}},
+		{"*", []string{"*"}},
+		{"user", []string{"user"}},
+	}
+	for _, tt := range tests {
+		if got := CandidateGrants(tt.requested); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CandidateGrants(%q) = %v, want %v", tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestMostSpecificMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		grants    []string
+		requested string
+		want      string
+		wantOk    bool
+	}{
+		{"exact beats every wildcard", []string{"*", "*:read", "user:*", "user:read"}, "user:read", "user:read", true},
+		{"resource wildcard beats action wildcard", []string{"*", "*:read", "user:*"}, "user:read", "user:*", true},
+		{"action wildcard beats global wildcard", []string{"*", "*:read"}, "user:read", "*:read", true},
+		{"global wildcard", []string{"*"}, "user:read", "*", true},
+		{"non matching grants are skipped", []string{"role:*", "*:update", "user:update"}, "user:read", "", false},
+		{"malformed grants are skipped", []string{"user", ""}, "user:read", "", false},
+		{"no grants", nil, "user:read", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := MostSpecificMatch(tt.grants, tt.requested)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("%s: MostSpecificMatch(%v, %q) = %q, %v, want %q, %v", tt.name, tt.grants, tt.requested, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	allow := func(name string) Grant { return Grant{Name: name, Effect: EffectAllow} }
+	deny := func(name string) Grant { return Grant{Name: name, Effect: EffectDeny} }
+
+	tests := []struct {
+		name        string
+		grants      []Grant
+		requested   string
+		wantAllowed bool
+		wantMatched Grant
+	}{
+		{"no grants", nil, "user:read", false, Grant{}},
+		{"exact allow", []Grant{allow("user:read")}, "user:read", true, allow("user:read")},
+		{"resource wildcard allow", []Grant{allow("user:*")}, "user:read", true, allow("user:*")},
+		{"action wildcard allow", []Grant{allow("*:read")}, "user:read", true, allow("*:read")},
+		{"global wildcard allow", []Grant{allow("*")}, "user:read", true, allow("*")},
+		{"unrelated allow", []Grant{allow("role:read"), allow("user:update")}, "user:read", false, Grant{}},
+		{"most specific allow decides", []Grant{allow("*"), allow("user:*"), allow("user:read")}, "user:read", true, allow("user:read")},
+		{"exact deny", []Grant{deny("user:read")}, "user:read", false, deny("user:read")},
+		{"deny overrides a more specific allow", []Grant{allow("user:read"), deny("user:*")}, "user:read", false, deny("user:*")},
+		{"deny overrides whatever the order", []Grant{deny("*"), allow("user:read")}, "user:read", false, deny("*")},
+		{"deny of another action leaves the allow", []Grant{allow("user:*"), deny("user:delete")}, "user:read", true, allow("user:*")},
+		{"deny of another action applies to it", []Grant{allow("user:*"), deny("user:delete")}, "user:delete", false, deny("user:delete")},
+		{"conditional allow fails closed without attributes", []Grant{{Name: "user:read", Effect: EffectAllow, Condition: `request.ip == "10.0.0.1"`}}, "user:read", false, Grant{}},
+	}
+	for _, tt := range tests {
+		got := Decide(tt.grants, tt.requested)
+		if got.Allowed != tt.wantAllowed || got.Matched != tt.wantMatched {
+			t.Errorf("%s: Decide(%q) = %+v, want allowed %v matched %+v", tt.name, tt.requested, got, tt.wantAllowed, tt.wantMatched)
+		}
+	}
+}
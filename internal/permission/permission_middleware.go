@@ -1 +1,316 @@
-package permission
\ No newline at end of file
+package permission
+
+import (
+	"context"
+	"fmt"
+	"go_project_structure/internal/token"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Authorizer answers authorization questions about a user against the
+// user -> role -> permission graph. organizationId selects the tenant whose
+// role assignments apply on top of the global ones; 0 means global only.
+// attributes are what grant conditions are evaluated against.
+type Authorizer interface {
+	HasPermission(userId int64, organizationId int64, permissionName string, attributes Attributes) (bool, error)
+	HasResourcePermission(userId int64, organizationId int64, permissionName string, resourceId string, attributes Attributes) (bool, error)
+	HasAnyRole(userId int64, organizationId int64, roleNames []string) (bool, error)
+	IsOrganizationMember(userId int64, organizationId int64) (bool, error)
+}
+
+// SubjectResolver resolves the id of the authenticated caller of a request.
+type SubjectResolver func(r *http.Request) (int64, error)
+
+// OrganizationLookup reports whether a live organization with the given id exists.
+type OrganizationLookup func(organizationId int64) (bool, error)
+
+// OrganizationHeader selects the tenant of a request when the token does not carry one.
+const OrganizationHeader = "X-Organization-ID"
+
+// OrganizationOperatorPermission lets a caller act in organizations they hold no role in.
+const OrganizationOperatorPermission = "organization:read"
+
+type PermissionMiddleware struct {
+	authorizer         Authorizer
+	resolveSubject     SubjectResolver
+	organizationExists OrganizationLookup
+}
+
+func NewPermissionMiddleware(_authorizer Authorizer, _resolveSubject SubjectResolver, _organizationExists OrganizationLookup) *PermissionMiddleware {
+	return &PermissionMiddleware{
+		authorizer:         _authorizer,
+		resolveSubject:     _resolveSubject,
+		organizationExists: _organizationExists,
+	}
+}
+
+// TenantFromContext returns the organization validated by ResolveTenant, or 0 outside any tenant.
+func TenantFromContext(ctx context.Context) int64 {
+	organizationId, _ := ctx.Value("organization_id").(int64)
+	return organizationId
+}
+
+// ResolveTenant reads the tenant of the request from the token's org_id claim or the
+// X-Organization-ID header, checks that the caller may act in it and stores it in the
+// request context for the permission guards. Requests without a tenant stay global.
+func (pm *PermissionMiddleware) ResolveTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenOrganizationId, hasTokenTenant := r.Context().Value("token_organization_id").(int64)
+		header := strings.TrimSpace(r.Header.Get(OrganizationHeader))
+
+		organizationId := tokenOrganizationId
+		if header != "" {
+			headerOrganizationId, err := strconv.ParseInt(header, 10, 64)
+			if err != nil || headerOrganizationId <= 0 {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid organization", fmt.Errorf("%s must be a positive integer", OrganizationHeader))
+				return
+			}
+			if hasTokenTenant && headerOrganizationId != tokenOrganizationId {
+				utils.WriteJsonErrorResponse(w, http.StatusForbidden, "Forbidden", fmt.Errorf("%s does not match the organization of the token", OrganizationHeader))
+				return
+			}
+			organizationId = headerOrganizationId
+		}
+		if organizationId == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userId, err := pm.resolveSubject(r)
+		if err != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", err)
+			return
+		}
+
+		principal, _ := token.PrincipalFromContext(r.Context())
+		allowed, err := pm.MayActIn(principal, userId, organizationId, RequestAttributes(r, userId))
+		if err != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Organization check failed.", err)
+			return
+		}
+		if !allowed {
+			fmt.Printf("User %d may not act in organization %d\n", userId, organizationId)
+			utils.WriteJsonErrorResponse(w, http.StatusForbidden, "Forbidden", fmt.Errorf("not a member of organization %d", organizationId))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "organization_id", organizationId)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// MayActIn reports whether the user may act in a live organization: as a member of
+// it, or as an operator holding OrganizationOperatorPermission globally. principal is
+// the credential of the call, nil when there is none to limit it.
+func (pm *PermissionMiddleware) MayActIn(principal *token.Principal, userId int64, organizationId int64, attributes Attributes) (bool, error) {
+	exists, err := pm.organizationExists(organizationId)
+	if err != nil || !exists {
+		return false, err
+	}
+	allowed, err := pm.authorizer.IsOrganizationMember(userId, organizationId)
+	if err == nil && !allowed && (principal == nil || PrincipalAllows(principal, OrganizationOperatorPermission)) {
+		allowed, err = pm.authorizer.HasPermission(userId, 0, OrganizationOperatorPermission, attributes)
+	}
+	return allowed, err
+}
+
+// GlobalScope makes the guards after it judge the caller outside any tenant, after
+// ResolveTenant has validated it. Global resources such as users, organizations and
+// the permission catalogue are governed by global grants only: roles held inside an
+// organization are handed out by its admins and must not reach past it.
+func GlobalScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "organization_id", int64(0))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequirePermission only lets the request through when the caller holds the given permission.
+func (pm *PermissionMiddleware) RequirePermission(permissionName string) func(http.Handler) http.Handler {
+	return pm.RequireAnyPermission(permissionName)
+}
+
+// RequireAnyPermission lets the request through when the caller holds at least one of the given permissions.
+func (pm *PermissionMiddleware) RequireAnyPermission(permissionNames ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userId, err := pm.resolveSubject(r)
+			if err != nil {
+				utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", err)
+				return
+			}
+
+			attributes := RequestAttributes(r, userId)
+			for _, permissionName := range permissionNames {
+				if !credentialAllows(r, permissionName) {
+					continue
+				}
+				allowed, err := pm.authorizer.HasPermission(userId, TenantFromContext(r.Context()), permissionName, attributes)
+				if err != nil {
+					utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Permission check failed.", err)
+					return
+				}
+				if allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			fmt.Printf("User %d is missing permission %v\n", userId, permissionNames)
+			utils.WriteJsonErrorResponse(w, http.StatusForbidden, "Forbidden", fmt.Errorf("missing permission: %s", strings.Join(permissionNames, " or ")))
+		})
+	}
+}
+
+// RequireResourcePermission only lets the request through when the caller holds the
+// permission on the object whose id is in the urlParam path segment, through a role,
+// a grant on that object or by owning it.
+func (pm *PermissionMiddleware) RequireResourcePermission(permissionName string, urlParam string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userId, err := pm.resolveSubject(r)
+			if err != nil {
+				utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", err)
+				return
+			}
+
+			resourceId := chi.URLParam(r, urlParam)
+			allowed := false
+			if credentialAllows(r, permissionName) {
+				allowed, err = pm.authorizer.HasResourcePermission(userId, TenantFromContext(r.Context()), permissionName, resourceId, RequestAttributes(r, userId))
+			}
+			if err != nil {
+				utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Permission check failed.", err)
+				return
+			}
+			if !allowed {
+				fmt.Printf("User %d is missing permission %s on %s\n", userId, permissionName, resourceId)
+				utils.WriteJsonErrorResponse(w, http.StatusForbidden, "Forbidden", fmt.Errorf("missing permission: %s on %s", permissionName, resourceId))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole lets the request through when the caller holds at least one of the given roles.
+func (pm *PermissionMiddleware) RequireRole(roleNames ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userId, err := pm.resolveSubject(r)
+			if err != nil {
+				utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", err)
+				return
+			}
+
+			// a role stands for permissions a scoped credential may not hold
+			matched := false
+			if principal, ok := token.PrincipalFromContext(r.Context()); !ok || principal.Scopes == nil {
+				matched, err = pm.authorizer.HasAnyRole(userId, TenantFromContext(r.Context()), roleNames)
+			}
+			if err != nil {
+				utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Role check failed.", err)
+				return
+			}
+			if !matched {
+				fmt.Printf("User %d is missing role %v\n", userId, roleNames)
+				utils.WriteJsonErrorResponse(w, http.StatusForbidden, "Forbidden", fmt.Errorf("missing role: %s", strings.Join(roleNames, " or ")))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// credentialAllows reports whether the credential of the request may use the
+// permission at all; API keys are limited to their scopes.
+func credentialAllows(r *http.Request, permissionName string) bool {
+	principal, ok := token.PrincipalFromContext(r.Context())
+	return !ok || PrincipalAllows(principal, permissionName)
+}
+
+// PrincipalAllows reports whether the caller may use the permission at all: API
+// keys are limited to their scopes, which match like grants do, so a user:* scope
+// covers user:read. Access tokens are not limited. The role graph decides the rest.
+func PrincipalAllows(principal *token.Principal, permissionName string) bool {
+	if principal.Scopes == nil {
+		return true
+	}
+	for _, scope := range principal.Scopes {
+		if MatchPermission(scope, permissionName) {
+			return true
+		}
+	}
+	return false
+}
+
+func CreatePermissionRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = CreatePermissionRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("create permission payload received.")
+
+		RequestPayload.Name = strings.TrimSpace(RequestPayload.Name)
+		RequestPayload.Description = strings.TrimSpace(RequestPayload.Description)
+		if _, _, err := ParsePermissionName(RequestPayload.Name); err != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", err)
+			return
+		}
+		if RequestPayload.Description == "" || len(RequestPayload.Description) > 255 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("description is required and must be at most 255 characters"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "create_permission_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func UpdatePermissionRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = UpdatePermissionRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("update permission payload received.")
+
+		if RequestPayload.Name == nil && RequestPayload.Description == nil && RequestPayload.Resource == nil && RequestPayload.Action == nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("at least one field is required"))
+			return
+		}
+		if RequestPayload.Name != nil {
+			if _, _, err := ParsePermissionName(*RequestPayload.Name); err != nil {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", err)
+				return
+			}
+		}
+		if RequestPayload.Description != nil {
+			description := strings.TrimSpace(*RequestPayload.Description)
+			if description == "" || len(description) > 255 {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("description must be between 1 and 255 characters"))
+				return
+			}
+			RequestPayload.Description = &description
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "update_permission_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,100 @@
+package permission
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// fakeAuthorizer grants the permissions of grants[organizationId] on top of the global
+// grants[0], the way role assignments scoped to a tenant add to the global ones.
+type fakeAuthorizer struct {
+	grants  map[int64][]string
+	members map[int64]bool
+}
+
+func (f *fakeAuthorizer) allows(organizationId int64, permissionName string) bool {
+	scopes := []int64{0}
+	if organizationId != 0 {
+		scopes = append(scopes, organizationId)
+	}
+	for _, scope := range scopes {
+		for _, granted := range f.grants[scope] {
+			if MatchPermission(granted, permissionName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *fakeAuthorizer) HasPermission(userId int64, organizationId int64, permissionName string, attributes Attributes) (bool, error) {
+	return f.allows(organizationId, permissionName), nil
+}
+
+func (f *fakeAuthorizer) HasResourcePermission(userId int64, organizationId int64, permissionName string, resourceId string, attributes Attributes) (bool, error) {
+	return f.allows(organizationId, permissionName), nil
+}
+
+func (f *fakeAuthorizer) HasAnyRole(userId int64, organizationId int64, roleNames []string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeAuthorizer) IsOrganizationMember(userId int64, organizationId int64) (bool, error) {
+	return f.members[organizationId], nil
+}
+
+func newTestMiddleware(authorizer Authorizer) *PermissionMiddleware {
+	return NewPermissionMiddleware(authorizer,
+		func(r *http.Request) (int64, error) { return 7, nil },
+		func(organizationId int64) (bool, error) { return true, nil })
+}
+
+// serve runs a request acting in organization 5 through ResolveTenant, the guards and
+// a handler answering 200.
+func serve(pm *PermissionMiddleware, guards ...func(http.Handler) http.Handler) int {
+	router := chi.NewRouter()
+	router.Use(pm.ResolveTenant)
+	router.With(guards...).Patch("/resources/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	request := httptest.NewRequest(http.MethodPatch, "/resources/9", nil)
+	request.Header.Set(OrganizationHeader, "5")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder.Code
+}
+
+// TestGlobalScopeIgnoresTenantGrants shows that an admin of an organization, holding
+// every permission there, cannot reach global resources.
+func TestGlobalScopeIgnoresTenantGrants(t *testing.T) {
+	orgAdmin := newTestMiddleware(&fakeAuthorizer{grants: map[int64][]string{5: {"*"}}, members: map[int64]bool{5: true}})
+	globalAdmin := newTestMiddleware(&fakeAuthorizer{grants: map[int64][]string{0: {"*"}}, members: map[int64]bool{5: true}})
+
+	tests := []struct {
+		name   string
+		pm     *PermissionMiddleware
+		guards []func(http.Handler) http.Handler
+		want   int
+	}{
+		{"org admin on a tenant resource", orgAdmin, []func(http.Handler) http.Handler{orgAdmin.RequirePermission("role:update")}, http.StatusOK},
+		{"org admin on a global resource", orgAdmin, []func(http.Handler) http.Handler{GlobalScope, orgAdmin.RequirePermission("organization:delete")}, http.StatusForbidden},
+		{"org admin on another user", orgAdmin, []func(http.Handler) http.Handler{GlobalScope, orgAdmin.RequireResourcePermission("user:update", "id")}, http.StatusForbidden},
+		{"global admin on a global resource", globalAdmin, []func(http.Handler) http.Handler{GlobalScope, globalAdmin.RequirePermission("organization:delete")}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := serve(tt.pm, tt.guards...); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveTenantRefusesNonMembers(t *testing.T) {
+	outsider := newTestMiddleware(&fakeAuthorizer{grants: map[int64][]string{}, members: map[int64]bool{}})
+	if got := serve(outsider); got != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", got, http.StatusForbidden)
+	}
+}
@@ -6,8 +6,8 @@ import (
 
 type Permission struct {
 	gorm.Model
-	Name        string `gorm:"size:255;not null"`
+	Name        string `gorm:"size:255;not null;uniqueIndex:idx_permissions_name_live,where:deleted_at IS NULL"`
 	Description string `gorm:"size:255;not null"`
-	Resources   string `gorm:"size:100;not null"`
-	Action      string `gorm:"size:50;not null"`
+	Resource    string `gorm:"size:100;not null;index:idx_permissions_resource_action"`
+	Action      string `gorm:"size:50;not null;index:idx_permissions_resource_action"`
 }
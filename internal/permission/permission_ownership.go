@@ -0,0 +1,30 @@
+package permission
+
+import (
+	"sync"
+)
+
+// OwnershipRule reports whether a user owns one object of a resource type,
+// e.g. whether user 42 owns the user with id 17.
+type OwnershipRule func(userId int64, resourceId string) bool
+
+var (
+	ownershipMu    sync.RWMutex
+	ownershipRules = map[string]OwnershipRule{}
+)
+
+// RegisterOwnershipRule makes the owner of an object hold every permission on it,
+// unless a deny grant covers the permission. A later rule replaces an earlier one.
+func RegisterOwnershipRule(resource string, rule OwnershipRule) {
+	ownershipMu.Lock()
+	defer ownershipMu.Unlock()
+	ownershipRules[resource] = rule
+}
+
+// IsOwner applies the ownership rule registered for the resource type, if any.
+func IsOwner(userId int64, resource string, resourceId string) bool {
+	ownershipMu.RLock()
+	rule, ok := ownershipRules[resource]
+	ownershipMu.RUnlock()
+	return ok && rule(userId, resourceId)
+}
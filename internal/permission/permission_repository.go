@@ -1,6 +1,7 @@
 package permission
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 
@@ -9,14 +10,15 @@ import (
 )
 
 type PermissionRepository interface {
-	Create(name string, description string, resources string, action string) error
+	Create(name string, description string, resource string, action string) error
 	GetByID(id string) (*Permission, error)
 	GetAll() ([]*Permission, error)
-	Update(id string, name *string, description *string, resources *string, action *string) (string, error)
+	Update(id string, name *string, description *string, resource *string, action *string) (string, error)
 	SoftDelete(id string) (string, error)
 	HardDelete(id string) (string, error)
 
 	GetByName(name string) (*Permission, error)
+	GetAllByFilter(resource string, action string) ([]*Permission, error)
 }
 
 type PermissionRepositoryImpl struct {
@@ -30,7 +32,7 @@ func NewPermissionRepository(_db *gorm.DB) PermissionRepository {
 	}
 }
 
-func (u *PermissionRepositoryImpl) Create(name string, description string, resources string, action string) error {
+func (u *PermissionRepositoryImpl) Create(name string, description string, resource string, action string) error {
 	fmt.Println("creating permission in permission repository.")
 
 	// step 0: create a permission instance
@@ -40,10 +42,10 @@ func (u *PermissionRepositoryImpl) Create(name string, description string, resou
 	// }
 
 	// step 1: prepare the query
-	query := "INSERT INTO permissions (name, description, resources, action) VALUES (?, ?, ?, ?)"
+	query := "INSERT INTO permissions (name, description, resource, action) VALUES (?, ?, ?, ?)"
 
 	// step 2: execute the query
-	result := u.db.Exec(query, name, description, resources, action)
+	result := u.db.Exec(query, name, description, resource, action)
 
 	// step 3: check for errors
 	if result.Error != nil {
@@ -82,14 +84,14 @@ func (u *PermissionRepositoryImpl) GetByID(id string) (*Permission, error) {
 	fmt.Println("Fetching permission by id in permission repository.")
 
 	// step 1: prepare the query
-	query := "SELECT id, name, description, resources, action, created_at, updated_at FROM permissions WHERE deleted_at IS NULL AND id = ?"
+	query := "SELECT id, name, description, resource, action, created_at, updated_at FROM permissions WHERE deleted_at IS NULL AND id = ?"
 
 	// step 2: execute the query
 	row := u.db.Raw(query, id).Row()
 
 	// step 3: process the result
 	permission := &Permission{}
-	err := row.Scan(&permission.ID, &permission.Name, &permission.Description, &permission.Resources, &permission.Action, &permission.CreatedAt, &permission.UpdatedAt)
+	err := row.Scan(&permission.ID, &permission.Name, &permission.Description, &permission.Resource, &permission.Action, &permission.CreatedAt, &permission.UpdatedAt)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			fmt.Println("Permission not found.")
@@ -108,7 +110,7 @@ func (u *PermissionRepositoryImpl) GetAll() ([]*Permission, error) {
 	fmt.Println("Fetching all permissions in permission repository.")
 
 	// step 1: prepare the query
-	query := "SELECT id, name, description, resources, action, created_at, updated_at FROM permissions WHERE deleted_at IS NULL"
+	query := "SELECT id, name, description, resource, action, created_at, updated_at FROM permissions WHERE deleted_at IS NULL"
 
 	// step 2: execute the query
 	rows, err := u.db.Raw(query).Rows()
@@ -131,15 +133,9 @@ func (u *PermissionRepositoryImpl) GetAll() ([]*Permission, error) {
 	// }
 
 	// step 4: process the result
-	var permissions []*Permission
-	for rows.Next() {
-		var permission Permission
-		err := u.db.ScanRows(rows, &permission)
-		if err != nil {
-			fmt.Printf("Error scanning row: %v\n", err)
-			return nil, err
-		}
-		permissions = append(permissions, &permission)
+	permissions, err := scanPermissions(rows)
+	if err != nil {
+		return nil, err
 	}
 
 	// step 5: return the result
@@ -149,7 +145,7 @@ func (u *PermissionRepositoryImpl) GetAll() ([]*Permission, error) {
 	return permissions, nil
 }
 
-func (u *PermissionRepositoryImpl) Update(id string, name *string, description *string, resources *string, action *string) (string, error) {
+func (u *PermissionRepositoryImpl) Update(id string, name *string, description *string, resource *string, action *string) (string, error) {
 	fmt.Println("updating permission in permission repository.")
 
 	// step 1: prepare the query
@@ -163,9 +159,9 @@ func (u *PermissionRepositoryImpl) Update(id string, name *string, description *
 		query += "description = ?, "
 		args = append(args, *description)
 	}
-	if resources != nil {
-		query += "resources = ?, "
-		args = append(args, *resources)
+	if resource != nil {
+		query += "resource = ?, "
+		args = append(args, *resource)
 	}
 	if action != nil {
 		query += "action = ?, "
@@ -259,14 +255,14 @@ func (u *PermissionRepositoryImpl) GetByName(name string) (*Permission, error) {
 	fmt.Println("Fetching permission by id in permission repository.")
 
 	// step 1: prepare the query
-	query := "SELECT id, name, description, resources, action, created_at, updated_at FROM permissions WHERE deleted_at IS NULL AND name = ?"
+	query := "SELECT id, name, description, resource, action, created_at, updated_at FROM permissions WHERE deleted_at IS NULL AND name = ?"
 
 	// step 2: execute the query
 	row := u.db.Raw(query, name).Row()
 
 	// step 3: process the result
 	permission := &Permission{}
-	err := row.Scan(&permission.ID, &permission.Name, &permission.Description, &permission.Resources, &permission.Action, &permission.CreatedAt, &permission.UpdatedAt)
+	err := row.Scan(&permission.ID, &permission.Name, &permission.Description, &permission.Resource, &permission.Action, &permission.CreatedAt, &permission.UpdatedAt)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			fmt.Println("Permission not found.")
@@ -280,3 +276,46 @@ func (u *PermissionRepositoryImpl) GetByName(name string) (*Permission, error) {
 	fmt.Printf("Fetched permission: %+v\n", permission)
 	return permission, nil
 }
+
+// GetAllByFilter lists live permissions, narrowed by resource and/or action when they are not empty.
+func (u *PermissionRepositoryImpl) GetAllByFilter(resource string, action string) ([]*Permission, error) {
+	fmt.Println("Fetching filtered permissions in permission repository.")
+
+	// step 1: prepare the query
+	query := "SELECT id, name, description, resource, action, created_at, updated_at FROM permissions WHERE deleted_at IS NULL"
+	args := []interface{}{}
+	if resource != "" {
+		query += " AND resource = ?"
+		args = append(args, resource)
+	}
+	if action != "" {
+		query += " AND action = ?"
+		args = append(args, action)
+	}
+	query += " ORDER BY id"
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query, args...).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	return scanPermissions(rows)
+}
+
+func scanPermissions(rows *sql.Rows) ([]*Permission, error) {
+	permissions := []*Permission{}
+	for rows.Next() {
+		permission := &Permission{}
+		err := rows.Scan(&permission.ID, &permission.Name, &permission.Description, &permission.Resource, &permission.Action, &permission.CreatedAt, &permission.UpdatedAt)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, rows.Err()
+}
@@ -1 +1,201 @@
 package permission
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	ErrPermissionNotFound    = errors.New("permission not found")
+	ErrPermissionNameTaken   = errors.New("permission name already exists")
+	ErrInvalidPermissionName = errors.New("permission name must follow the resource:action convention")
+)
+
+// permissionSegment matches one side of a resource:action permission name, e.g. "user", "read" or "*".
+var permissionSegment = regexp.MustCompile(`^([a-z][a-z0-9_-]*|\*)$`)
+
+// ParsePermissionName splits a resource:action permission name into its resource and action.
+// Either side may be the wildcard, and "*" alone is the canonical form of "*:*".
+func ParsePermissionName(name string) (string, string, error) {
+	resource, action, ok := splitPermissionName(name)
+	if !ok || !permissionSegment.MatchString(resource) || !permissionSegment.MatchString(action) {
+		return "", "", ErrInvalidPermissionName
+	}
+	if name != FormatPermissionName(resource, action) {
+		return "", "", fmt.Errorf("%w: use %s instead of %s", ErrInvalidPermissionName, FormatPermissionName(resource, action), name)
+	}
+	return resource, action, nil
+}
+
+type PermissionService interface {
+	CreatePermission(name string, description string, resource string, action string) (*Permission, error)
+	GetPermissionById(id string) (*Permission, error)
+	GetAllPermissions(resource string, action string) ([]*Permission, error)
+	UpdatePermission(id string, name *string, description *string, resource *string, action *string) (string, error)
+	DeletePermission(id string) (string, error)
+	PermanentlyDeletePermission(id string) (string, error)
+}
+
+type PermissionServiceImpl struct {
+	permissionRepository PermissionRepository
+}
+
+func NewPermissionService(_permissionRepository PermissionRepository) PermissionService {
+	return &PermissionServiceImpl{
+		permissionRepository: _permissionRepository,
+	}
+}
+
+func (ps *PermissionServiceImpl) CreatePermission(name string, description string, resource string, action string) (*Permission, error) {
+	fmt.Println("Creating permission in permission service.")
+
+	parsedResource, parsedAction, err := ParsePermissionName(name)
+	if err != nil {
+		return nil, err
+	}
+	if (resource != "" && resource != parsedResource) || (action != "" && action != parsedAction) {
+		return nil, fmt.Errorf("%w: name %s does not match resource %q and action %q", ErrInvalidPermissionName, name, resource, action)
+	}
+
+	if err := ps.ensureNameAvailable(name, ""); err != nil {
+		return nil, err
+	}
+
+	err = ps.permissionRepository.Create(name, description, parsedResource, parsedAction)
+	if err != nil {
+		fmt.Printf("Error creating permission: %v\n", err)
+		if err.Error() == "unique constraint violation" {
+			return nil, ErrPermissionNameTaken
+		}
+		return nil, err
+	}
+
+	permission, err := ps.permissionRepository.GetByName(name)
+	if err != nil {
+		fmt.Printf("Error fetching created permission: %v\n", err)
+		return nil, err
+	}
+	return permission, nil
+}
+
+func (ps *PermissionServiceImpl) GetPermissionById(id string) (*Permission, error) {
+	fmt.Println("Getting permission by id in permission service.")
+	permission, err := ps.permissionRepository.GetByID(id)
+	if err != nil {
+		fmt.Printf("Error fetching permission by id: %v\n", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPermissionNotFound
+		}
+		return nil, err
+	}
+	return permission, nil
+}
+
+func (ps *PermissionServiceImpl) GetAllPermissions(resource string, action string) ([]*Permission, error) {
+	fmt.Println("Getting all permissions in permission service.")
+	permissions, err := ps.permissionRepository.GetAllByFilter(resource, action)
+	if err != nil {
+		fmt.Printf("Error fetching permissions: %v\n", err)
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// UpdatePermission keeps name, resource and action consistent: a new name moves the
+// permission to the resource and action it spells, and a new resource or action renames it.
+func (ps *PermissionServiceImpl) UpdatePermission(id string, name *string, description *string, resource *string, action *string) (string, error) {
+	fmt.Println("Updating permission in permission service.")
+
+	existing, err := ps.GetPermissionById(id)
+	if err != nil {
+		return "", err
+	}
+
+	var newName, newResource, newAction *string
+	if name != nil || resource != nil || action != nil {
+		mergedResource, mergedAction := existing.Resource, existing.Action
+		if name != nil {
+			parsedResource, parsedAction, err := ParsePermissionName(*name)
+			if err != nil {
+				return "", err
+			}
+			if (resource != nil && *resource != parsedResource) || (action != nil && *action != parsedAction) {
+				return "", fmt.Errorf("%w: name %s does not match the given resource and action", ErrInvalidPermissionName, *name)
+			}
+			mergedResource, mergedAction = parsedResource, parsedAction
+		} else {
+			if resource != nil {
+				mergedResource = *resource
+			}
+			if action != nil {
+				mergedAction = *action
+			}
+		}
+
+		mergedName := FormatPermissionName(mergedResource, mergedAction)
+		if _, _, err := ParsePermissionName(mergedName); err != nil {
+			return "", err
+		}
+		if mergedName != existing.Name {
+			if err := ps.ensureNameAvailable(mergedName, id); err != nil {
+				return "", err
+			}
+		}
+		newName, newResource, newAction = &mergedName, &mergedResource, &mergedAction
+	}
+
+	message, err := ps.permissionRepository.Update(id, newName, description, newResource, newAction)
+	if err != nil {
+		fmt.Printf("Error updating permission: %v\n", err)
+		if err.Error() == "No permission was updated." {
+			return "", ErrPermissionNotFound
+		}
+		return "", err
+	}
+	return message, nil
+}
+
+func (ps *PermissionServiceImpl) DeletePermission(id string) (string, error) {
+	fmt.Println("Deleting permission in permission service.")
+
+	message, err := ps.permissionRepository.SoftDelete(id)
+	if err != nil {
+		fmt.Printf("Error deleting permission: %v\n", err)
+		if err.Error() == "No permission was deleted." {
+			return "", ErrPermissionNotFound
+		}
+		return "", err
+	}
+	return message, nil
+}
+
+func (ps *PermissionServiceImpl) PermanentlyDeletePermission(id string) (string, error) {
+	fmt.Println("Permanently deleting permission in permission service.")
+
+	message, err := ps.permissionRepository.HardDelete(id)
+	if err != nil {
+		fmt.Printf("Error permanently deleting permission: %v\n", err)
+		if err.Error() == "No permission was deleted." {
+			return "", ErrPermissionNotFound
+		}
+		return "", err
+	}
+	return message, nil
+}
+
+// ensureNameAvailable fails when a live permission other than exceptId already uses the name.
+func (ps *PermissionServiceImpl) ensureNameAvailable(name string, exceptId string) error {
+	existing, err := ps.permissionRepository.GetByName(name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if fmt.Sprint(existing.ID) == exceptId {
+		return nil
+	}
+	return ErrPermissionNameTaken
+}
@@ -0,0 +1,24 @@
+package refreshtoken
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken is one opaque refresh token. Only its SHA-256 hash is stored. Every
+// token rotated from the same login shares a FamilyID; a token is used once, and
+// presenting a used token again revokes its whole family.
+type RefreshToken struct {
+	gorm.Model
+	UserID uint `gorm:"not null;index"`
+	// OrganizationID is the organization the access tokens issued with it are pinned to
+	OrganizationID *uint
+	// ClientID is the OIDC client the family was issued to, nil for a /login session
+	ClientID  *string   `gorm:"size:64"`
+	FamilyID  string    `gorm:"size:64;not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
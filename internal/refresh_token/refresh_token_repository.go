@@ -0,0 +1,219 @@
+package refreshtoken
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	Create(userId int64, organizationId int64, familyId string, tokenHash string, expiresAt time.Time) (*RefreshToken, error)
+	GetByHash(tokenHash string) (*RefreshToken, error)
+	Rotate(tokenHash string, newTokenHash string, expiresAt time.Time) (*RefreshToken, error)
+	SetFamilyClient(familyId string, clientId string) (int64, error)
+	RevokeFamily(familyId string) (int64, error)
+	RevokeUserTokens(userId int64) (int64, error)
+}
+
+type RefreshTokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(_db *gorm.DB) RefreshTokenRepository {
+	return &RefreshTokenRepositoryImpl{
+		db: _db,
+	}
+}
+
+const refreshTokenColumns = "t.id, t.user_id, t.organization_id, t.client_id, t.family_id, t.token_hash, t.expires_at, t.used_at, t.revoked_at, t.created_at, t.updated_at"
+
+func (u *RefreshTokenRepositoryImpl) Create(userId int64, organizationId int64, familyId string, tokenHash string, expiresAt time.Time) (*RefreshToken, error) {
+	fmt.Println("Creating refresh token in refreshToken repository.")
+
+	// step 1: prepare the query
+	query := `INSERT INTO refresh_tokens AS t (user_id, organization_id, family_id, token_hash, expires_at) VALUES (?, ?, ?, ?, ?)
+		RETURNING ` + refreshTokenColumns
+
+	// step 2: execute the query
+	row := u.db.Raw(query, userId, organizationScope(organizationId), familyId, tokenHash, expiresAt).Row()
+
+	// step 3: process the result
+	refreshToken, err := scanRefreshToken(row)
+	if err != nil {
+		fmt.Printf("Error creating refresh token: %v\n", err)
+		return nil, err
+	}
+
+	// step 4: return the result
+	fmt.Printf("Created refresh token %d in family %s for user %d\n", refreshToken.ID, familyId, userId)
+	return refreshToken, nil
+}
+
+// GetByHash returns the token with the given hash without using it up. A token that
+// is unknown, revoked or expired is refused with ErrRefreshTokenInvalid.
+func (u *RefreshTokenRepositoryImpl) GetByHash(tokenHash string) (*RefreshToken, error) {
+	fmt.Println("Fetching refresh token by hash in refreshToken repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + refreshTokenColumns + " FROM refresh_tokens t WHERE t.deleted_at IS NULL AND t.token_hash = ? AND t.revoked_at IS NULL AND t.expires_at > NOW()"
+
+	// step 2: execute the query
+	row := u.db.Raw(query, tokenHash).Row()
+
+	// step 3: process the result
+	refreshToken, err := scanRefreshToken(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		fmt.Printf("Error fetching refresh token: %v\n", err)
+		return nil, err
+	}
+
+	// step 4: return the result
+	return refreshToken, nil
+}
+
+// Rotate uses up the token with the given hash and issues its successor in the same
+// family. A token that is unknown, revoked or expired is refused with
+// ErrRefreshTokenInvalid. A token that was already used is refused with
+// ErrRefreshTokenReused after its whole family is revoked; the presented token is
+// returned with that error so the caller can tell whose family it was.
+func (u *RefreshTokenRepositoryImpl) Rotate(tokenHash string, newTokenHash string, expiresAt time.Time) (*RefreshToken, error) {
+	fmt.Println("Rotating refresh token in refreshToken repository.")
+
+	var presented, issued *RefreshToken
+	reused := false
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		// step 1: lock the presented token so concurrent rotations of it serialize
+		row := tx.Raw("SELECT "+refreshTokenColumns+" FROM refresh_tokens t WHERE t.deleted_at IS NULL AND t.token_hash = ? FOR UPDATE", tokenHash).Row()
+		var err error
+		presented, err = scanRefreshToken(row)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRefreshTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		// step 2: refuse tokens that may not be rotated
+		switch {
+		case presented.RevokedAt != nil:
+			return ErrRefreshTokenInvalid
+		case presented.UsedAt != nil:
+			// the token was stolen or replayed: nobody in its family can be trusted anymore
+			reused = true
+			return tx.Exec("UPDATE refresh_tokens SET revoked_at = NOW(), updated_at = NOW() WHERE family_id = ? AND revoked_at IS NULL", presented.FamilyID).Error
+		case !presented.ExpiresAt.After(time.Now()):
+			return ErrRefreshTokenInvalid
+		}
+
+		// step 3: use up the presented token and issue its successor
+		if err := tx.Exec("UPDATE refresh_tokens SET used_at = NOW(), updated_at = NOW() WHERE id = ?", presented.ID).Error; err != nil {
+			return err
+		}
+		// the successor stays bound to the client of the family
+		row = tx.Raw(`INSERT INTO refresh_tokens AS t (user_id, organization_id, client_id, family_id, token_hash, expires_at) VALUES (?, ?, ?, ?, ?, ?)
+			RETURNING `+refreshTokenColumns, presented.UserID, presented.OrganizationID, presented.ClientID, presented.FamilyID, newTokenHash, expiresAt).Row()
+		issued, err = scanRefreshToken(row)
+		return err
+	})
+
+	if err != nil {
+		fmt.Printf("Error rotating refresh token: %v\n", err)
+		return nil, err
+	}
+	if reused {
+		fmt.Printf("Refresh token %d was reused; revoked family %s\n", presented.ID, presented.FamilyID)
+		return presented, ErrRefreshTokenReused
+	}
+
+	fmt.Printf("Rotated refresh token %d to %d in family %s\n", presented.ID, issued.ID, issued.FamilyID)
+	return issued, nil
+}
+
+// SetFamilyClient records the OIDC client a family was issued to. A family keeps the
+// first client recorded for it, so a family issued to one client never moves to another.
+func (u *RefreshTokenRepositoryImpl) SetFamilyClient(familyId string, clientId string) (int64, error) {
+	fmt.Println("Setting refresh token family client in refreshToken repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE refresh_tokens SET client_id = ?, updated_at = NOW() WHERE deleted_at IS NULL AND family_id = ? AND client_id IS NULL"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, clientId, familyId)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error setting refresh token family client: %v\n", result.Error)
+		return 0, result.Error
+	}
+
+	// step 4: return the result
+	fmt.Printf("Bound %d refresh tokens in family %s to client %s\n", result.RowsAffected, familyId, clientId)
+	return result.RowsAffected, nil
+}
+
+// RevokeFamily revokes every live token rotated from the same login.
+func (u *RefreshTokenRepositoryImpl) RevokeFamily(familyId string) (int64, error) {
+	fmt.Println("Revoking refresh token family in refreshToken repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE refresh_tokens SET revoked_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND family_id = ? AND revoked_at IS NULL"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, familyId)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error revoking refresh token family: %v\n", result.Error)
+		return 0, result.Error
+	}
+
+	// step 4: return the result
+	fmt.Printf("Revoked %d refresh tokens in family %s\n", result.RowsAffected, familyId)
+	return result.RowsAffected, nil
+}
+
+// RevokeUserTokens revokes every live token of the user, ending all of their sessions.
+func (u *RefreshTokenRepositoryImpl) RevokeUserTokens(userId int64) (int64, error) {
+	fmt.Println("Revoking user refresh tokens in refreshToken repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE refresh_tokens SET revoked_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND user_id = ? AND revoked_at IS NULL"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, userId)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error revoking user refresh tokens: %v\n", result.Error)
+		return 0, result.Error
+	}
+
+	// step 4: return the result
+	fmt.Printf("Revoked %d refresh tokens of user %d\n", result.RowsAffected, userId)
+	return result.RowsAffected, nil
+}
+
+// organizationScope turns an organization id into the value stored in
+// refresh_tokens.organization_id, where tokens outside any organization are NULL.
+func organizationScope(organizationId int64) interface{} {
+	if organizationId == 0 {
+		return nil
+	}
+	return organizationId
+}
+
+func scanRefreshToken(row *sql.Row) (*RefreshToken, error) {
+	refreshToken := &RefreshToken{}
+	err := row.Scan(&refreshToken.ID, &refreshToken.UserID, &refreshToken.OrganizationID, &refreshToken.ClientID, &refreshToken.FamilyID, &refreshToken.TokenHash,
+		&refreshToken.ExpiresAt, &refreshToken.UsedAt, &refreshToken.RevokedAt, &refreshToken.CreatedAt, &refreshToken.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return refreshToken, nil
+}
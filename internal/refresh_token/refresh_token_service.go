@@ -0,0 +1,145 @@
+package refreshtoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	env "go_project_structure/config/env"
+	"go_project_structure/internal/events"
+	"time"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used; its session has been revoked")
+)
+
+// ReusedRefreshTokenEvent is published when a used refresh token is presented again.
+const ReusedRefreshTokenEvent = "refresh_token.reused"
+
+type RefreshTokenService interface {
+	Issue(userId int64, organizationId int64) (string, *RefreshToken, error)
+	Lookup(token string) (*RefreshToken, error)
+	Rotate(token string) (string, *RefreshToken, error)
+	BindFamily(familyId string, clientId string) error
+	RevokeFamily(familyId string) error
+	RevokeUserTokens(userId int64) error
+}
+
+type RefreshTokenServiceImpl struct {
+	refreshTokenRepository RefreshTokenRepository
+	bus                    *events.Bus
+}
+
+func NewRefreshTokenService(_refreshTokenRepository RefreshTokenRepository, _bus *events.Bus) RefreshTokenService {
+	return &RefreshTokenServiceImpl{
+		refreshTokenRepository: _refreshTokenRepository,
+		bus:                    _bus,
+	}
+}
+
+// RefreshTokenTTL is how long a refresh token can be rotated after it is issued.
+func RefreshTokenTTL() time.Duration {
+	return time.Duration(env.GetInt("REFRESH_TOKEN_TTL_HOURS", 720)) * time.Hour
+}
+
+// Issue starts a new token family for a login and returns its first token.
+func (ts *RefreshTokenServiceImpl) Issue(userId int64, organizationId int64) (string, *RefreshToken, error) {
+	fmt.Println("Issuing refresh token in refreshToken service.")
+	familyId, err := randomToken(16)
+	if err != nil {
+		return "", nil, err
+	}
+	token, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	refreshToken, err := ts.refreshTokenRepository.Create(userId, organizationId, familyId, hashToken(token), time.Now().Add(RefreshTokenTTL()))
+	if err != nil {
+		fmt.Printf("Error issuing refresh token: %v\n", err)
+		return "", nil, err
+	}
+	return token, refreshToken, nil
+}
+
+// Lookup returns the live token a refresh token is, without rotating it.
+func (ts *RefreshTokenServiceImpl) Lookup(token string) (*RefreshToken, error) {
+	fmt.Println("Looking up refresh token in refreshToken service.")
+	return ts.refreshTokenRepository.GetByHash(hashToken(token))
+}
+
+// Rotate exchanges a refresh token for its successor. Presenting a token that was
+// already used revokes its family and announces the reuse.
+func (ts *RefreshTokenServiceImpl) Rotate(token string) (string, *RefreshToken, error) {
+	fmt.Println("Rotating refresh token in refreshToken service.")
+	next, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	refreshToken, err := ts.refreshTokenRepository.Rotate(hashToken(token), hashToken(next), time.Now().Add(RefreshTokenTTL()))
+	if errors.Is(err, ErrRefreshTokenReused) {
+		ts.bus.Publish(events.Event{
+			Name: ReusedRefreshTokenEvent,
+			Data: map[string]interface{}{
+				"refresh_token_id": refreshToken.ID,
+				"user_id":          refreshToken.UserID,
+				"family_id":        refreshToken.FamilyID,
+			},
+		})
+		return "", nil, err
+	}
+	if err != nil {
+		fmt.Printf("Error rotating refresh token: %v\n", err)
+		return "", nil, err
+	}
+	return next, refreshToken, nil
+}
+
+// BindFamily ties a token family to the OIDC client it was issued to; the tokens
+// rotated from it later inherit the client.
+func (ts *RefreshTokenServiceImpl) BindFamily(familyId string, clientId string) error {
+	fmt.Println("Binding refresh token family in refreshToken service.")
+	if _, err := ts.refreshTokenRepository.SetFamilyClient(familyId, clientId); err != nil {
+		fmt.Printf("Error binding refresh token family: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func (ts *RefreshTokenServiceImpl) RevokeFamily(familyId string) error {
+	fmt.Println("Revoking refresh token family in refreshToken service.")
+	if _, err := ts.refreshTokenRepository.RevokeFamily(familyId); err != nil {
+		fmt.Printf("Error revoking refresh token family: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func (ts *RefreshTokenServiceImpl) RevokeUserTokens(userId int64) error {
+	fmt.Println("Revoking user refresh tokens in refreshToken service.")
+	if _, err := ts.refreshTokenRepository.RevokeUserTokens(userId); err != nil {
+		fmt.Printf("Error revoking user refresh tokens: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// randomToken returns size random bytes, URL-safe base64 encoded.
+func randomToken(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// hashToken is the form a refresh token is stored and looked up in.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,131 @@
+package refreshtoken
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go_project_structure/internal/events"
+)
+
+// fakeRefreshTokenRepository keeps tokens in memory and rotates them the way the
+// Rotate query does.
+type fakeRefreshTokenRepository struct {
+	RefreshTokenRepository
+	tokens []*RefreshToken
+}
+
+func (f *fakeRefreshTokenRepository) Create(userId int64, organizationId int64, familyId string, tokenHash string, expiresAt time.Time) (*RefreshToken, error) {
+	refreshToken := &RefreshToken{UserID: uint(userId), FamilyID: familyId, TokenHash: tokenHash, ExpiresAt: expiresAt}
+	refreshToken.ID = uint(len(f.tokens) + 1)
+	f.tokens = append(f.tokens, refreshToken)
+	return refreshToken, nil
+}
+
+func (f *fakeRefreshTokenRepository) Rotate(tokenHash string, newTokenHash string, expiresAt time.Time) (*RefreshToken, error) {
+	for _, presented := range f.tokens {
+		if presented.TokenHash != tokenHash {
+			continue
+		}
+		switch {
+		case presented.RevokedAt != nil:
+			return nil, ErrRefreshTokenInvalid
+		case presented.UsedAt != nil:
+			f.RevokeFamily(presented.FamilyID)
+			return presented, ErrRefreshTokenReused
+		case !presented.ExpiresAt.After(time.Now()):
+			return nil, ErrRefreshTokenInvalid
+		}
+		now := time.Now()
+		presented.UsedAt = &now
+		issued, _ := f.Create(int64(presented.UserID), 0, presented.FamilyID, newTokenHash, expiresAt)
+		return issued, nil
+	}
+	return nil, ErrRefreshTokenInvalid
+}
+
+func (f *fakeRefreshTokenRepository) RevokeFamily(familyId string) (int64, error) {
+	revoked := int64(0)
+	for _, refreshToken := range f.tokens {
+		if refreshToken.FamilyID == familyId && refreshToken.RevokedAt == nil {
+			now := time.Now()
+			refreshToken.RevokedAt = &now
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func TestRotate(t *testing.T) {
+	repository := &fakeRefreshTokenRepository{}
+	service := NewRefreshTokenService(repository, events.NewBus())
+
+	first, issued, err := service.Issue(7, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issued.TokenHash == first || issued.TokenHash != hashToken(first) {
+		t.Error("the token is not stored hashed")
+	}
+
+	second, rotated, err := service.Rotate(first)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if second == first || rotated.FamilyID != issued.FamilyID || rotated.UserID != 7 {
+		t.Errorf("Rotate = %+v, want a new token of family %s", rotated, issued.FamilyID)
+	}
+	if _, _, err := service.Rotate(second); err != nil {
+		t.Errorf("Rotate of the successor: %v", err)
+	}
+
+	if _, _, err := service.Rotate("unknown"); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Errorf("Rotate of an unknown token = %v, want ErrRefreshTokenInvalid", err)
+	}
+
+	expired, expiredToken, _ := service.Issue(7, 0)
+	expiredToken.ExpiresAt = time.Now().Add(-time.Minute)
+	if _, _, err := service.Rotate(expired); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Errorf("Rotate of an expired token = %v, want ErrRefreshTokenInvalid", err)
+	}
+}
+
+func TestRotateReuseRevokesFamily(t *testing.T) {
+	repository := &fakeRefreshTokenRepository{}
+	bus := events.NewBus()
+	published := []events.Event{}
+	bus.Subscribe(func(event events.Event) { published = append(published, event) })
+	service := NewRefreshTokenService(repository, bus)
+
+	first, issued, _ := service.Issue(7, 0)
+	other, _, _ := service.Issue(7, 0)
+	second, _, err := service.Rotate(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// replaying the rotated token revokes the whole family, the successor included
+	if _, _, err := service.Rotate(first); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Rotate of a used token = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, _, err := service.Rotate(second); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Errorf("Rotate of the successor after reuse = %v, want ErrRefreshTokenInvalid", err)
+	}
+	for _, refreshToken := range repository.tokens {
+		if refreshToken.FamilyID == issued.FamilyID && refreshToken.RevokedAt == nil {
+			t.Errorf("token %d of the family is not revoked", refreshToken.ID)
+		}
+	}
+
+	// other logins of the user keep working
+	if _, _, err := service.Rotate(other); err != nil {
+		t.Errorf("Rotate of another family: %v", err)
+	}
+
+	if len(published) != 1 || published[0].Name != ReusedRefreshTokenEvent {
+		t.Fatalf("published %v, want one %s event", published, ReusedRefreshTokenEvent)
+	}
+	if published[0].Data["family_id"] != issued.FamilyID || published[0].Data["user_id"] != uint(7) {
+		t.Errorf("event data = %v", published[0].Data)
+	}
+}
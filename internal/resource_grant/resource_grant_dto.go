@@ -0,0 +1,8 @@
+package resourcegrant
+
+type CreateResourceGrantRequest struct {
+	UserID       int64  `json:"user_id" validate:"required"`
+	PermissionID int64  `json:"permission_id" validate:"required"`
+	ResourceID   string `json:"resource_id" validate:"required,max=255"`
+	Effect       string `json:"effect" validate:"omitempty,oneof=allow deny"`
+}
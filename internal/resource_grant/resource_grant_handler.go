@@ -0,0 +1,95 @@
+package resourcegrant
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ResourceGrantController struct {
+	ResourceGrantService ResourceGrantService
+}
+
+func NewResourceGrantController(_resourceGrantService ResourceGrantService) *ResourceGrantController {
+	return &ResourceGrantController{
+		ResourceGrantService: _resourceGrantService,
+	}
+}
+
+// parseId reads a positive integer id from a raw url or query value.
+func parseId(value string, name string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return id, nil
+}
+
+func (gc *ResourceGrantController) GetGrants(w http.ResponseWriter, r *http.Request) {
+	var userId int64
+	if userIdParam := r.URL.Query().Get("user_id"); userIdParam != "" {
+		var err error
+		userId, err = parseId(userIdParam, "user_id")
+		if err != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid user id", err)
+			return
+		}
+	}
+
+	resourceGrants, err := gc.ResourceGrantService.GetGrants(userId, r.URL.Query().Get("resource_id"))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Resource grants fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get resource grants end point", resourceGrants)
+}
+
+func (gc *ResourceGrantController) GetGrantById(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid resource grant id", err)
+		return
+	}
+
+	resourceGrant, err := gc.ResourceGrantService.GetGrantById(id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			status = http.StatusNotFound
+		}
+		utils.WriteJsonErrorResponse(w, status, "Resource grant fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get resource grant by id end point", resourceGrant)
+}
+
+func (gc *ResourceGrantController) GrantPermission(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("create_resource_grant_payload").(CreateResourceGrantRequest)
+
+	resourceGrant, err := gc.ResourceGrantService.GrantPermission(requestPayload.UserID, requestPayload.PermissionID, requestPayload.ResourceID, requestPayload.Effect)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Resource permission grant failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Resource permission granted successfully", resourceGrant)
+}
+
+func (gc *ResourceGrantController) RevokeGrant(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid resource grant id", err)
+		return
+	}
+
+	err = gc.ResourceGrantService.RevokeGrant(id)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusNotFound, "Resource grant revoke failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Resource grant revoked successfully", nil)
+}
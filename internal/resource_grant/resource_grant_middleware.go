@@ -0,0 +1,44 @@
+package resourcegrant
+
+import (
+	"context"
+	"fmt"
+	"go_project_structure/internal/permission"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strings"
+)
+
+func CreateResourceGrantRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = CreateResourceGrantRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("create resource grant payload received.")
+
+		if RequestPayload.UserID <= 0 || RequestPayload.PermissionID <= 0 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("user_id and permission_id must be positive integers"))
+			return
+		}
+		RequestPayload.ResourceID = strings.TrimSpace(RequestPayload.ResourceID)
+		if RequestPayload.ResourceID == "" || len(RequestPayload.ResourceID) > 255 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("resource_id is required and must be at most 255 characters"))
+			return
+		}
+		if RequestPayload.Effect == "" {
+			RequestPayload.Effect = permission.EffectAllow
+		}
+		if RequestPayload.Effect != permission.EffectAllow && RequestPayload.Effect != permission.EffectDeny {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("effect must be either allow or deny"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "create_resource_grant_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
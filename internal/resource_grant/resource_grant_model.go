@@ -0,0 +1,16 @@
+package resourcegrant
+
+import (
+	"gorm.io/gorm"
+)
+
+// ResourceGrant gives one user a permission on a single object of the permission's
+// resource type, e.g. user:update on the user with id 17.
+type ResourceGrant struct {
+	gorm.Model
+	UserID       uint   `gorm:"not null;uniqueIndex:idx_resource_grants_user_permission_resource_live,where:deleted_at IS NULL;index:idx_resource_grants_user_resource,where:deleted_at IS NULL"`
+	PermissionID uint   `gorm:"not null;uniqueIndex:idx_resource_grants_user_permission_resource_live,where:deleted_at IS NULL"`
+	ResourceID   string `gorm:"size:255;not null;uniqueIndex:idx_resource_grants_user_permission_resource_live,where:deleted_at IS NULL;index:idx_resource_grants_user_resource,where:deleted_at IS NULL"`
+	// Effect is permission.EffectAllow or permission.EffectDeny; a deny overrides every matching allow.
+	Effect string `gorm:"size:10;not null;default:allow"`
+}
@@ -0,0 +1,197 @@
+package resourcegrant
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+type ResourceGrantRepository interface {
+	GetGrantById(id int64) (*ResourceGrant, error)
+	GetGrants(userId int64, resourceId string) ([]*ResourceGrant, error)
+	GrantPermission(userId int64, permissionId int64, resourceId string, effect string) (*ResourceGrant, error)
+	RevokeGrant(id int64) error
+}
+
+type ResourceGrantRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewResourceGrantRepository(_db *gorm.DB) ResourceGrantRepository {
+	return &ResourceGrantRepositoryImpl{
+		db: _db,
+	}
+}
+
+const resourceGrantColumns = "g.id, g.user_id, g.permission_id, g.resource_id, g.effect, g.created_at, g.updated_at"
+
+func (u *ResourceGrantRepositoryImpl) GetGrantById(id int64) (*ResourceGrant, error) {
+	fmt.Println("Fetching resource grant by id in resourceGrant repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + resourceGrantColumns + " FROM resource_grants g WHERE g.deleted_at IS NULL AND g.id = ?"
+
+	// step 2: execute the query
+	row := u.db.Raw(query, id).Row()
+
+	// step 3: process the result
+	resourceGrant, err := scanResourceGrant(row)
+	if err != nil {
+		fmt.Printf("Error fetching resource grant: %v\n", err)
+		return nil, err
+	}
+
+	// step 4: return the result
+	return resourceGrant, nil
+}
+
+// GetGrants lists live grants, narrowed by user and/or object when they are set.
+func (u *ResourceGrantRepositoryImpl) GetGrants(userId int64, resourceId string) ([]*ResourceGrant, error) {
+	fmt.Println("Fetching resource grants in resourceGrant repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + resourceGrantColumns + " FROM resource_grants g WHERE g.deleted_at IS NULL"
+	args := []interface{}{}
+	if userId > 0 {
+		query += " AND g.user_id = ?"
+		args = append(args, userId)
+	}
+	if resourceId != "" {
+		query += " AND g.resource_id = ?"
+		args = append(args, resourceId)
+	}
+	query += " ORDER BY g.id"
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query, args...).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	return scanResourceGrants(rows)
+}
+
+// GrantPermission gives the user the permission on one object. Granting it again
+// only aligns the effect of the live grant.
+func (u *ResourceGrantRepositoryImpl) GrantPermission(userId int64, permissionId int64, resourceId string, effect string) (*ResourceGrant, error) {
+	fmt.Println("granting resource permission in resourceGrant repository.")
+
+	var resourceGrant *ResourceGrant
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		// step 1: make sure the user and the permission are live
+		var exists bool
+		row := tx.Raw(`SELECT EXISTS (SELECT 1 FROM users WHERE deleted_at IS NULL AND id = ?)
+			AND EXISTS (SELECT 1 FROM permissions WHERE deleted_at IS NULL AND id = ?)`, userId, permissionId).Row()
+		if err := row.Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("user or permission not found")
+		}
+
+		// step 2: reuse the live grant if there is one, aligning its effect
+		row = tx.Raw(`UPDATE resource_grants g SET effect = ?, updated_at = NOW()
+			WHERE g.deleted_at IS NULL AND g.user_id = ? AND g.permission_id = ? AND g.resource_id = ?
+			RETURNING `+resourceGrantColumns, effect, userId, permissionId, resourceId).Row()
+		var err error
+		resourceGrant, err = scanResourceGrant(row)
+		if err == nil {
+			fmt.Println("Resource permission is already granted to user.")
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// step 3: insert a fresh grant
+		row = tx.Raw(`INSERT INTO resource_grants AS g (user_id, permission_id, resource_id, effect) VALUES (?, ?, ?, ?)
+			RETURNING `+resourceGrantColumns, userId, permissionId, resourceId, effect).Row()
+		resourceGrant, err = scanResourceGrant(row)
+		return err
+	})
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // unique_violation -> a concurrent request granted it first
+				fmt.Println("Resource permission was granted concurrently.")
+				grants, err := u.GetGrants(userId, resourceId)
+				if err != nil {
+					return nil, err
+				}
+				for _, grant := range grants {
+					if int64(grant.PermissionID) == permissionId {
+						return grant, nil
+					}
+				}
+				return nil, fmt.Errorf("unique constraint violation")
+			case "23503": // foreign_key_violation
+				return nil, fmt.Errorf("foreign key violation.")
+			case "23514": // check_violation
+				return nil, fmt.Errorf("invalid effect %q", effect)
+			default:
+				return nil, fmt.Errorf("database error: %v", pgErr.Message)
+			}
+		}
+		fmt.Printf("Error granting resource permission: %v\n", err)
+		return nil, err
+	}
+
+	fmt.Printf("Granted permission %d on %s to user %d with effect %s\n", permissionId, resourceId, userId, effect)
+	return resourceGrant, nil
+}
+
+func (u *ResourceGrantRepositoryImpl) RevokeGrant(id int64) error {
+	fmt.Println("revoking resource grant in resourceGrant repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE resource_grants SET deleted_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND id = ?"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, id)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error revoking resource grant: %v\n", result.Error)
+		return result.Error
+	}
+
+	// step 4: evaluate the result
+	if result.RowsAffected == 0 {
+		fmt.Println("No resource grant was revoked.")
+		return fmt.Errorf("No resource grant was revoked.")
+	}
+
+	// step 5: return the result
+	return nil
+}
+
+func scanResourceGrant(row *sql.Row) (*ResourceGrant, error) {
+	resourceGrant := &ResourceGrant{}
+	err := row.Scan(&resourceGrant.ID, &resourceGrant.UserID, &resourceGrant.PermissionID, &resourceGrant.ResourceID, &resourceGrant.Effect, &resourceGrant.CreatedAt, &resourceGrant.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return resourceGrant, nil
+}
+
+func scanResourceGrants(rows *sql.Rows) ([]*ResourceGrant, error) {
+	resourceGrants := []*ResourceGrant{}
+	for rows.Next() {
+		resourceGrant := &ResourceGrant{}
+		err := rows.Scan(&resourceGrant.ID, &resourceGrant.UserID, &resourceGrant.PermissionID, &resourceGrant.ResourceID, &resourceGrant.Effect, &resourceGrant.CreatedAt, &resourceGrant.UpdatedAt)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		resourceGrants = append(resourceGrants, resourceGrant)
+	}
+	return resourceGrants, rows.Err()
+}
@@ -0,0 +1,62 @@
+package resourcegrant
+
+import (
+	"fmt"
+)
+
+type ResourceGrantService interface {
+	GetGrantById(id int64) (*ResourceGrant, error)
+	GetGrants(userId int64, resourceId string) ([]*ResourceGrant, error)
+	GrantPermission(userId int64, permissionId int64, resourceId string, effect string) (*ResourceGrant, error)
+	RevokeGrant(id int64) error
+}
+
+type ResourceGrantServiceImpl struct {
+	resourceGrantRepository ResourceGrantRepository
+}
+
+func NewResourceGrantService(_resourceGrantRepository ResourceGrantRepository) ResourceGrantService {
+	return &ResourceGrantServiceImpl{
+		resourceGrantRepository: _resourceGrantRepository,
+	}
+}
+
+func (gs *ResourceGrantServiceImpl) GetGrantById(id int64) (*ResourceGrant, error) {
+	fmt.Println("Getting resource grant by id in resourceGrant service.")
+	resourceGrant, err := gs.resourceGrantRepository.GetGrantById(id)
+	if err != nil {
+		fmt.Printf("Error fetching resource grant: %v\n", err)
+		return nil, err
+	}
+	return resourceGrant, nil
+}
+
+func (gs *ResourceGrantServiceImpl) GetGrants(userId int64, resourceId string) ([]*ResourceGrant, error) {
+	fmt.Println("Getting resource grants in resourceGrant service.")
+	resourceGrants, err := gs.resourceGrantRepository.GetGrants(userId, resourceId)
+	if err != nil {
+		fmt.Printf("Error fetching resource grants: %v\n", err)
+		return nil, err
+	}
+	return resourceGrants, nil
+}
+
+func (gs *ResourceGrantServiceImpl) GrantPermission(userId int64, permissionId int64, resourceId string, effect string) (*ResourceGrant, error) {
+	fmt.Println("Granting resource permission in resourceGrant service.")
+	resourceGrant, err := gs.resourceGrantRepository.GrantPermission(userId, permissionId, resourceId, effect)
+	if err != nil {
+		fmt.Printf("Error granting resource permission: %v\n", err)
+		return nil, err
+	}
+	return resourceGrant, nil
+}
+
+func (gs *ResourceGrantServiceImpl) RevokeGrant(id int64) error {
+	fmt.Println("Revoking resource grant in resourceGrant service.")
+	err := gs.resourceGrantRepository.RevokeGrant(id)
+	if err != nil {
+		fmt.Printf("Error revoking resource grant: %v\n", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package revokedtoken
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RevokedToken is an entry of the access token denylist. With a TokenID it revokes
+// the token with that jti; without one it revokes every token of the user issued
+// before the entry was created. ExpiresAt is when every token the entry covers has
+// expired anyway, after which the entry can be dropped.
+type RevokedToken struct {
+	gorm.Model
+	TokenID   *string   `gorm:"size:64;uniqueIndex"`
+	UserID    uint      `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,111 @@
+package revokedtoken
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RevokedTokenRepository interface {
+	RevokeToken(tokenId string, userId int64, expiresAt time.Time) error
+	RevokeUserTokens(userId int64, expiresAt time.Time) error
+	IsRevoked(tokenId string, userId int64, issuedAt time.Time) (bool, error)
+	PruneExpired() (int64, error)
+}
+
+type RevokedTokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenRepository(_db *gorm.DB) RevokedTokenRepository {
+	return &RevokedTokenRepositoryImpl{
+		db: _db,
+	}
+}
+
+// RevokeToken denylists one access token until it expires. Revoking it again is a no-op.
+func (u *RevokedTokenRepositoryImpl) RevokeToken(tokenId string, userId int64, expiresAt time.Time) error {
+	fmt.Println("Revoking token in revokedToken repository.")
+
+	// step 1: prepare the query
+	query := "INSERT INTO revoked_tokens (token_id, user_id, expires_at) VALUES (?, ?, ?) ON CONFLICT (token_id) DO NOTHING"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, tokenId, userId, expiresAt)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error revoking token: %v\n", result.Error)
+		return result.Error
+	}
+
+	// step 4: return the result
+	fmt.Printf("Revoked token %s of user %d\n", tokenId, userId)
+	return nil
+}
+
+// RevokeUserTokens denylists every access token of the user issued so far. expiresAt
+// must be no earlier than the expiry of the last token issued before now.
+func (u *RevokedTokenRepositoryImpl) RevokeUserTokens(userId int64, expiresAt time.Time) error {
+	fmt.Println("Revoking user tokens in revokedToken repository.")
+
+	// step 1: prepare the query
+	query := "INSERT INTO revoked_tokens (token_id, user_id, expires_at) VALUES (NULL, ?, ?)"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, userId, expiresAt)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error revoking user tokens: %v\n", result.Error)
+		return result.Error
+	}
+
+	// step 4: return the result
+	fmt.Printf("Revoked every token of user %d\n", userId)
+	return nil
+}
+
+// IsRevoked reports whether the token is denylisted by its jti or by a revocation of
+// every token of its user. issuedAt only has second precision, so a token issued in
+// the same second as such a revocation counts as revoked too.
+func (u *RevokedTokenRepositoryImpl) IsRevoked(tokenId string, userId int64, issuedAt time.Time) (bool, error) {
+	// step 1: prepare the query
+	query := `SELECT EXISTS (
+		SELECT 1 FROM revoked_tokens t
+		WHERE t.deleted_at IS NULL AND t.expires_at > NOW()
+		AND (t.token_id = ? OR (t.token_id IS NULL AND t.user_id = ? AND t.created_at >= ?))
+	)`
+
+	// step 2: execute the query
+	row := u.db.Raw(query, tokenId, userId, issuedAt).Row()
+
+	// step 3: process the result
+	var revoked bool
+	if err := row.Scan(&revoked); err != nil {
+		fmt.Printf("Error checking token revocation: %v\n", err)
+		return false, err
+	}
+
+	// step 4: return the result
+	return revoked, nil
+}
+
+// PruneExpired drops the entries whose tokens have all expired.
+func (u *RevokedTokenRepositoryImpl) PruneExpired() (int64, error) {
+	// step 1: prepare the query
+	query := "DELETE FROM revoked_tokens WHERE expires_at <= NOW()"
+
+	// step 2: execute the query
+	result := u.db.Exec(query)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error pruning revoked tokens: %v\n", result.Error)
+		return 0, result.Error
+	}
+
+	// step 4: return the result
+	return result.RowsAffected, nil
+}
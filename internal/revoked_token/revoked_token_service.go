@@ -0,0 +1,125 @@
+package revokedtoken
+
+import (
+	"fmt"
+	"go_project_structure/internal/token"
+	"sync"
+	"time"
+)
+
+type RevokedTokenService interface {
+	RevokeToken(tokenId string, userId int64, expiresAt time.Time) error
+	RevokeUserTokens(userId int64) error
+	IsRevoked(claims *token.AccessClaims) (bool, error)
+}
+
+// RevokedTokenServiceImpl keeps the revocations it has seen in memory until the
+// tokens they cover expire, so a revoked token is refused without a query. Tokens
+// not known to be revoked are always checked against the database, which keeps
+// revocations made by other instances effective immediately.
+type RevokedTokenServiceImpl struct {
+	revokedTokenRepository RevokedTokenRepository
+
+	mu          sync.RWMutex
+	revokedIds  map[string]time.Time
+	userCutoffs map[int64]userCutoff
+}
+
+// userCutoff revokes the tokens of a user issued no later than revokedAt.
+type userCutoff struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+func NewRevokedTokenService(_revokedTokenRepository RevokedTokenRepository) RevokedTokenService {
+	return &RevokedTokenServiceImpl{
+		revokedTokenRepository: _revokedTokenRepository,
+		revokedIds:             map[string]time.Time{},
+		userCutoffs:            map[int64]userCutoff{},
+	}
+}
+
+func (rs *RevokedTokenServiceImpl) RevokeToken(tokenId string, userId int64, expiresAt time.Time) error {
+	fmt.Println("Revoking token in revokedToken service.")
+	if err := rs.revokedTokenRepository.RevokeToken(tokenId, userId, expiresAt); err != nil {
+		fmt.Printf("Error revoking token: %v\n", err)
+		return err
+	}
+	rs.remember(tokenId, expiresAt)
+	rs.prune()
+	return nil
+}
+
+// RevokeUserTokens revokes every access token issued to the user so far.
+func (rs *RevokedTokenServiceImpl) RevokeUserTokens(userId int64) error {
+	fmt.Println("Revoking user tokens in revokedToken service.")
+	now := time.Now()
+	expiresAt := now.Add(token.AccessTokenTTL())
+	if err := rs.revokedTokenRepository.RevokeUserTokens(userId, expiresAt); err != nil {
+		fmt.Printf("Error revoking user tokens: %v\n", err)
+		return err
+	}
+
+	rs.mu.Lock()
+	rs.userCutoffs[userId] = userCutoff{revokedAt: now, expiresAt: expiresAt}
+	rs.mu.Unlock()
+	rs.prune()
+	return nil
+}
+
+// IsRevoked is the token.RevocationCheck of the denylist.
+func (rs *RevokedTokenServiceImpl) IsRevoked(claims *token.AccessClaims) (bool, error) {
+	principal, err := claims.Principal()
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	rs.mu.RLock()
+	expiresAt, revokedId := rs.revokedIds[principal.TokenID]
+	cutoff, hasCutoff := rs.userCutoffs[principal.UserID]
+	rs.mu.RUnlock()
+	if revokedId && expiresAt.After(now) {
+		return true, nil
+	}
+	if hasCutoff && cutoff.expiresAt.After(now) && !principal.IssuedAt.After(cutoff.revokedAt) {
+		return true, nil
+	}
+
+	revoked, err := rs.revokedTokenRepository.IsRevoked(principal.TokenID, principal.UserID, principal.IssuedAt)
+	if err != nil {
+		return false, err
+	}
+	if revoked {
+		rs.remember(principal.TokenID, principal.ExpiresAt)
+	}
+	return revoked, nil
+}
+
+// remember caches a revoked token id until the token expires.
+func (rs *RevokedTokenServiceImpl) remember(tokenId string, expiresAt time.Time) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.revokedIds[tokenId] = expiresAt
+}
+
+// prune drops the cache entries and denylist rows of tokens that have expired.
+func (rs *RevokedTokenServiceImpl) prune() {
+	now := time.Now()
+	rs.mu.Lock()
+	for tokenId, expiresAt := range rs.revokedIds {
+		if !expiresAt.After(now) {
+			delete(rs.revokedIds, tokenId)
+		}
+	}
+	for userId, cutoff := range rs.userCutoffs {
+		if !cutoff.expiresAt.After(now) {
+			delete(rs.userCutoffs, userId)
+		}
+	}
+	rs.mu.Unlock()
+
+	if pruned, err := rs.revokedTokenRepository.PruneExpired(); err == nil && pruned > 0 {
+		fmt.Printf("Pruned %d expired revoked tokens\n", pruned)
+	}
+}
@@ -1 +1,19 @@
-package role
\ No newline at end of file
+package role
+
+type CreateRoleRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description" validate:"required,max=255"`
+	// OrganizationID makes the role belong to an organization; omit it for a global role
+	OrganizationID *int64 `json:"organization_id"`
+}
+
+type UpdateRoleRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	// RequireMfa makes the holders of the role log in with a second factor
+	RequireMfa *bool `json:"require_mfa"`
+}
+
+type AddParentRoleRequest struct {
+	ParentRoleID int64 `json:"parent_role_id" validate:"required"`
+}
@@ -1 +1,172 @@
-package role
\ No newline at end of file
+package role
+
+import (
+	"errors"
+	"fmt"
+	"go_project_structure/internal/permission"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RoleController struct {
+	RoleService RoleService
+}
+
+func NewRoleController(_roleService RoleService) *RoleController {
+	return &RoleController{
+		RoleService: _roleService,
+	}
+}
+
+// roleErrorStatus maps role service errors to http status codes.
+func roleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrRoleNotFound), errors.Is(err, ErrOrganizationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrRoleNameTaken), errors.Is(err, ErrRoleCycle), errors.Is(err, ErrRoleScope):
+		return http.StatusConflict
+	case errors.Is(err, ErrRoleOutsideOrganization):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (rc *RoleController) CreateRole(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("create_role_payload").(CreateRoleRequest)
+
+	// a caller acting in a tenant passed role:create there and may only add roles to
+	// it; outside any tenant they hold role:create globally and may add any role
+	if tenant := permission.TenantFromContext(r.Context()); tenant != 0 {
+		if requestPayload.OrganizationID == nil || *requestPayload.OrganizationID != tenant {
+			utils.WriteJsonErrorResponse(w, http.StatusForbidden, "Forbidden", ErrRoleOutsideOrganization)
+			return
+		}
+	}
+
+	role, err := rc.RoleService.CreateRole(requestPayload.Name, requestPayload.Description, requestPayload.OrganizationID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, roleErrorStatus(err), "Role creation failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusCreated, "Role created successfully", role)
+}
+
+func (rc *RoleController) GetRoleById(w http.ResponseWriter, r *http.Request) {
+	roleId := chi.URLParam(r, "id")
+
+	role, err := rc.RoleService.GetRoleById(roleId)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, roleErrorStatus(err), "Role fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get role by id end point", role)
+}
+
+func (rc *RoleController) GetAllRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := rc.RoleService.GetAllRoles()
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, roleErrorStatus(err), "Role fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get all roles end point", roles)
+}
+
+func (rc *RoleController) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	roleId := chi.URLParam(r, "id")
+
+	requestPayload := r.Context().Value("update_role_payload").(UpdateRoleRequest)
+
+	message, err := rc.RoleService.UpdateRole(roleId, requestPayload.Name, requestPayload.Description, requestPayload.RequireMfa, permission.TenantFromContext(r.Context()))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, roleErrorStatus(err), "Role update failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, message, nil)
+}
+
+func (rc *RoleController) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	roleId := chi.URLParam(r, "id")
+
+	message, err := rc.RoleService.DeleteRole(roleId, permission.TenantFromContext(r.Context()))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, roleErrorStatus(err), "Role delete failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, message, nil)
+}
+
+func (rc *RoleController) PermanentlyDeleteRole(w http.ResponseWriter, r *http.Request) {
+	roleId := chi.URLParam(r, "id")
+
+	message, err := rc.RoleService.PermanentlyDeleteRole(roleId, permission.TenantFromContext(r.Context()))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, roleErrorStatus(err), "Role delete failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, message, nil)
+}
+
+// parseRoleIdParam reads a positive integer role id from the chi url params.
+func parseRoleIdParam(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return id, nil
+}
+
+func (rc *RoleController) GetParentRoles(w http.ResponseWriter, r *http.Request) {
+	roleId, err := parseRoleIdParam(r, "id")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid role id", err)
+		return
+	}
+
+	roles, err := rc.RoleService.GetParentRoles(roleId)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, roleErrorStatus(err), "Parent roles fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get parent roles end point", roles)
+}
+
+func (rc *RoleController) AddParentRole(w http.ResponseWriter, r *http.Request) {
+	roleId, err := parseRoleIdParam(r, "id")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid role id", err)
+		return
+	}
+
+	requestPayload := r.Context().Value("add_parent_role_payload").(AddParentRoleRequest)
+
+	err = rc.RoleService.AddParentRole(roleId, requestPayload.ParentRoleID, permission.TenantFromContext(r.Context()))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, roleErrorStatus(err), "Parent role assignment failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Parent role added successfully", nil)
+}
+
+func (rc *RoleController) RemoveParentRole(w http.ResponseWriter, r *http.Request) {
+	roleId, err := parseRoleIdParam(r, "id")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid role id", err)
+		return
+	}
+	parentRoleId, err := parseRoleIdParam(r, "parentId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid parent role id", err)
+		return
+	}
+
+	err = rc.RoleService.RemoveParentRole(roleId, parentRoleId, permission.TenantFromContext(r.Context()))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, roleErrorStatus(err), "Parent role removal failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Parent role removed successfully", nil)
+}
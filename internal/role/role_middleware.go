@@ -1 +1,113 @@
-package role
\ No newline at end of file
+package role
+
+import (
+	"context"
+	"fmt"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strings"
+)
+
+const maxRoleFieldLength = 255
+
+func CreateRoleRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = CreateRoleRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("create role payload received.")
+
+		RequestPayload.Name = strings.TrimSpace(RequestPayload.Name)
+		RequestPayload.Description = strings.TrimSpace(RequestPayload.Description)
+		if err := validateRoleField("name", RequestPayload.Name); err != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", err)
+			return
+		}
+		if err := validateRoleField("description", RequestPayload.Description); err != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", err)
+			return
+		}
+		if RequestPayload.OrganizationID != nil && *RequestPayload.OrganizationID <= 0 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("organization_id must be a positive integer"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "create_role_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func UpdateRoleRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = UpdateRoleRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("update role payload received.")
+
+		if RequestPayload.Name == nil && RequestPayload.Description == nil && RequestPayload.RequireMfa == nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("at least one of name, description or require_mfa is required"))
+			return
+		}
+		if RequestPayload.Name != nil {
+			name := strings.TrimSpace(*RequestPayload.Name)
+			if err := validateRoleField("name", name); err != nil {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", err)
+				return
+			}
+			RequestPayload.Name = &name
+		}
+		if RequestPayload.Description != nil {
+			description := strings.TrimSpace(*RequestPayload.Description)
+			if err := validateRoleField("description", description); err != nil {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", err)
+				return
+			}
+			RequestPayload.Description = &description
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "update_role_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func AddParentRoleRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = AddParentRoleRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("add parent role payload received.")
+
+		if RequestPayload.ParentRoleID <= 0 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("parent_role_id must be a positive integer"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "add_parent_role_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validateRoleField(field string, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", field)
+	}
+	if len(value) > maxRoleFieldLength {
+		return fmt.Errorf("%s must be at most %d characters", field, maxRoleFieldLength)
+	}
+	return nil
+}
@@ -4,9 +4,22 @@ import (
 	"gorm.io/gorm"
 )
 
+// Role is global when OrganizationID is nil; otherwise it belongs to that
+// organization and can only be assigned within it. Names are unique per scope.
+// Holders of a role with RequireMfa, directly or through inheritance, must log in
+// with a second factor.
 type Role struct {
 	gorm.Model
-	Name string `gorm:"size:255;not null"`
-	Description string `gorm:"size:255;not null"`
-	
-}
\ No newline at end of file
+	Name           string `gorm:"size:255;not null;uniqueIndex:idx_roles_name_global_live,where:deleted_at IS NULL AND organization_id IS NULL;uniqueIndex:idx_roles_organization_name_live,priority:2,where:deleted_at IS NULL AND organization_id IS NOT NULL"`
+	Description    string `gorm:"size:255;not null"`
+	OrganizationID *uint  `gorm:"index;uniqueIndex:idx_roles_organization_name_live,priority:1,where:deleted_at IS NULL AND organization_id IS NOT NULL"`
+	RequireMfa     bool   `gorm:"not null;default:false"`
+}
+
+// RoleParent makes Role inherit every permission of ParentRole.
+// The edges form a DAG; cycles are rejected when an edge is written.
+type RoleParent struct {
+	gorm.Model
+	RoleID       uint `gorm:"not null;uniqueIndex:idx_role_parents_role_parent_live,where:deleted_at IS NULL"`
+	ParentRoleID uint `gorm:"not null;uniqueIndex:idx_role_parents_role_parent_live,where:deleted_at IS NULL"`
+}
@@ -1,6 +1,7 @@
 package role
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 
@@ -9,14 +10,19 @@ import (
 )
 
 type RoleRepository interface {
-	Create(name string, description string) error
+	Create(name string, description string, organizationId *int64) error
 	GetByID(id string) (*Role, error)
 	GetAll() ([]*Role, error)
-	Update(id string, name *string, description *string) (string, error)
+	Update(id string, name *string, description *string, requireMfa *bool) (string, error)
 	SoftDelete(id string) (string, error)
 	HardDelete(id string) (string, error)
 
-	GetByName(name string) (*Role, error)
+	GetByName(name string, organizationId *int64) (*Role, error)
+
+	// role hierarchy
+	GetParentRoles(roleId int64) ([]*Role, error)
+	AddParentRole(roleId int64, parentRoleId int64) error
+	RemoveParentRole(roleId int64, parentRoleId int64) error
 }
 
 type RoleRepositoryImpl struct {
@@ -30,7 +36,7 @@ func NewRoleRepository(_db *gorm.DB) RoleRepository {
 	}
 }
 
-func (u *RoleRepositoryImpl) Create(name string, description string) error {
+func (u *RoleRepositoryImpl) Create(name string, description string, organizationId *int64) error {
 	fmt.Println("creating role in role repository.")
 
 	// step 0: create a role instance
@@ -40,10 +46,13 @@ func (u *RoleRepositoryImpl) Create(name string, description string) error {
 	// }
 
 	// step 1: prepare the query
-	query := "INSERT INTO roles (name, description) VALUES (?, ?)"
+	// a role of an organization is only created while the organization is live
+	query := `INSERT INTO roles (name, description, organization_id)
+		SELECT ?, ?, ?
+		WHERE ? OR EXISTS (SELECT 1 FROM organizations WHERE deleted_at IS NULL AND id = ?)`
 
 	// step 2: execute the query
-	result := u.db.Exec(query, name, description)
+	result := u.db.Exec(query, name, description, organizationId, organizationId == nil, organizationId)
 
 	// step 3: check for errors
 	if result.Error != nil {
@@ -68,7 +77,7 @@ func (u *RoleRepositoryImpl) Create(name string, description string) error {
 	rowsAffected := result.RowsAffected
 	if rowsAffected == 0 {
 		fmt.Println("No role was created.")
-		return nil
+		return ErrOrganizationNotFound
 	}
 
 	fmt.Printf("Created role (rows affected: %d)\n",
@@ -82,14 +91,14 @@ func (u *RoleRepositoryImpl) GetByID(id string) (*Role, error) {
 	fmt.Println("Fetching role by id in role repository.")
 
 	// step 1: prepare the query
-	query := "SELECT id, name, description, created_at, updated_at FROM roles WHERE deleted_at IS NULL AND id = ?"
+	query := "SELECT id, name, description, organization_id, require_mfa, created_at, updated_at FROM roles WHERE deleted_at IS NULL AND id = ?"
 
 	// step 2: execute the query
 	row := u.db.Raw(query, id).Row()
 
 	// step 3: process the result
 	role := &Role{}
-	err := row.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt, &role.UpdatedAt)
+	err := row.Scan(&role.ID, &role.Name, &role.Description, &role.OrganizationID, &role.RequireMfa, &role.CreatedAt, &role.UpdatedAt)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			fmt.Println("Role not found.")
@@ -108,7 +117,7 @@ func (u *RoleRepositoryImpl) GetAll() ([]*Role, error) {
 	fmt.Println("Fetching all roles in role repository.")
 
 	// step 1: prepare the query
-	query := "SELECT id, name, description, created_at, updated_at FROM roles WHERE deleted_at IS NULL"
+	query := "SELECT id, name, description, organization_id, require_mfa, created_at, updated_at FROM roles WHERE deleted_at IS NULL"
 
 	// step 2: execute the query
 	rows, err := u.db.Raw(query).Rows()
@@ -149,7 +158,7 @@ func (u *RoleRepositoryImpl) GetAll() ([]*Role, error) {
 	return roles, nil
 }
 
-func (u *RoleRepositoryImpl) Update(id string, name *string, description *string) (string, error) {
+func (u *RoleRepositoryImpl) Update(id string, name *string, description *string, requireMfa *bool) (string, error) {
 	fmt.Println("updating role in role repository.")
 
 	// step 1: prepare the query
@@ -163,6 +172,10 @@ func (u *RoleRepositoryImpl) Update(id string, name *string, description *string
 		query += "description = ?, "
 		args = append(args, *description)
 	}
+	if requireMfa != nil {
+		query += "require_mfa = ?, "
+		args = append(args, *requireMfa)
+	}
 	query += "updated_at = NOW() "
 	query += "WHERE deleted_at IS NULL AND id = ?"
 	args = append(args, id)
@@ -247,18 +260,20 @@ func (u *RoleRepositoryImpl) HardDelete(id string) (string, error) {
 }
 
 
-func (u *RoleRepositoryImpl) GetByName(name string) (*Role, error) {
+// GetByName looks the name up among the roles of the organization, or among the
+// global roles when organizationId is nil.
+func (u *RoleRepositoryImpl) GetByName(name string, organizationId *int64) (*Role, error) {
 	fmt.Println("Fetching role by id in role repository.")
 
 	// step 1: prepare the query
-	query := "SELECT id, name, description, created_at, updated_at FROM roles WHERE deleted_at IS NULL AND name = ?"
+	query := "SELECT id, name, description, organization_id, require_mfa, created_at, updated_at FROM roles WHERE deleted_at IS NULL AND name = ? AND organization_id IS NOT DISTINCT FROM ?"
 
 	// step 2: execute the query
-	row := u.db.Raw(query, name).Row()
+	row := u.db.Raw(query, name, organizationId).Row()
 
 	// step 3: process the result
 	role := &Role{}
-	err := row.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt, &role.UpdatedAt)
+	err := row.Scan(&role.ID, &role.Name, &role.Description, &role.OrganizationID, &role.RequireMfa, &role.CreatedAt, &role.UpdatedAt)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			fmt.Println("Role not found.")
@@ -271,4 +286,138 @@ func (u *RoleRepositoryImpl) GetByName(name string) (*Role, error) {
 	// step 4: return the result
 	fmt.Printf("Fetched role: %+v\n", role)
 	return role, nil
-}
\ No newline at end of file
+}
+
+// role hierarchy related actions
+
+func (u *RoleRepositoryImpl) GetParentRoles(roleId int64) ([]*Role, error) {
+	fmt.Println("Fetching parent roles in role repository.")
+
+	// step 1: prepare the query
+	query := `SELECT r.id, r.name, r.description, r.organization_id, r.require_mfa, r.created_at, r.updated_at
+		FROM roles r
+		JOIN role_parents rp ON rp.parent_role_id = r.id AND rp.deleted_at IS NULL
+		WHERE r.deleted_at IS NULL AND rp.role_id = ?
+		ORDER BY r.id`
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query, roleId).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	return scanRoles(rows)
+}
+
+// AddParentRole makes roleId inherit the permissions of parentRoleId. Adding an
+// existing edge is a no-op, and an edge that would close a cycle is rejected.
+func (u *RoleRepositoryImpl) AddParentRole(roleId int64, parentRoleId int64) error {
+	fmt.Println("adding parent role in role repository.")
+
+	if roleId == parentRoleId {
+		return ErrRoleCycle
+	}
+
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		// step 1: serialize hierarchy writes so two concurrent edges cannot form a cycle together
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('role_parents'))").Error; err != nil {
+			return err
+		}
+
+		// step 2: make sure both roles are live
+		var exists bool
+		row := tx.Raw(`SELECT EXISTS (SELECT 1 FROM roles WHERE deleted_at IS NULL AND id = ?)
+			AND EXISTS (SELECT 1 FROM roles WHERE deleted_at IS NULL AND id = ?)`, roleId, parentRoleId).Row()
+		if err := row.Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrRoleNotFound
+		}
+
+		// a role may inherit from global roles and from roles of its own organization
+		var sameScope bool
+		row = tx.Raw(`SELECT parent.organization_id IS NULL OR parent.organization_id IS NOT DISTINCT FROM child.organization_id
+			FROM roles child, roles parent WHERE child.id = ? AND parent.id = ?`, roleId, parentRoleId).Row()
+		if err := row.Scan(&sameScope); err != nil {
+			return err
+		}
+		if !sameScope {
+			return ErrRoleScope
+		}
+
+		// step 3: reject the edge when the role is already an ancestor of the new parent
+		var cyclic bool
+		row = tx.Raw(`WITH RECURSIVE ancestors(role_id) AS (
+				SELECT parent_role_id FROM role_parents WHERE deleted_at IS NULL AND role_id = ?
+				UNION
+				SELECT rp.parent_role_id FROM role_parents rp
+				JOIN ancestors a ON rp.role_id = a.role_id
+				WHERE rp.deleted_at IS NULL
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors WHERE role_id = ?)`, parentRoleId, roleId).Row()
+		if err := row.Scan(&cyclic); err != nil {
+			return err
+		}
+		if cyclic {
+			fmt.Printf("Role %d is already an ancestor of role %d\n", roleId, parentRoleId)
+			return ErrRoleCycle
+		}
+
+		// step 4: insert the edge unless it is already there
+		result := tx.Exec(`INSERT INTO role_parents (role_id, parent_role_id)
+			SELECT ?, ?
+			WHERE NOT EXISTS (
+				SELECT 1 FROM role_parents WHERE deleted_at IS NULL AND role_id = ? AND parent_role_id = ?
+			)`, roleId, parentRoleId, roleId, parentRoleId)
+		if result.Error != nil {
+			fmt.Printf("Error adding parent role: %v\n", result.Error)
+			return result.Error
+		}
+
+		fmt.Printf("Role %d inherits from role %d (rows affected: %d)\n", roleId, parentRoleId, result.RowsAffected)
+		return nil
+	})
+}
+
+func (u *RoleRepositoryImpl) RemoveParentRole(roleId int64, parentRoleId int64) error {
+	fmt.Println("removing parent role in role repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE role_parents SET deleted_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND role_id = ? AND parent_role_id = ?"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, roleId, parentRoleId)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error removing parent role: %v\n", result.Error)
+		return result.Error
+	}
+
+	// step 4: evaluate the result
+	if result.RowsAffected == 0 {
+		fmt.Println("No parent role was removed.")
+		return ErrRoleNotFound
+	}
+
+	// step 5: return the result
+	return nil
+}
+
+func scanRoles(rows *sql.Rows) ([]*Role, error) {
+	roles := []*Role{}
+	for rows.Next() {
+		role := &Role{}
+		err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.OrganizationID, &role.RequireMfa, &role.CreatedAt, &role.UpdatedAt)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
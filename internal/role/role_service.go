@@ -1 +1,241 @@
 package role
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrRoleNotFound  = errors.New("role not found")
+	ErrRoleNameTaken = errors.New("role name already exists")
+	ErrRoleCycle     = errors.New("role hierarchy must not contain cycles")
+	ErrRoleScope     = errors.New("a role can only inherit from global roles or roles of its own organization")
+	// ErrRoleOutsideOrganization refuses a caller acting in an organization a role
+	// that is not that organization's, global roles included
+	ErrRoleOutsideOrganization = errors.New("the role does not belong to the organization")
+
+	ErrOrganizationNotFound = errors.New("organization not found")
+)
+
+type RoleService interface {
+	CreateRole(name string, description string, organizationId *int64) (*Role, error)
+	GetRoleById(id string) (*Role, error)
+	GetAllRoles() ([]*Role, error)
+	UpdateRole(id string, name *string, description *string, requireMfa *bool, organizationId int64) (string, error)
+	DeleteRole(id string, organizationId int64) (string, error)
+	PermanentlyDeleteRole(id string, organizationId int64) (string, error)
+	EnsureRoleInOrganization(id string, organizationId int64) error
+
+	GetParentRoles(roleId int64) ([]*Role, error)
+	AddParentRole(roleId int64, parentRoleId int64, organizationId int64) error
+	RemoveParentRole(roleId int64, parentRoleId int64, organizationId int64) error
+}
+
+type RoleServiceImpl struct {
+	roleRepository RoleRepository
+}
+
+func NewRoleService(_roleRepository RoleRepository) RoleService {
+	return &RoleServiceImpl{
+		roleRepository: _roleRepository,
+	}
+}
+
+func (rs *RoleServiceImpl) CreateRole(name string, description string, organizationId *int64) (*Role, error) {
+	fmt.Println("Creating role in role service.")
+
+	if err := rs.ensureNameAvailable(name, organizationId, ""); err != nil {
+		return nil, err
+	}
+
+	err := rs.roleRepository.Create(name, description, organizationId)
+	if err != nil {
+		fmt.Printf("Error creating role: %v\n", err)
+		if err.Error() == "unique constraint violation" {
+			return nil, ErrRoleNameTaken
+		}
+		return nil, err
+	}
+
+	role, err := rs.roleRepository.GetByName(name, organizationId)
+	if err != nil {
+		fmt.Printf("Error fetching created role: %v\n", err)
+		return nil, err
+	}
+	return role, nil
+}
+
+func (rs *RoleServiceImpl) GetRoleById(id string) (*Role, error) {
+	fmt.Println("Getting role by id in role service.")
+	role, err := rs.roleRepository.GetByID(id)
+	if err != nil {
+		fmt.Printf("Error fetching role by id: %v\n", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+func (rs *RoleServiceImpl) GetAllRoles() ([]*Role, error) {
+	fmt.Println("Getting all roles in role service.")
+	roles, err := rs.roleRepository.GetAll()
+	if err != nil {
+		fmt.Printf("Error fetching all roles: %v\n", err)
+		return nil, err
+	}
+	return roles, nil
+}
+
+// EnsureRoleInOrganization refuses a role that does not belong to the organization
+// the caller acts in. A role assigned, granted to or edited inside an organization
+// only ever applies there; a global role would carry the change to every tenant.
+// Outside any organization (0) every role passes.
+func (rs *RoleServiceImpl) EnsureRoleInOrganization(id string, organizationId int64) error {
+	if organizationId == 0 {
+		return nil
+	}
+	role, err := rs.GetRoleById(id)
+	if err != nil {
+		return err
+	}
+	if role.OrganizationID == nil || int64(*role.OrganizationID) != organizationId {
+		fmt.Printf("Role %s is outside organization %d\n", id, organizationId)
+		return ErrRoleOutsideOrganization
+	}
+	return nil
+}
+
+func (rs *RoleServiceImpl) UpdateRole(id string, name *string, description *string, requireMfa *bool, organizationId int64) (string, error) {
+	fmt.Println("Updating role in role service.")
+
+	if err := rs.EnsureRoleInOrganization(id, organizationId); err != nil {
+		return "", err
+	}
+	if name != nil {
+		existing, err := rs.GetRoleById(id)
+		if err != nil {
+			return "", err
+		}
+		if err := rs.ensureNameAvailable(*name, organizationIdOf(existing), id); err != nil {
+			return "", err
+		}
+	}
+
+	message, err := rs.roleRepository.Update(id, name, description, requireMfa)
+	if err != nil {
+		fmt.Printf("Error updating role: %v\n", err)
+		if err.Error() == "No role was updated." {
+			return "", ErrRoleNotFound
+		}
+		return "", err
+	}
+	return message, nil
+}
+
+func (rs *RoleServiceImpl) DeleteRole(id string, organizationId int64) (string, error) {
+	fmt.Println("Deleting role in role service.")
+
+	if err := rs.EnsureRoleInOrganization(id, organizationId); err != nil {
+		return "", err
+	}
+
+	message, err := rs.roleRepository.SoftDelete(id)
+	if err != nil {
+		fmt.Printf("Error deleting role: %v\n", err)
+		if err.Error() == "No role was deleted." {
+			return "", ErrRoleNotFound
+		}
+		return "", err
+	}
+	return message, nil
+}
+
+func (rs *RoleServiceImpl) PermanentlyDeleteRole(id string, organizationId int64) (string, error) {
+	fmt.Println("Permanently deleting role in role service.")
+
+	if err := rs.EnsureRoleInOrganization(id, organizationId); err != nil {
+		return "", err
+	}
+
+	message, err := rs.roleRepository.HardDelete(id)
+	if err != nil {
+		fmt.Printf("Error permanently deleting role: %v\n", err)
+		if err.Error() == "No role was deleted." {
+			return "", ErrRoleNotFound
+		}
+		return "", err
+	}
+	return message, nil
+}
+
+func (rs *RoleServiceImpl) GetParentRoles(roleId int64) ([]*Role, error) {
+	fmt.Println("Getting parent roles in role service.")
+
+	if _, err := rs.GetRoleById(fmt.Sprint(roleId)); err != nil {
+		return nil, err
+	}
+
+	roles, err := rs.roleRepository.GetParentRoles(roleId)
+	if err != nil {
+		fmt.Printf("Error fetching parent roles: %v\n", err)
+		return nil, err
+	}
+	return roles, nil
+}
+
+// AddParentRole makes a role inherit from another. Inside an organization both have
+// to be its roles: inheriting from a global role such as admin would hand its grants
+// to whoever the organization assigns the role to.
+func (rs *RoleServiceImpl) AddParentRole(roleId int64, parentRoleId int64, organizationId int64) error {
+	fmt.Println("Adding parent role in role service.")
+	for _, id := range []int64{roleId, parentRoleId} {
+		if err := rs.EnsureRoleInOrganization(fmt.Sprint(id), organizationId); err != nil {
+			return err
+		}
+	}
+	err := rs.roleRepository.AddParentRole(roleId, parentRoleId)
+	if err != nil {
+		fmt.Printf("Error adding parent role: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func (rs *RoleServiceImpl) RemoveParentRole(roleId int64, parentRoleId int64, organizationId int64) error {
+	fmt.Println("Removing parent role in role service.")
+	if err := rs.EnsureRoleInOrganization(fmt.Sprint(roleId), organizationId); err != nil {
+		return err
+	}
+	err := rs.roleRepository.RemoveParentRole(roleId, parentRoleId)
+	if err != nil {
+		fmt.Printf("Error removing parent role: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// ensureNameAvailable fails when a live role of the same scope other than exceptId already uses the name.
+func (rs *RoleServiceImpl) ensureNameAvailable(name string, organizationId *int64, exceptId string) error {
+	existing, err := rs.roleRepository.GetByName(name, organizationId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if fmt.Sprint(existing.ID) == exceptId {
+		return nil
+	}
+	return ErrRoleNameTaken
+}
+
+func organizationIdOf(role *Role) *int64 {
+	if role.OrganizationID == nil {
+		return nil
+	}
+	organizationId := int64(*role.OrganizationID)
+	return &organizationId
+}
@@ -0,0 +1,125 @@
+package role
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// fakeRoleRepository holds roles by id and records which of them were written to.
+type fakeRoleRepository struct {
+	RoleRepository
+	roles   map[string]*Role
+	written []string
+}
+
+func (f *fakeRoleRepository) GetByID(id string) (*Role, error) {
+	if role, ok := f.roles[id]; ok {
+		return role, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeRoleRepository) GetByName(name string, organizationId *int64) (*Role, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeRoleRepository) Update(id string, name *string, description *string, requireMfa *bool) (string, error) {
+	f.written = append(f.written, id)
+	return "Role updated successfully.", nil
+}
+
+func (f *fakeRoleRepository) SoftDelete(id string) (string, error) {
+	f.written = append(f.written, id)
+	return "Role deleted successfully.", nil
+}
+
+func (f *fakeRoleRepository) HardDelete(id string) (string, error) {
+	f.written = append(f.written, id)
+	return "Role permanently deleted successfully.", nil
+}
+
+func (f *fakeRoleRepository) AddParentRole(roleId int64, parentRoleId int64) error {
+	f.written = append(f.written, fmt.Sprint(roleId))
+	return nil
+}
+
+func (f *fakeRoleRepository) RemoveParentRole(roleId int64, parentRoleId int64) error {
+	f.written = append(f.written, fmt.Sprint(roleId))
+	return nil
+}
+
+// newFakeRoleRepository knows the global admin role 1, role 2 of organization 5 and
+// role 3 of organization 6.
+func newFakeRoleRepository() *fakeRoleRepository {
+	organization := func(id uint) *uint { return &id }
+	return &fakeRoleRepository{roles: map[string]*Role{
+		"1": {Name: "admin"},
+		"2": {Name: "editor", OrganizationID: organization(5)},
+		"3": {Name: "editor", OrganizationID: organization(6)},
+	}}
+}
+
+// TestRoleServiceKeepsTenantsToTheirRoles shows that an admin acting in organization 5
+// can change its own roles only: not the global admin role, nor another tenant's.
+func TestRoleServiceKeepsTenantsToTheirRoles(t *testing.T) {
+	name := "renamed"
+	tests := []struct {
+		name           string
+		call           func(rs RoleService, organizationId int64) error
+		organizationId int64
+		wantErr        error
+	}{
+		{"update own role", func(rs RoleService, organizationId int64) error {
+			_, err := rs.UpdateRole("2", &name, nil, nil, organizationId)
+			return err
+		}, 5, nil},
+		{"update global role", func(rs RoleService, organizationId int64) error {
+			_, err := rs.UpdateRole("1", &name, nil, nil, organizationId)
+			return err
+		}, 5, ErrRoleOutsideOrganization},
+		{"update global role outside any tenant", func(rs RoleService, organizationId int64) error {
+			_, err := rs.UpdateRole("1", &name, nil, nil, organizationId)
+			return err
+		}, 0, nil},
+		{"update role of another tenant", func(rs RoleService, organizationId int64) error {
+			_, err := rs.UpdateRole("3", &name, nil, nil, organizationId)
+			return err
+		}, 5, ErrRoleOutsideOrganization},
+		{"delete global role", func(rs RoleService, organizationId int64) error {
+			_, err := rs.DeleteRole("1", organizationId)
+			return err
+		}, 5, ErrRoleOutsideOrganization},
+		{"permanently delete global role", func(rs RoleService, organizationId int64) error {
+			_, err := rs.PermanentlyDeleteRole("1", organizationId)
+			return err
+		}, 5, ErrRoleOutsideOrganization},
+		{"inherit from own role", func(rs RoleService, organizationId int64) error {
+			return rs.AddParentRole(2, 2, organizationId)
+		}, 5, nil},
+		{"inherit from global admin", func(rs RoleService, organizationId int64) error {
+			return rs.AddParentRole(2, 1, organizationId)
+		}, 5, ErrRoleOutsideOrganization},
+		{"make global admin inherit", func(rs RoleService, organizationId int64) error {
+			return rs.AddParentRole(1, 2, organizationId)
+		}, 5, ErrRoleOutsideOrganization},
+		{"detach parent of global role", func(rs RoleService, organizationId int64) error {
+			return rs.RemoveParentRole(1, 2, organizationId)
+		}, 5, ErrRoleOutsideOrganization},
+		{"unknown role", func(rs RoleService, organizationId int64) error {
+			_, err := rs.DeleteRole("42", organizationId)
+			return err
+		}, 5, ErrRoleNotFound},
+	}
+	for _, tt := range tests {
+		repository := newFakeRoleRepository()
+		err := tt.call(NewRoleService(repository), tt.organizationId)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil && len(repository.written) > 0 {
+			t.Errorf("%s: refused call still wrote roles %v", tt.name, repository.written)
+		}
+	}
+}
@@ -1 +1,8 @@
 package rolepermission
+
+type GrantPermissionRequest struct {
+	RoleID       int64  `json:"role_id" validate:"required"`
+	PermissionID int64  `json:"permission_id" validate:"required"`
+	Effect       string `json:"effect" validate:"omitempty,oneof=allow deny"`
+	Condition    string `json:"condition"`
+}
@@ -1 +1,108 @@
-package rolepermission
\ No newline at end of file
+package rolepermission
+
+import (
+	"errors"
+	"fmt"
+	"go_project_structure/internal/permission"
+	"go_project_structure/internal/role"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RolePermissionController struct {
+	RolePermissionService RolePermissionService
+}
+
+func NewRolePermissionController(_rolePermissionService RolePermissionService) *RolePermissionController {
+	return &RolePermissionController{
+		RolePermissionService: _rolePermissionService,
+	}
+}
+
+// parseId reads a positive integer id from a raw url or query value.
+func parseId(value string, name string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return id, nil
+}
+
+func (rc *RolePermissionController) GetRolePermissions(w http.ResponseWriter, r *http.Request) {
+	roleIdParam := r.URL.Query().Get("role_id")
+
+	var rolePermissions []*RolePermission
+	var err error
+	if roleIdParam == "" {
+		rolePermissions, err = rc.RolePermissionService.GetAllRolePermissions()
+	} else {
+		roleId, parseErr := parseId(roleIdParam, "role_id")
+		if parseErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid role id", parseErr)
+			return
+		}
+		rolePermissions, err = rc.RolePermissionService.GetRolePermissionsByRoleId(roleId)
+	}
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Role permissions fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get role permissions end point", rolePermissions)
+}
+
+func (rc *RolePermissionController) GetRolePermissionById(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid role permission id", err)
+		return
+	}
+
+	rolePermission, err := rc.RolePermissionService.GetRolePermissionById(id)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusNotFound, "Role permission fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get role permission by id end point", rolePermission)
+}
+
+func (rc *RolePermissionController) AddPermissionToRole(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("grant_permission_payload").(GrantPermissionRequest)
+
+	rolePermission, err := rc.RolePermissionService.AddPermissionToRole(requestPayload.RoleID, requestPayload.PermissionID, requestPayload.Effect, requestPayload.Condition, permission.TenantFromContext(r.Context()))
+	if errors.Is(err, role.ErrRoleOutsideOrganization) {
+		utils.WriteJsonErrorResponse(w, http.StatusForbidden, "Forbidden", err)
+		return
+	}
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Permission grant failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Permission granted to role successfully", rolePermission)
+}
+
+func (rc *RolePermissionController) RemovePermissionFromRole(w http.ResponseWriter, r *http.Request) {
+	roleId, err := parseId(chi.URLParam(r, "roleId"), "roleId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid role id", err)
+		return
+	}
+	permissionId, err := parseId(chi.URLParam(r, "permissionId"), "permissionId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid permission id", err)
+		return
+	}
+
+	err = rc.RolePermissionService.RemovePermissionFromRole(roleId, permissionId, permission.TenantFromContext(r.Context()))
+	if errors.Is(err, role.ErrRoleOutsideOrganization) {
+		utils.WriteJsonErrorResponse(w, http.StatusForbidden, "Forbidden", err)
+		return
+	}
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusNotFound, "Permission revoke failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Permission revoked from role successfully", nil)
+}
@@ -1 +1,42 @@
 package rolepermission
+
+import (
+	"context"
+	"fmt"
+	"go_project_structure/internal/permission"
+	utils "go_project_structure/utils"
+	"net/http"
+)
+
+func GrantPermissionRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = GrantPermissionRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("grant permission payload received.")
+
+		if RequestPayload.RoleID <= 0 || RequestPayload.PermissionID <= 0 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("role_id and permission_id must be positive integers"))
+			return
+		}
+		if RequestPayload.Effect == "" {
+			RequestPayload.Effect = permission.EffectAllow
+		}
+		if RequestPayload.Effect != permission.EffectAllow && RequestPayload.Effect != permission.EffectDeny {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("effect must be either allow or deny"))
+			return
+		}
+		if _, err := permission.CompileCondition(RequestPayload.Condition); RequestPayload.Condition != "" && err != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", err)
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "grant_permission_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
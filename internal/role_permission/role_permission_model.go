@@ -6,6 +6,11 @@ import (
 
 type RolePermission struct {
 	gorm.Model
-	RoleID      uint
-	PermissionID uint
+	RoleID       uint `gorm:"not null;uniqueIndex:idx_role_permissions_role_permission_live,where:deleted_at IS NULL"`
+	PermissionID uint `gorm:"not null;uniqueIndex:idx_role_permissions_role_permission_live,where:deleted_at IS NULL"`
+	// Effect is permission.EffectAllow or permission.EffectDeny; a deny overrides every matching allow.
+	Effect string `gorm:"size:10;not null;default:allow"`
+	// Condition is a permission condition expression that has to hold for the grant
+	// to apply, e.g. "request.ip in 10.0.0.0/8"; empty means the grant always applies.
+	Condition string `gorm:"not null;default:''"`
 }
@@ -1,6 +1,7 @@
 package rolepermission
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 
@@ -18,7 +19,7 @@ type RolePermissionRepository interface {
 
 	GetRolePermissionById(id int64) (*RolePermission, error)
 	GetRolePermissionByRoleId(roleId int64) ([]*RolePermission, error)
-	AddPermissionToRole(roleId int64, permissionId int64) (*RolePermission, error)
+	AddPermissionToRole(roleId int64, permissionId int64, effect string, condition string) (*RolePermission, error)
 	RemovePermissionFromRole(roleId int64, permissionId int64) error
 	GetAllRolePermissions() ([]*RolePermission, error)
 }
@@ -93,7 +94,7 @@ func (u *RolePermissionRepositoryImpl) GetByID(id string) (*RolePermission, erro
 
 	// step 3: process the result
 	rolePermission := &RolePermission{}
-	err := row.Scan(&rolePermission.ID, &rolePermission.PermissionID, &rolePermission.RoleID)
+	err := row.Scan(&rolePermission.ID, &rolePermission.RoleID, &rolePermission.PermissionID, &rolePermission.CreatedAt, &rolePermission.UpdatedAt)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			fmt.Println("RolePermission not found.")
@@ -254,22 +255,218 @@ func (u *RolePermissionRepositoryImpl) HardDelete(id string) (string, error) {
 
 
 // role-permission related actions
+
+// rolePermissionColumns is the column list every role-permission query scans with scanRolePermission.
+const rolePermissionColumns = "rp.id, rp.role_id, rp.permission_id, rp.effect, rp.condition, rp.created_at, rp.updated_at"
+
 func (u *RolePermissionRepositoryImpl) GetRolePermissionById(id int64) (*RolePermission, error) {
-	return nil, nil
+	fmt.Println("Fetching rolePermission by id in rolePermission repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + rolePermissionColumns + " FROM role_permissions rp WHERE rp.deleted_at IS NULL AND rp.id = ?"
+
+	// step 2: execute the query
+	row := u.db.Raw(query, id).Row()
+
+	// step 3: process the result
+	rolePermission, err := scanRolePermission(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Println("RolePermission not found.")
+			return nil, fmt.Errorf("role permission not found")
+		}
+		fmt.Printf("Error fetching rolePermission: %v\n", err)
+		return nil, err
+	}
+
+	// step 4: return the result
+	return rolePermission, nil
 }
 
 func (u *RolePermissionRepositoryImpl) GetRolePermissionByRoleId(roleId int64) ([]*RolePermission, error) {
-	return nil, nil
+	fmt.Println("Fetching rolePermissions by role id in rolePermission repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + rolePermissionColumns + `
+		FROM role_permissions rp
+		JOIN roles r ON r.id = rp.role_id AND r.deleted_at IS NULL
+		JOIN permissions p ON p.id = rp.permission_id AND p.deleted_at IS NULL
+		WHERE rp.deleted_at IS NULL AND rp.role_id = ?
+		ORDER BY rp.permission_id`
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query, roleId).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	return scanRolePermissions(rows)
 }
 
-func (u *RolePermissionRepositoryImpl) AddPermissionToRole(roleId int64, permissionId int64) (*RolePermission, error) {
-	return nil, nil
+// AddPermissionToRole grants (or, with EffectDeny, explicitly denies) a permission
+// to a role. Repeating a grant is a no-op that returns the existing row, changing
+// its effect or condition updates that row in place, and a grant that was previously revoked is
+// revived instead of inserting a new row.
+func (u *RolePermissionRepositoryImpl) AddPermissionToRole(roleId int64, permissionId int64, effect string, condition string) (*RolePermission, error) {
+	fmt.Println("adding permission to role in rolePermission repository.")
+
+	var rolePermission *RolePermission
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		// step 1: make sure both sides of the grant are live
+		var exists bool
+		row := tx.Raw(`SELECT EXISTS (SELECT 1 FROM roles WHERE deleted_at IS NULL AND id = ?)
+			AND EXISTS (SELECT 1 FROM permissions WHERE deleted_at IS NULL AND id = ?)`, roleId, permissionId).Row()
+		if err := row.Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("role or permission not found")
+		}
+
+		// step 2: reuse the live grant if there is one, aligning its effect and condition
+		row = tx.Raw(`UPDATE role_permissions rp SET effect = ?, condition = ?,
+				updated_at = CASE WHEN rp.effect = ? AND rp.condition = ? THEN rp.updated_at ELSE NOW() END
+			WHERE rp.deleted_at IS NULL AND rp.role_id = ? AND rp.permission_id = ?
+			RETURNING `+rolePermissionColumns, effect, condition, effect, condition, roleId, permissionId).Row()
+		var err error
+		rolePermission, err = scanRolePermission(row)
+		if err == nil {
+			fmt.Println("Permission is already granted to role.")
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// step 3: revive the most recently revoked grant
+		row = tx.Raw(`UPDATE role_permissions rp SET deleted_at = NULL, effect = ?, condition = ?, updated_at = NOW()
+			WHERE rp.id = (
+				SELECT id FROM role_permissions
+				WHERE deleted_at IS NOT NULL AND role_id = ? AND permission_id = ?
+				ORDER BY deleted_at DESC LIMIT 1
+			)
+			RETURNING `+rolePermissionColumns, effect, condition, roleId, permissionId).Row()
+		rolePermission, err = scanRolePermission(row)
+		if err == nil {
+			fmt.Println("Revived revoked permission grant.")
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// step 4: insert a fresh grant
+		row = tx.Raw(`INSERT INTO role_permissions AS rp (role_id, permission_id, effect, condition) VALUES (?, ?, ?, ?)
+			RETURNING `+rolePermissionColumns, roleId, permissionId, effect, condition).Row()
+		rolePermission, err = scanRolePermission(row)
+		return err
+	})
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // unique_violation -> a concurrent request granted it first
+				fmt.Println("Permission was granted concurrently.")
+				return u.getLiveRolePermission(roleId, permissionId)
+			case "23503": // foreign_key_violation
+				return nil, fmt.Errorf("foreign key violation.")
+			case "23514": // check_violation
+				return nil, fmt.Errorf("invalid effect %q", effect)
+			default:
+				return nil, fmt.Errorf("database error: %v", pgErr.Message)
+			}
+		}
+		fmt.Printf("Error adding permission to role: %v\n", err)
+		return nil, err
+	}
+
+	fmt.Printf("Granted permission %d to role %d with effect %s\n", permissionId, roleId, effect)
+	return rolePermission, nil
 }
 
 func (u *RolePermissionRepositoryImpl) RemovePermissionFromRole(roleId int64, permissionId int64) error {
+	fmt.Println("removing permission from role in rolePermission repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE role_permissions SET deleted_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND role_id = ? AND permission_id = ?"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, roleId, permissionId)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error removing permission from role: %v\n", result.Error)
+		return result.Error
+	}
+
+	// step 4: evaluate the result
+	if result.RowsAffected == 0 {
+		fmt.Println("No permission was removed from role.")
+		return fmt.Errorf("No permission was removed from role.")
+	}
+
+	fmt.Printf("Removed permission %d from role %d\n", permissionId, roleId)
+
+	// step 5: return the result
 	return nil
 }
 
 func (u *RolePermissionRepositoryImpl) GetAllRolePermissions() ([]*RolePermission, error) {
-	return nil, nil
+	fmt.Println("Fetching all live rolePermissions in rolePermission repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + rolePermissionColumns + `
+		FROM role_permissions rp
+		JOIN roles r ON r.id = rp.role_id AND r.deleted_at IS NULL
+		JOIN permissions p ON p.id = rp.permission_id AND p.deleted_at IS NULL
+		WHERE rp.deleted_at IS NULL
+		ORDER BY rp.role_id, rp.permission_id`
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	return scanRolePermissions(rows)
+}
+
+func (u *RolePermissionRepositoryImpl) getLiveRolePermission(roleId int64, permissionId int64) (*RolePermission, error) {
+	query := "SELECT " + rolePermissionColumns + " FROM role_permissions rp WHERE rp.deleted_at IS NULL AND rp.role_id = ? AND rp.permission_id = ?"
+	rolePermission, err := scanRolePermission(u.db.Raw(query, roleId, permissionId).Row())
+	if err != nil {
+		fmt.Printf("Error fetching rolePermission: %v\n", err)
+		return nil, err
+	}
+	return rolePermission, nil
+}
+
+func scanRolePermission(row *sql.Row) (*RolePermission, error) {
+	rolePermission := &RolePermission{}
+	err := row.Scan(&rolePermission.ID, &rolePermission.RoleID, &rolePermission.PermissionID, &rolePermission.Effect, &rolePermission.Condition, &rolePermission.CreatedAt, &rolePermission.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return rolePermission, nil
+}
+
+func scanRolePermissions(rows *sql.Rows) ([]*RolePermission, error) {
+	rolePermissions := []*RolePermission{}
+	for rows.Next() {
+		rolePermission := &RolePermission{}
+		err := rows.Scan(&rolePermission.ID, &rolePermission.RoleID, &rolePermission.PermissionID, &rolePermission.Effect, &rolePermission.Condition, &rolePermission.CreatedAt, &rolePermission.UpdatedAt)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		rolePermissions = append(rolePermissions, rolePermission)
+	}
+	return rolePermissions, rows.Err()
 }
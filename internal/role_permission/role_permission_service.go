@@ -1 +1,84 @@
 package rolepermission
+
+import (
+	"fmt"
+	"go_project_structure/internal/role"
+)
+
+type RolePermissionService interface {
+	GetRolePermissionById(id int64) (*RolePermission, error)
+	GetRolePermissionsByRoleId(roleId int64) ([]*RolePermission, error)
+	AddPermissionToRole(roleId int64, permissionId int64, effect string, condition string, organizationId int64) (*RolePermission, error)
+	RemovePermissionFromRole(roleId int64, permissionId int64, organizationId int64) error
+	GetAllRolePermissions() ([]*RolePermission, error)
+}
+
+type RolePermissionServiceImpl struct {
+	rolePermissionRepository RolePermissionRepository
+	roleService              role.RoleService
+}
+
+func NewRolePermissionService(_rolePermissionRepository RolePermissionRepository, _roleService role.RoleService) RolePermissionService {
+	return &RolePermissionServiceImpl{
+		rolePermissionRepository: _rolePermissionRepository,
+		roleService:              _roleService,
+	}
+}
+
+func (rs *RolePermissionServiceImpl) GetRolePermissionById(id int64) (*RolePermission, error) {
+	fmt.Println("Getting rolePermission by id in rolePermission service.")
+	rolePermission, err := rs.rolePermissionRepository.GetRolePermissionById(id)
+	if err != nil {
+		fmt.Printf("Error fetching rolePermission: %v\n", err)
+		return nil, err
+	}
+	return rolePermission, nil
+}
+
+func (rs *RolePermissionServiceImpl) GetRolePermissionsByRoleId(roleId int64) ([]*RolePermission, error) {
+	fmt.Println("Getting rolePermissions by role id in rolePermission service.")
+	rolePermissions, err := rs.rolePermissionRepository.GetRolePermissionByRoleId(roleId)
+	if err != nil {
+		fmt.Printf("Error fetching rolePermissions: %v\n", err)
+		return nil, err
+	}
+	return rolePermissions, nil
+}
+
+// AddPermissionToRole grants a permission to a role. Inside an organization only its
+// own roles can be granted to; a grant on a global role would reach every tenant.
+func (rs *RolePermissionServiceImpl) AddPermissionToRole(roleId int64, permissionId int64, effect string, condition string, organizationId int64) (*RolePermission, error) {
+	fmt.Println("Adding permission to role in rolePermission service.")
+	if err := rs.roleService.EnsureRoleInOrganization(fmt.Sprint(roleId), organizationId); err != nil {
+		return nil, err
+	}
+	rolePermission, err := rs.rolePermissionRepository.AddPermissionToRole(roleId, permissionId, effect, condition)
+	if err != nil {
+		fmt.Printf("Error adding permission to role: %v\n", err)
+		return nil, err
+	}
+	return rolePermission, nil
+}
+
+func (rs *RolePermissionServiceImpl) RemovePermissionFromRole(roleId int64, permissionId int64, organizationId int64) error {
+	fmt.Println("Removing permission from role in rolePermission service.")
+	if err := rs.roleService.EnsureRoleInOrganization(fmt.Sprint(roleId), organizationId); err != nil {
+		return err
+	}
+	err := rs.rolePermissionRepository.RemovePermissionFromRole(roleId, permissionId)
+	if err != nil {
+		fmt.Printf("Error removing permission from role: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func (rs *RolePermissionServiceImpl) GetAllRolePermissions() ([]*RolePermission, error) {
+	fmt.Println("Getting all rolePermissions in rolePermission service.")
+	rolePermissions, err := rs.rolePermissionRepository.GetAllRolePermissions()
+	if err != nil {
+		fmt.Printf("Error fetching rolePermissions: %v\n", err)
+		return nil, err
+	}
+	return rolePermissions, nil
+}
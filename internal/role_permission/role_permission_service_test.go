@@ -0,0 +1,67 @@
+package rolepermission
+
+import (
+	"errors"
+	"testing"
+
+	"go_project_structure/internal/role"
+)
+
+// fakeRoleService places role 1 globally and role 2 in organization 5.
+type fakeRoleService struct {
+	role.RoleService
+}
+
+func (f *fakeRoleService) EnsureRoleInOrganization(id string, organizationId int64) error {
+	if organizationId == 0 || (id == "2" && organizationId == 5) {
+		return nil
+	}
+	return role.ErrRoleOutsideOrganization
+}
+
+type fakeRolePermissionRepository struct {
+	RolePermissionRepository
+	granted []int64
+}
+
+func (f *fakeRolePermissionRepository) AddPermissionToRole(roleId int64, permissionId int64, effect string, condition string) (*RolePermission, error) {
+	f.granted = append(f.granted, roleId)
+	return &RolePermission{}, nil
+}
+
+func (f *fakeRolePermissionRepository) RemovePermissionFromRole(roleId int64, permissionId int64) error {
+	f.granted = append(f.granted, roleId)
+	return nil
+}
+
+// TestAddPermissionToRoleStaysInTenant shows that an org admin cannot grant
+// permissions to the global admin role, which would reach every organization.
+func TestAddPermissionToRoleStaysInTenant(t *testing.T) {
+	tests := []struct {
+		name           string
+		roleId         int64
+		organizationId int64
+		wantErr        error
+	}{
+		{"own role", 2, 5, nil},
+		{"global role", 1, 5, role.ErrRoleOutsideOrganization},
+		{"role of the organization from another", 2, 6, role.ErrRoleOutsideOrganization},
+		{"global role outside any tenant", 1, 0, nil},
+	}
+	for _, tt := range tests {
+		repository := &fakeRolePermissionRepository{}
+		rs := NewRolePermissionService(repository, &fakeRoleService{})
+
+		_, err := rs.AddPermissionToRole(tt.roleId, 1, "allow", "", tt.organizationId)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: AddPermissionToRole err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		err = rs.RemovePermissionFromRole(tt.roleId, 1, tt.organizationId)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: RemovePermissionFromRole err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if tt.wantErr != nil && len(repository.granted) > 0 {
+			t.Errorf("%s: refused call still changed roles %v", tt.name, repository.granted)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	apikey "go_project_structure/internal/api_key"
+	"go_project_structure/internal/middlewares"
+	userrole "go_project_structure/internal/user_role"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type ApiKeyRouter struct {
+	apiKeyController *apikey.ApiKeyController
+}
+
+func NewApiKeyRouter(_apiKeyController *apikey.ApiKeyController) *ApiKeyRouter {
+	return &ApiKeyRouter{
+		apiKeyController: _apiKeyController,
+	}
+}
+
+func RegisterApiKeyRoutes(db *gorm.DB, router chi.Router) *ApiKeyRouter {
+	ks := apikey.NewApiKeyService(apikey.NewApiKeyRepository(db), userrole.NewUserRoleRepository(db))
+	kc := apikey.NewApiKeyController(ks)
+	kRouter := NewApiKeyRouter(kc)
+	return kRouter
+}
+
+func (kr *ApiKeyRouter) Register(r chi.Router) {
+	r.With(middlewares.JwtAuthMiddleware, apikey.CreateApiKeyRequestValidator).Post("/api-keys", kr.apiKeyController.CreateApiKey)
+	r.With(middlewares.JwtAuthMiddleware).Get("/api-keys", kr.apiKeyController.GetApiKeys)
+	r.With(middlewares.JwtAuthMiddleware).Delete("/api-keys/{id}", kr.apiKeyController.RevokeApiKey)
+}
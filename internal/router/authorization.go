@@ -0,0 +1,43 @@
+package router
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go_project_structure/internal/organization"
+	"go_project_structure/internal/permission"
+	"go_project_structure/internal/token"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
+	"net/http"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// newPermissionMiddleware builds the permission guards shared by every domain router.
+// The caller is the principal JwtAuthMiddleware puts in the request context, as long
+// as their account is still live.
+func newPermissionMiddleware(db *gorm.DB) *permission.PermissionMiddleware {
+	userRepository := user.NewUserRepository(db)
+	organizationRepository := organization.NewOrganizationRepository(db)
+	userRoleService := userrole.NewUserRoleService(userrole.NewUserRoleRepository(db))
+
+	return permission.NewPermissionMiddleware(userRoleService, func(r *http.Request) (int64, error) {
+		principal, ok := token.PrincipalFromContext(r.Context())
+		if !ok {
+			return 0, fmt.Errorf("unauthenticated request")
+		}
+		u, err := userRepository.GetByID(strconv.FormatInt(principal.UserID, 10))
+		if err != nil {
+			return 0, fmt.Errorf("unknown user")
+		}
+		return int64(u.ID), nil
+	}, func(organizationId int64) (bool, error) {
+		_, err := organizationRepository.GetByID(fmt.Sprint(organizationId))
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"go_project_structure/internal/authz"
+	"go_project_structure/internal/middlewares"
+	"go_project_structure/internal/permission"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type AuthzRouter struct {
+	authzController      *authz.AuthzController
+	permissionMiddleware *permission.PermissionMiddleware
+}
+
+func NewAuthzRouter(_authzController *authz.AuthzController, _permissionMiddleware *permission.PermissionMiddleware) *AuthzRouter {
+	return &AuthzRouter{
+		authzController:      _authzController,
+		permissionMiddleware: _permissionMiddleware,
+	}
+}
+
+func RegisterAuthzRoutes(db *gorm.DB, router chi.Router) *AuthzRouter {
+	ur := userrole.NewUserRoleRepository(db)
+	us := userrole.NewUserRoleService(ur)
+	as := authz.NewAuthzService(us, user.NewUserRepository(db))
+	ac := authz.NewAuthzController(as)
+	aRouter := NewAuthzRouter(ac, newPermissionMiddleware(db))
+	return aRouter
+}
+
+func (ar *AuthzRouter) Register(r chi.Router) {
+	r.Route("/authz", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware, ar.permissionMiddleware.ResolveTenant, ar.permissionMiddleware.RequirePermission("authz:check"))
+		r.With(authz.CheckRequestValidator).Post("/check", ar.authzController.Check)
+		r.With(authz.BatchCheckRequestValidator).Post("/check-batch", ar.authzController.CheckBatch)
+	})
+}
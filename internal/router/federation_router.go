@@ -0,0 +1,43 @@
+package router
+
+import (
+	"go_project_structure/internal/events"
+	"go_project_structure/internal/federation"
+	"go_project_structure/internal/middlewares"
+	refreshtoken "go_project_structure/internal/refresh_token"
+	revokedtoken "go_project_structure/internal/revoked_token"
+	"go_project_structure/internal/role"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type FederationRouter struct {
+	federationController *federation.FederationController
+}
+
+func NewFederationRouter(_federationController *federation.FederationController) *FederationRouter {
+	return &FederationRouter{
+		federationController: _federationController,
+	}
+}
+
+func RegisterFederationRoutes(db *gorm.DB, router chi.Router) *FederationRouter {
+	ur := user.NewUserRepository(db)
+	urr := userrole.NewUserRoleRepository(db)
+	ts := refreshtoken.NewRefreshTokenService(refreshtoken.NewRefreshTokenRepository(db), events.DefaultBus)
+	rs := revokedtoken.NewRevokedTokenService(revokedtoken.NewRevokedTokenRepository(db))
+	us := user.NewUserService(ur, urr, ts, rs)
+	fs := federation.NewFederationService(federation.NewFederationRepository(db), ur, us, urr, role.NewRoleRepository(db), newMfaService(db))
+	fc := federation.NewFederationController(fs)
+	fRouter := NewFederationRouter(fc)
+	return fRouter
+}
+
+func (fr *FederationRouter) Register(r chi.Router) {
+	r.With(middlewares.RateLimitMiddleware).Get("/auth/{provider}/login", fr.federationController.Login)
+	r.With(middlewares.RateLimitMiddleware).Get("/auth/{provider}/callback", fr.federationController.Callback)
+	r.With(middlewares.JwtAuthMiddleware).Get("/identities", fr.federationController.GetIdentities)
+}
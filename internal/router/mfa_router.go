@@ -0,0 +1,57 @@
+package router
+
+import (
+	"go_project_structure/internal/events"
+	"go_project_structure/internal/mfa"
+	"go_project_structure/internal/middlewares"
+	refreshtoken "go_project_structure/internal/refresh_token"
+	revokedtoken "go_project_structure/internal/revoked_token"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type MfaRouter struct {
+	mfaController *mfa.MfaController
+}
+
+func NewMfaRouter(_mfaController *mfa.MfaController) *MfaRouter {
+	return &MfaRouter{
+		mfaController: _mfaController,
+	}
+}
+
+// newMfaService builds the mfa service, which every way of logging in needs.
+func newMfaService(db *gorm.DB) mfa.MfaService {
+	urr := userrole.NewUserRoleRepository(db)
+	ts := refreshtoken.NewRefreshTokenService(refreshtoken.NewRefreshTokenRepository(db), events.DefaultBus)
+	rs := revokedtoken.NewRevokedTokenService(revokedtoken.NewRevokedTokenRepository(db))
+	us := user.NewUserService(user.NewUserRepository(db), urr, ts, rs)
+	return mfa.NewMfaService(mfa.NewMfaRepository(db), us, urr, newPasskeyService(db), events.DefaultBus)
+}
+
+func RegisterMfaRoutes(db *gorm.DB, router chi.Router) *MfaRouter {
+	mc := mfa.NewMfaController(newMfaService(db))
+	mRouter := NewMfaRouter(mc)
+	return mRouter
+}
+
+func (mr *MfaRouter) Register(r chi.Router) {
+	r.With(middlewares.RateLimitMiddleware).Post("/login", mr.mfaController.Login)
+	r.With(middlewares.RateLimitMiddleware, mfa.CompleteLoginRequestValidator).Post("/login/mfa", mr.mfaController.CompleteLogin)
+	r.With(middlewares.RateLimitMiddleware, mfa.MfaTokenRequestValidator).Post("/login/mfa/enroll", mr.mfaController.EnrollForLogin)
+	r.With(middlewares.RateLimitMiddleware, mfa.MfaTokenRequestValidator).Post("/login/mfa/passkey", mr.mfaController.BeginPasskeyChallenge)
+	r.With(middlewares.RateLimitMiddleware).Post("/login/passkey/options", mr.mfaController.BeginPasskeyLogin)
+	r.With(middlewares.RateLimitMiddleware, mfa.PasskeyLoginRequestValidator).Post("/login/passkey", mr.mfaController.LoginWithPasskey)
+
+	r.Route("/mfa", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware)
+		r.Get("/", mr.mfaController.GetStatus)
+		r.Post("/totp", mr.mfaController.StartEnrollment)
+		r.With(middlewares.RateLimitMiddleware, mfa.CodeRequestValidator).Post("/totp/verify", mr.mfaController.ConfirmEnrollment)
+		r.With(middlewares.RateLimitMiddleware, mfa.CodeRequestValidator).Delete("/totp", mr.mfaController.Disable)
+		r.With(middlewares.RateLimitMiddleware, mfa.CodeRequestValidator).Post("/recovery-codes", mr.mfaController.RegenerateRecoveryCodes)
+	})
+}
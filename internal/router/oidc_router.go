@@ -0,0 +1,57 @@
+package router
+
+import (
+	"go_project_structure/internal/events"
+	"go_project_structure/internal/middlewares"
+	"go_project_structure/internal/oidc"
+	"go_project_structure/internal/permission"
+	refreshtoken "go_project_structure/internal/refresh_token"
+	revokedtoken "go_project_structure/internal/revoked_token"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type OidcRouter struct {
+	oidcController       *oidc.OidcController
+	permissionMiddleware *permission.PermissionMiddleware
+}
+
+func NewOidcRouter(_oidcController *oidc.OidcController, _permissionMiddleware *permission.PermissionMiddleware) *OidcRouter {
+	return &OidcRouter{
+		oidcController:       _oidcController,
+		permissionMiddleware: _permissionMiddleware,
+	}
+}
+
+func RegisterOidcRoutes(db *gorm.DB, router chi.Router) *OidcRouter {
+	ts := refreshtoken.NewRefreshTokenService(refreshtoken.NewRefreshTokenRepository(db), events.DefaultBus)
+	rs := revokedtoken.NewRevokedTokenService(revokedtoken.NewRevokedTokenRepository(db))
+	us := user.NewUserService(user.NewUserRepository(db), userrole.NewUserRoleRepository(db), ts, rs)
+	ps := oidc.NewOidcService(oidc.NewOidcRepository(db), us, ts, newServiceAccountService(db), newMfaService(db))
+	oc := oidc.NewOidcController(ps)
+	oRouter := NewOidcRouter(oc, newPermissionMiddleware(db))
+	return oRouter
+}
+
+func (ir *OidcRouter) Register(r chi.Router) {
+	if !oidc.Enabled() {
+		return
+	}
+	r.Get("/.well-known/openid-configuration", ir.oidcController.Discovery)
+	r.With(oidc.AuthorizeRequestValidator).Get("/oauth/authorize", ir.oidcController.Authorize)
+	r.With(middlewares.RateLimitMiddleware, oidc.AuthorizeRequestValidator).Post("/oauth/authorize", ir.oidcController.AuthorizeLogin)
+	r.With(middlewares.RateLimitMiddleware, oidc.TokenRequestValidator).Post("/oauth/token", ir.oidcController.Token)
+	r.With(oidc.ClientTokenAuthMiddleware).Get("/userinfo", ir.oidcController.UserInfo)
+	r.With(oidc.ClientTokenAuthMiddleware).Post("/userinfo", ir.oidcController.UserInfo)
+
+	r.Route("/oauth/clients", func(r chi.Router) {
+		// clients are shared by every organization
+		r.Use(middlewares.JwtAuthMiddleware, ir.permissionMiddleware.ResolveTenant, permission.GlobalScope)
+		r.With(ir.permissionMiddleware.RequirePermission("oidc_client:create"), oidc.CreateClientRequestValidator).Post("/", ir.oidcController.CreateClient)
+		r.With(ir.permissionMiddleware.RequirePermission("oidc_client:read")).Get("/", ir.oidcController.GetAllClients)
+		r.With(ir.permissionMiddleware.RequirePermission("oidc_client:delete")).Delete("/{id}", ir.oidcController.DeleteClient)
+	})
+}
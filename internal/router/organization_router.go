@@ -0,0 +1,43 @@
+package router
+
+import (
+	"go_project_structure/internal/middlewares"
+	"go_project_structure/internal/organization"
+	"go_project_structure/internal/permission"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type OrganizationRouter struct {
+	organizationController *organization.OrganizationController
+	permissionMiddleware   *permission.PermissionMiddleware
+}
+
+func NewOrganizationRouter(_organizationController *organization.OrganizationController, _permissionMiddleware *permission.PermissionMiddleware) *OrganizationRouter {
+	return &OrganizationRouter{
+		organizationController: _organizationController,
+		permissionMiddleware:   _permissionMiddleware,
+	}
+}
+
+func RegisterOrganizationRoutes(db *gorm.DB, router chi.Router) *OrganizationRouter {
+	or := organization.NewOrganizationRepository(db)
+	os := organization.NewOrganizationService(or)
+	oc := organization.NewOrganizationController(os)
+	oRouter := NewOrganizationRouter(oc, newPermissionMiddleware(db))
+	return oRouter
+}
+
+func (or *OrganizationRouter) Register(r chi.Router) {
+	r.Route("/organizations", func(r chi.Router) {
+		// organizations are global resources
+		r.Use(middlewares.JwtAuthMiddleware, or.permissionMiddleware.ResolveTenant, permission.GlobalScope)
+		r.With(or.permissionMiddleware.RequirePermission("organization:create"), organization.CreateOrganizationRequestValidator).Post("/", or.organizationController.CreateOrganization)
+		r.With(or.permissionMiddleware.RequirePermission("organization:read")).Get("/", or.organizationController.GetAllOrganizations)
+		r.With(or.permissionMiddleware.RequirePermission("organization:read")).Get("/{id}", or.organizationController.GetOrganizationById)
+		r.With(or.permissionMiddleware.RequirePermission("organization:update"), organization.UpdateOrganizationRequestValidator).Patch("/{id}", or.organizationController.UpdateOrganization)
+		r.With(or.permissionMiddleware.RequirePermission("organization:delete")).Delete("/{id}", or.organizationController.DeleteOrganization)
+		r.With(or.permissionMiddleware.RequirePermission("organization:delete")).Delete("/{id}/permanent", or.organizationController.PermanentlyDeleteOrganization)
+	})
+}
@@ -0,0 +1,48 @@
+package router
+
+import (
+	"go_project_structure/internal/events"
+	"go_project_structure/internal/middlewares"
+	"go_project_structure/internal/passkey"
+	refreshtoken "go_project_structure/internal/refresh_token"
+	revokedtoken "go_project_structure/internal/revoked_token"
+	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type PasskeyRouter struct {
+	passkeyController *passkey.PasskeyController
+}
+
+func NewPasskeyRouter(_passkeyController *passkey.PasskeyController) *PasskeyRouter {
+	return &PasskeyRouter{
+		passkeyController: _passkeyController,
+	}
+}
+
+// newPasskeyService builds the passkey service, which the logins need as well.
+func newPasskeyService(db *gorm.DB) passkey.PasskeyService {
+	ts := refreshtoken.NewRefreshTokenService(refreshtoken.NewRefreshTokenRepository(db), events.DefaultBus)
+	rs := revokedtoken.NewRevokedTokenService(revokedtoken.NewRevokedTokenRepository(db))
+	us := user.NewUserService(user.NewUserRepository(db), userrole.NewUserRoleRepository(db), ts, rs)
+	return passkey.NewPasskeyService(passkey.NewPasskeyRepository(db), us, events.DefaultBus)
+}
+
+func RegisterPasskeyRoutes(db *gorm.DB, router chi.Router) *PasskeyRouter {
+	pc := passkey.NewPasskeyController(newPasskeyService(db))
+	pRouter := NewPasskeyRouter(pc)
+	return pRouter
+}
+
+func (pr *PasskeyRouter) Register(r chi.Router) {
+	r.Route("/passkeys", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware)
+		r.Get("/", pr.passkeyController.GetPasskeys)
+		r.Post("/registration", pr.passkeyController.BeginRegistration)
+		r.With(middlewares.RateLimitMiddleware, passkey.RegisterPasskeyRequestValidator).Post("/", pr.passkeyController.FinishRegistration)
+		r.Delete("/{id}", pr.passkeyController.DeletePasskey)
+	})
+}
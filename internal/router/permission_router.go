@@ -0,0 +1,42 @@
+package router
+
+import (
+	"go_project_structure/internal/middlewares"
+	"go_project_structure/internal/permission"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type PermissionRouter struct {
+	permissionController *permission.PermissionController
+	permissionMiddleware *permission.PermissionMiddleware
+}
+
+func NewPermissionRouter(_permissionController *permission.PermissionController, _permissionMiddleware *permission.PermissionMiddleware) *PermissionRouter {
+	return &PermissionRouter{
+		permissionController: _permissionController,
+		permissionMiddleware: _permissionMiddleware,
+	}
+}
+
+func RegisterPermissionRoutes(db *gorm.DB, router chi.Router) *PermissionRouter {
+	pr := permission.NewPermissionRepository(db)
+	ps := permission.NewPermissionService(pr)
+	pc := permission.NewPermissionController(ps)
+	pRouter := NewPermissionRouter(pc, newPermissionMiddleware(db))
+	return pRouter
+}
+
+func (pr *PermissionRouter) Register(r chi.Router) {
+	r.Route("/permissions", func(r chi.Router) {
+		// the catalogue is shared by every organization: only global grants change it
+		r.Use(middlewares.JwtAuthMiddleware, pr.permissionMiddleware.ResolveTenant)
+		r.With(permission.GlobalScope, pr.permissionMiddleware.RequirePermission("permission:create"), permission.CreatePermissionRequestValidator).Post("/", pr.permissionController.CreatePermission)
+		r.With(pr.permissionMiddleware.RequirePermission("permission:read")).Get("/", pr.permissionController.GetAllPermissions)
+		r.With(pr.permissionMiddleware.RequirePermission("permission:read")).Get("/{id}", pr.permissionController.GetPermissionById)
+		r.With(permission.GlobalScope, pr.permissionMiddleware.RequirePermission("permission:update"), permission.UpdatePermissionRequestValidator).Patch("/{id}", pr.permissionController.UpdatePermission)
+		r.With(permission.GlobalScope, pr.permissionMiddleware.RequirePermission("permission:delete")).Delete("/{id}", pr.permissionController.DeletePermission)
+		r.With(permission.GlobalScope, pr.permissionMiddleware.RequirePermission("permission:delete")).Delete("/{id}/permanent", pr.permissionController.PermanentlyDeletePermission)
+	})
+}
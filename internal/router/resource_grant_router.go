@@ -0,0 +1,41 @@
+package router
+
+import (
+	"go_project_structure/internal/middlewares"
+	"go_project_structure/internal/permission"
+	resourcegrant "go_project_structure/internal/resource_grant"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type ResourceGrantRouter struct {
+	resourceGrantController *resourcegrant.ResourceGrantController
+	permissionMiddleware    *permission.PermissionMiddleware
+}
+
+func NewResourceGrantRouter(_resourceGrantController *resourcegrant.ResourceGrantController, _permissionMiddleware *permission.PermissionMiddleware) *ResourceGrantRouter {
+	return &ResourceGrantRouter{
+		resourceGrantController: _resourceGrantController,
+		permissionMiddleware:    _permissionMiddleware,
+	}
+}
+
+func RegisterResourceGrantRoutes(db *gorm.DB, router chi.Router) *ResourceGrantRouter {
+	gr := resourcegrant.NewResourceGrantRepository(db)
+	gs := resourcegrant.NewResourceGrantService(gr)
+	gc := resourcegrant.NewResourceGrantController(gs)
+	gRouter := NewResourceGrantRouter(gc, newPermissionMiddleware(db))
+	return gRouter
+}
+
+func (rgr *ResourceGrantRouter) Register(r chi.Router) {
+	r.Route("/resource-grants", func(r chi.Router) {
+		// grants on objects reach past any organization
+		r.Use(middlewares.JwtAuthMiddleware, rgr.permissionMiddleware.ResolveTenant, permission.GlobalScope)
+		r.With(rgr.permissionMiddleware.RequirePermission("grant:read")).Get("/", rgr.resourceGrantController.GetGrants)
+		r.With(rgr.permissionMiddleware.RequirePermission("grant:read")).Get("/{id}", rgr.resourceGrantController.GetGrantById)
+		r.With(rgr.permissionMiddleware.RequirePermission("grant:create"), resourcegrant.CreateResourceGrantRequestValidator).Post("/", rgr.resourceGrantController.GrantPermission)
+		r.With(rgr.permissionMiddleware.RequirePermission("grant:delete")).Delete("/{id}", rgr.resourceGrantController.RevokeGrant)
+	})
+}
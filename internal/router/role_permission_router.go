@@ -0,0 +1,42 @@
+package router
+
+import (
+	"go_project_structure/internal/middlewares"
+	"go_project_structure/internal/permission"
+	"go_project_structure/internal/role"
+	rolepermission "go_project_structure/internal/role_permission"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type RolePermissionRouter struct {
+	rolePermissionController *rolepermission.RolePermissionController
+	permissionMiddleware     *permission.PermissionMiddleware
+}
+
+func NewRolePermissionRouter(_rolePermissionController *rolepermission.RolePermissionController, _permissionMiddleware *permission.PermissionMiddleware) *RolePermissionRouter {
+	return &RolePermissionRouter{
+		rolePermissionController: _rolePermissionController,
+		permissionMiddleware:     _permissionMiddleware,
+	}
+}
+
+func RegisterRolePermissionRoutes(db *gorm.DB, router chi.Router) *RolePermissionRouter {
+	rpr := rolepermission.NewRolePermissionRepository(db)
+	rs := role.NewRoleService(role.NewRoleRepository(db))
+	rps := rolepermission.NewRolePermissionService(rpr, rs)
+	rpc := rolepermission.NewRolePermissionController(rps)
+	rpRouter := NewRolePermissionRouter(rpc, newPermissionMiddleware(db))
+	return rpRouter
+}
+
+func (rpr *RolePermissionRouter) Register(r chi.Router) {
+	r.Route("/role-permissions", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware, rpr.permissionMiddleware.ResolveTenant)
+		r.With(rpr.permissionMiddleware.RequirePermission("permission:read")).Get("/", rpr.rolePermissionController.GetRolePermissions)
+		r.With(rpr.permissionMiddleware.RequirePermission("permission:read")).Get("/{id}", rpr.rolePermissionController.GetRolePermissionById)
+		r.With(rpr.permissionMiddleware.RequirePermission("role:update"), rolepermission.GrantPermissionRequestValidator).Post("/", rpr.rolePermissionController.AddPermissionToRole)
+		r.With(rpr.permissionMiddleware.RequirePermission("role:update")).Delete("/{roleId}/{permissionId}", rpr.rolePermissionController.RemovePermissionFromRole)
+	})
+}
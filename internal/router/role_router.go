@@ -0,0 +1,45 @@
+package router
+
+import (
+	"go_project_structure/internal/middlewares"
+	"go_project_structure/internal/permission"
+	"go_project_structure/internal/role"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type RoleRouter struct {
+	roleController       *role.RoleController
+	permissionMiddleware *permission.PermissionMiddleware
+}
+
+func NewRoleRouter(_roleController *role.RoleController, _permissionMiddleware *permission.PermissionMiddleware) *RoleRouter {
+	return &RoleRouter{
+		roleController:       _roleController,
+		permissionMiddleware: _permissionMiddleware,
+	}
+}
+
+func RegisterRoleRoutes(db *gorm.DB, router chi.Router) *RoleRouter {
+	rr := role.NewRoleRepository(db)
+	rs := role.NewRoleService(rr)
+	rc := role.NewRoleController(rs)
+	rRouter := NewRoleRouter(rc, newPermissionMiddleware(db))
+	return rRouter
+}
+
+func (rr *RoleRouter) Register(r chi.Router) {
+	r.Route("/roles", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware, rr.permissionMiddleware.ResolveTenant)
+		r.With(rr.permissionMiddleware.RequirePermission("role:create"), role.CreateRoleRequestValidator).Post("/", rr.roleController.CreateRole)
+		r.With(rr.permissionMiddleware.RequirePermission("role:read")).Get("/", rr.roleController.GetAllRoles)
+		r.With(rr.permissionMiddleware.RequirePermission("role:read")).Get("/{id}", rr.roleController.GetRoleById)
+		r.With(rr.permissionMiddleware.RequirePermission("role:update"), role.UpdateRoleRequestValidator).Patch("/{id}", rr.roleController.UpdateRole)
+		r.With(rr.permissionMiddleware.RequirePermission("role:delete")).Delete("/{id}", rr.roleController.DeleteRole)
+		r.With(rr.permissionMiddleware.RequirePermission("role:delete")).Delete("/{id}/permanent", rr.roleController.PermanentlyDeleteRole)
+		r.With(rr.permissionMiddleware.RequirePermission("role:read")).Get("/{id}/parents", rr.roleController.GetParentRoles)
+		r.With(rr.permissionMiddleware.RequirePermission("role:update"), role.AddParentRoleRequestValidator).Post("/{id}/parents", rr.roleController.AddParentRole)
+		r.With(rr.permissionMiddleware.RequirePermission("role:update")).Delete("/{id}/parents/{parentId}", rr.roleController.RemoveParentRole)
+	})
+}
@@ -13,7 +13,48 @@ var DomainRegistries = []func(*gorm.DB, chi.Router){
 	func(db *gorm.DB, router chi.Router) {
 		RegisterRoutes(db, router).Register(router)
 	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterUserRoleRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterRolePermissionRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterRoleRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterPermissionRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterOrganizationRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterResourceGrantRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterAuthzRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterTokenRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterOidcRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterFederationRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterApiKeyRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterServiceAccountRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterMfaRoutes(db, router).Register(router)
+	},
+	func(db *gorm.DB, router chi.Router) {
+		RegisterPasskeyRoutes(db, router).Register(router)
+	},
 
 	// Add new modules here:
-	// role.RegisterRoutes,
 }
@@ -0,0 +1,57 @@
+package router
+
+import (
+	apikey "go_project_structure/internal/api_key"
+	"go_project_structure/internal/events"
+	"go_project_structure/internal/middlewares"
+	"go_project_structure/internal/permission"
+	revokedtoken "go_project_structure/internal/revoked_token"
+	serviceaccount "go_project_structure/internal/service_account"
+	userrole "go_project_structure/internal/user_role"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type ServiceAccountRouter struct {
+	serviceAccountController *serviceaccount.ServiceAccountController
+	permissionMiddleware     *permission.PermissionMiddleware
+}
+
+func NewServiceAccountRouter(_serviceAccountController *serviceaccount.ServiceAccountController, _permissionMiddleware *permission.PermissionMiddleware) *ServiceAccountRouter {
+	return &ServiceAccountRouter{
+		serviceAccountController: _serviceAccountController,
+		permissionMiddleware:     _permissionMiddleware,
+	}
+}
+
+// newServiceAccountService builds the service account service, which the token
+// endpoint needs as well.
+func newServiceAccountService(db *gorm.DB) serviceaccount.ServiceAccountService {
+	urr := userrole.NewUserRoleRepository(db)
+	ks := apikey.NewApiKeyService(apikey.NewApiKeyRepository(db), urr)
+	rs := revokedtoken.NewRevokedTokenService(revokedtoken.NewRevokedTokenRepository(db))
+	return serviceaccount.NewServiceAccountService(serviceaccount.NewServiceAccountRepository(db), urr, ks, rs, events.DefaultBus)
+}
+
+func RegisterServiceAccountRoutes(db *gorm.DB, router chi.Router) *ServiceAccountRouter {
+	sc := serviceaccount.NewServiceAccountController(newServiceAccountService(db))
+	sRouter := NewServiceAccountRouter(sc, newPermissionMiddleware(db))
+	return sRouter
+}
+
+func (sr *ServiceAccountRouter) Register(r chi.Router) {
+	r.Route("/service-accounts", func(r chi.Router) {
+		// service accounts are users, which are global
+		r.Use(middlewares.JwtAuthMiddleware, sr.permissionMiddleware.ResolveTenant, permission.GlobalScope)
+		r.With(sr.permissionMiddleware.RequirePermission("service_account:create"), serviceaccount.CreateServiceAccountRequestValidator).Post("/", sr.serviceAccountController.CreateServiceAccount)
+		r.With(sr.permissionMiddleware.RequirePermission("service_account:read")).Get("/", sr.serviceAccountController.GetAllServiceAccounts)
+		r.With(sr.permissionMiddleware.RequirePermission("service_account:read")).Get("/{id}", sr.serviceAccountController.GetServiceAccountById)
+		r.With(sr.permissionMiddleware.RequirePermission("service_account:update")).Post("/{id}/secret", sr.serviceAccountController.RotateSecret)
+		r.With(sr.permissionMiddleware.RequirePermission("service_account:delete")).Delete("/{id}", sr.serviceAccountController.DeleteServiceAccount)
+
+		r.With(sr.permissionMiddleware.RequirePermission("service_account:update"), apikey.CreateApiKeyRequestValidator).Post("/{id}/api-keys", sr.serviceAccountController.CreateApiKey)
+		r.With(sr.permissionMiddleware.RequirePermission("service_account:read")).Get("/{id}/api-keys", sr.serviceAccountController.GetApiKeys)
+		r.With(sr.permissionMiddleware.RequirePermission("service_account:update")).Delete("/{id}/api-keys/{keyId}", sr.serviceAccountController.RevokeApiKey)
+	})
+}
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"go_project_structure/internal/token"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type TokenRouter struct {
+	tokenController *token.TokenController
+}
+
+func NewTokenRouter(_tokenController *token.TokenController) *TokenRouter {
+	return &TokenRouter{
+		tokenController: _tokenController,
+	}
+}
+
+func RegisterTokenRoutes(db *gorm.DB, router chi.Router) *TokenRouter {
+	tc := token.NewTokenController()
+	tRouter := NewTokenRouter(tc)
+	return tRouter
+}
+
+func (tr *TokenRouter) Register(r chi.Router) {
+	r.Get("/.well-known/jwks.json", tr.tokenController.Jwks)
+}
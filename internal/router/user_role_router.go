@@ -0,0 +1,45 @@
+package router
+
+import (
+	"go_project_structure/internal/middlewares"
+	"go_project_structure/internal/permission"
+	userrole "go_project_structure/internal/user_role"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+type UserRoleRouter struct {
+	userRoleController   *userrole.UserRoleController
+	permissionMiddleware *permission.PermissionMiddleware
+}
+
+func NewUserRoleRouter(_userRoleController *userrole.UserRoleController, _permissionMiddleware *permission.PermissionMiddleware) *UserRoleRouter {
+	return &UserRoleRouter{
+		userRoleController:   _userRoleController,
+		permissionMiddleware: _permissionMiddleware,
+	}
+}
+
+func RegisterUserRoleRoutes(db *gorm.DB, router chi.Router) *UserRoleRouter {
+	urr := userrole.NewUserRoleRepository(db)
+	urs := userrole.NewUserRoleService(urr)
+	urc := userrole.NewUserRoleController(urs)
+	urRouter := NewUserRoleRouter(urc, newPermissionMiddleware(db))
+	return urRouter
+}
+
+func (urr *UserRoleRouter) Register(r chi.Router) {
+	r.Route("/users/{userId}", func(r chi.Router) {
+		// assignments and checks under this route apply to the tenant of the request
+		r.Use(middlewares.JwtAuthMiddleware, urr.permissionMiddleware.ResolveTenant)
+		r.With(urr.permissionMiddleware.RequirePermission("role:read")).Get("/roles", urr.userRoleController.GetUserRoles)
+		r.With(urr.permissionMiddleware.RequirePermission("role:read")).Get("/roles/check", urr.userRoleController.CheckRoles)
+		r.With(urr.permissionMiddleware.RequirePermission("role:read")).Get("/assignments", urr.userRoleController.GetUserAssignments)
+		r.With(urr.permissionMiddleware.RequirePermission("role:update"), userrole.AssignRoleRequestValidator).Post("/roles", urr.userRoleController.AssignRoleToUser)
+		r.With(urr.permissionMiddleware.RequirePermission("role:update")).Delete("/roles/{roleId}", urr.userRoleController.RemoveRoleFromUser)
+		r.With(urr.permissionMiddleware.RequirePermission("permission:read")).Get("/permissions", urr.userRoleController.GetUserPermissions)
+		r.With(urr.permissionMiddleware.RequirePermission("permission:read")).Get("/permissions/check", urr.userRoleController.CheckPermission)
+		r.With(urr.permissionMiddleware.RequirePermission("permission:read")).Get("/permissions/effective", urr.userRoleController.GetUserEffectivePermissions)
+	})
+}
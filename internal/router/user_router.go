@@ -1,8 +1,13 @@
 package router
 
 import (
+	"go_project_structure/internal/events"
 	"go_project_structure/internal/middlewares"
+	"go_project_structure/internal/permission"
+	refreshtoken "go_project_structure/internal/refresh_token"
+	revokedtoken "go_project_structure/internal/revoked_token"
 	"go_project_structure/internal/user"
+	userrole "go_project_structure/internal/user_role"
 	"go_project_structure/utils"
 
 	"github.com/go-chi/chi/v5"
@@ -10,31 +15,37 @@ import (
 )
 
 type UserRouter struct {
-	userController *user.UserController
+	userController       *user.UserController
+	permissionMiddleware *permission.PermissionMiddleware
 }
 
-func NewUserRouter(_userController *user.UserController) *UserRouter {
+func NewUserRouter(_userController *user.UserController, _permissionMiddleware *permission.PermissionMiddleware) *UserRouter {
 	return &UserRouter{
-		userController: _userController,
+		userController:       _userController,
+		permissionMiddleware: _permissionMiddleware,
 	}
 }
 
 func RegisterRoutes(db *gorm.DB, router chi.Router) *UserRouter {
 	ur := user.NewUserRepository(db)
-	us := user.NewUserService(ur)
+	ts := refreshtoken.NewRefreshTokenService(refreshtoken.NewRefreshTokenRepository(db), events.DefaultBus)
+	rs := revokedtoken.NewRevokedTokenService(revokedtoken.NewRevokedTokenRepository(db))
+	us := user.NewUserService(ur, userrole.NewUserRoleRepository(db), ts, rs)
 	uc := user.NewUserController(us)
-	uRouter := NewUserRouter(uc)
+	uRouter := NewUserRouter(uc, newPermissionMiddleware(db))
 	return uRouter
 }
 
 func (ur *UserRouter) Register(r chi.Router) {
 	r.Use(middlewares.RequestLoggerMiddleware)
 	r.With(user.UserRegisterRequestValidator).Post("/signup", ur.userController.RegisterUser)
-	r.Post("/login", ur.userController.LoginUser)
-	r.With(middlewares.JwtAuthMiddleware).Get("/profile/{id}", ur.userController.GetUserById)
-	r.With(middlewares.JwtAuthMiddleware).Get("/profile", ur.userController.GetAllUsers)
-	r.With(middlewares.RateLimitMiddleware, user.UserUpdateRequestValidator).Patch("/profile/{id}", ur.userController.UpdateUser)
-	r.With(middlewares.JwtAuthMiddleware).Delete("/profile/{id}", ur.userController.DeleteUser)
+	r.With(user.RefreshTokenRequestValidator).Post("/token/refresh", ur.userController.RefreshToken)
+	r.With(middlewares.JwtAuthMiddleware).Post("/logout", ur.userController.Logout)
+	r.With(middlewares.JwtAuthMiddleware, ur.permissionMiddleware.ResolveTenant, permission.GlobalScope, ur.permissionMiddleware.RequireResourcePermission("user:read", "id")).Get("/profile/{id}", ur.userController.GetUserById)
+	r.With(middlewares.JwtAuthMiddleware, ur.permissionMiddleware.ResolveTenant, permission.GlobalScope, ur.permissionMiddleware.RequirePermission("user:read")).Get("/profile", ur.userController.GetAllUsers)
+	r.With(middlewares.RateLimitMiddleware, middlewares.JwtAuthMiddleware, ur.permissionMiddleware.ResolveTenant, permission.GlobalScope, ur.permissionMiddleware.RequireResourcePermission("user:update", "id"), user.UserUpdateRequestValidator).Patch("/profile/{id}", ur.userController.UpdateUser)
+	r.With(middlewares.JwtAuthMiddleware, ur.permissionMiddleware.ResolveTenant, permission.GlobalScope, ur.permissionMiddleware.RequireResourcePermission("user:delete", "id")).Delete("/profile/{id}", ur.userController.DeleteUser)
+	r.With(middlewares.JwtAuthMiddleware, ur.permissionMiddleware.ResolveTenant, permission.GlobalScope, ur.permissionMiddleware.RequirePermission("session:revoke")).Delete("/profile/{id}/sessions", ur.userController.RevokeAllSessions)
 
 	// proxy routes
 	r.Get("/fake-store/*", utils.ProxyToService("https://fakestoreapi.com", "/fake-store"))
@@ -0,0 +1,22 @@
+package serviceaccount
+
+import "time"
+
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description" validate:"omitempty,max=255"`
+}
+
+// ServiceAccountResponse describes a service account. UserID is the id its roles are
+// assigned to. ClientSecret is only returned when the account is created or its
+// secret is rotated.
+type ServiceAccountResponse struct {
+	ID           uint      `json:"id"`
+	UserID       uint      `json:"user_id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+	CreatedBy    uint      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,200 @@
+package serviceaccount
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	apikey "go_project_structure/internal/api_key"
+	"go_project_structure/internal/token"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ServiceAccountController struct {
+	ServiceAccountService ServiceAccountService
+}
+
+func NewServiceAccountController(_serviceAccountService ServiceAccountService) *ServiceAccountController {
+	return &ServiceAccountController{
+		ServiceAccountService: _serviceAccountService,
+	}
+}
+
+// parseId reads a positive integer id from a url parameter.
+func parseId(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return id, nil
+}
+
+// notFoundStatus maps a missing account to 404 and anything else to 500.
+func notFoundStatus(err error) int {
+	if errors.Is(err, sql.ErrNoRows) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func serviceAccountResponse(serviceAccount *ServiceAccount, secret string) *ServiceAccountResponse {
+	return &ServiceAccountResponse{
+		ID:           serviceAccount.ID,
+		UserID:       serviceAccount.UserID,
+		Name:         serviceAccount.Name,
+		Description:  serviceAccount.Description,
+		ClientID:     serviceAccount.ClientID,
+		ClientSecret: secret,
+		CreatedBy:    serviceAccount.CreatedBy,
+		CreatedAt:    serviceAccount.CreatedAt,
+	}
+}
+
+func (sc *ServiceAccountController) CreateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	principal, ok := token.PrincipalFromContext(r.Context())
+	if !ok {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", errors.New("missing principal"))
+		return
+	}
+	requestPayload := r.Context().Value("create_service_account_payload").(CreateServiceAccountRequest)
+
+	secret, serviceAccount, err := sc.ServiceAccountService.Create(requestPayload.Name, requestPayload.Description, principal.UserID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Service account creation failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusCreated, "Service account created; store the client secret now, it cannot be shown again", serviceAccountResponse(serviceAccount, secret))
+}
+
+func (sc *ServiceAccountController) GetAllServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	serviceAccounts, err := sc.ServiceAccountService.GetAll()
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Service accounts fetch failed.", err)
+		return
+	}
+	responses := []*ServiceAccountResponse{}
+	for _, serviceAccount := range serviceAccounts {
+		responses = append(responses, serviceAccountResponse(serviceAccount, ""))
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get all service accounts end point", responses)
+}
+
+func (sc *ServiceAccountController) GetServiceAccountById(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r, "id")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid service account id", err)
+		return
+	}
+
+	serviceAccount, err := sc.ServiceAccountService.GetByID(id)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, notFoundStatus(err), "Service account fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get service account by id end point", serviceAccountResponse(serviceAccount, ""))
+}
+
+func (sc *ServiceAccountController) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	principal, ok := token.PrincipalFromContext(r.Context())
+	if !ok {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", errors.New("missing principal"))
+		return
+	}
+	id, err := parseId(r, "id")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid service account id", err)
+		return
+	}
+
+	secret, serviceAccount, err := sc.ServiceAccountService.RotateSecret(id, principal.UserID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, notFoundStatus(err), "Service account secret rotation failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Client secret rotated; store it now, it cannot be shown again", serviceAccountResponse(serviceAccount, secret))
+}
+
+func (sc *ServiceAccountController) DeleteServiceAccount(w http.ResponseWriter, r *http.Request) {
+	principal, ok := token.PrincipalFromContext(r.Context())
+	if !ok {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", errors.New("missing principal"))
+		return
+	}
+	id, err := parseId(r, "id")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid service account id", err)
+		return
+	}
+
+	err = sc.ServiceAccountService.Delete(id, principal.UserID)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, notFoundStatus(err), "Service account delete failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Service account deleted successfully", nil)
+}
+
+func (sc *ServiceAccountController) CreateApiKey(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r, "id")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid service account id", err)
+		return
+	}
+	requestPayload := r.Context().Value("create_api_key_payload").(apikey.CreateApiKeyRequest)
+
+	key, apiKey, err := sc.ServiceAccountService.CreateApiKey(id, requestPayload.Name, requestPayload.Scopes, requestPayload.ExpiresInDays, requestPayload.OrganizationID)
+	if err != nil {
+		status := notFoundStatus(err)
+		if errors.Is(err, apikey.ErrScopeNotPermitted) || errors.Is(err, apikey.ErrNotMember) {
+			status = http.StatusForbidden
+		}
+		utils.WriteJsonErrorResponse(w, status, "API key creation failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusCreated, "API key created; store it now, it is not shown again", &apikey.CreateApiKeyResponse{
+		Key:    key,
+		ApiKey: apikey.NewApiKeyView(apiKey),
+	})
+}
+
+func (sc *ServiceAccountController) GetApiKeys(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r, "id")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid service account id", err)
+		return
+	}
+
+	apiKeys, err := sc.ServiceAccountService.GetApiKeys(id)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, notFoundStatus(err), "API keys fetch failed.", err)
+		return
+	}
+	views := []*apikey.ApiKeyView{}
+	for _, apiKey := range apiKeys {
+		views = append(views, apikey.NewApiKeyView(apiKey))
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get service account api keys end point", views)
+}
+
+func (sc *ServiceAccountController) RevokeApiKey(w http.ResponseWriter, r *http.Request) {
+	id, err := parseId(r, "id")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid service account id", err)
+		return
+	}
+	apiKeyId, err := parseId(r, "keyId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid api key id", err)
+		return
+	}
+
+	err = sc.ServiceAccountService.RevokeApiKey(id, apiKeyId)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusNotFound, "API key revoke failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "API key revoked successfully", nil)
+}
@@ -0,0 +1,37 @@
+package serviceaccount
+
+import (
+	"context"
+	"fmt"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strings"
+)
+
+func CreateServiceAccountRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = CreateServiceAccountRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("create service account payload received.")
+
+		RequestPayload.Name = strings.TrimSpace(RequestPayload.Name)
+		if RequestPayload.Name == "" || len(RequestPayload.Name) > 255 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("name is required and must be at most 255 characters"))
+			return
+		}
+		RequestPayload.Description = strings.TrimSpace(RequestPayload.Description)
+		if len(RequestPayload.Description) > 255 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("description must be at most 255 characters"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "create_service_account_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
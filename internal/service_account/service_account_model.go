@@ -0,0 +1,21 @@
+package serviceaccount
+
+import (
+	"gorm.io/gorm"
+)
+
+// ServiceAccount is a non-human client of the API. Its identity is a users row of
+// kind service_account, UserID, so it holds roles, resource grants and API keys like
+// any user does. It authenticates with its client id and the secret whose SHA-256 is
+// SecretHash, or with an API key.
+type ServiceAccount struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;uniqueIndex"`
+	ClientID    string `gorm:"size:64;not null;uniqueIndex"`
+	SecretHash  string `gorm:"size:64;not null"`
+	Description string `gorm:"size:255;not null;default:''"`
+	// CreatedBy is the user who created the account
+	CreatedBy uint `gorm:"not null"`
+	// Name is the name of the identity of the account
+	Name string `gorm:"-"`
+}
@@ -0,0 +1,180 @@
+package serviceaccount
+
+import (
+	"database/sql"
+	"fmt"
+	"go_project_structure/internal/token"
+
+	"gorm.io/gorm"
+)
+
+type ServiceAccountRepository interface {
+	Create(name string, email string, description string, clientId string, secretHash string, createdBy int64) (*ServiceAccount, error)
+	GetAll() ([]*ServiceAccount, error)
+	GetByID(id int64) (*ServiceAccount, error)
+	GetByClientID(clientId string) (*ServiceAccount, error)
+	UpdateSecretHash(id int64, secretHash string) error
+	Delete(id int64) (*ServiceAccount, error)
+}
+
+type ServiceAccountRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewServiceAccountRepository(_db *gorm.DB) ServiceAccountRepository {
+	return &ServiceAccountRepositoryImpl{
+		db: _db,
+	}
+}
+
+const serviceAccountColumns = "sa.id, sa.user_id, sa.client_id, sa.secret_hash, sa.description, sa.created_by, sa.created_at, sa.updated_at, usr.name"
+
+// serviceAccountFrom joins the live identity of each account.
+const serviceAccountFrom = " FROM service_accounts sa JOIN users usr ON usr.id = sa.user_id AND usr.deleted_at IS NULL WHERE sa.deleted_at IS NULL"
+
+// Create inserts the identity of the account and the account itself.
+func (u *ServiceAccountRepositoryImpl) Create(name string, email string, description string, clientId string, secretHash string, createdBy int64) (*ServiceAccount, error) {
+	fmt.Println("Creating service account in serviceAccount repository.")
+
+	serviceAccount := &ServiceAccount{}
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		// step 1: create the identity, which has no password to log in with
+		var userId uint
+		row := tx.Raw("INSERT INTO users (name, email, password, kind) VALUES (?, ?, '', ?) RETURNING id", name, email, token.SubjectServiceAccount).Row()
+		if err := row.Scan(&userId); err != nil {
+			return err
+		}
+
+		// step 2: create the account
+		row = tx.Raw(`INSERT INTO service_accounts (user_id, client_id, secret_hash, description, created_by) VALUES (?, ?, ?, ?, ?)
+			RETURNING id, user_id, client_id, secret_hash, description, created_by, created_at, updated_at`, userId, clientId, secretHash, description, createdBy).Row()
+		return row.Scan(&serviceAccount.ID, &serviceAccount.UserID, &serviceAccount.ClientID, &serviceAccount.SecretHash, &serviceAccount.Description,
+			&serviceAccount.CreatedBy, &serviceAccount.CreatedAt, &serviceAccount.UpdatedAt)
+	})
+	if err != nil {
+		fmt.Printf("Error creating service account: %v\n", err)
+		return nil, err
+	}
+
+	// step 3: return the result
+	serviceAccount.Name = name
+	fmt.Printf("Created service account %d with identity %d\n", serviceAccount.ID, serviceAccount.UserID)
+	return serviceAccount, nil
+}
+
+func (u *ServiceAccountRepositoryImpl) GetAll() ([]*ServiceAccount, error) {
+	fmt.Println("Fetching all service accounts in serviceAccount repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + serviceAccountColumns + serviceAccountFrom + " ORDER BY sa.id"
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	serviceAccounts := []*ServiceAccount{}
+	for rows.Next() {
+		serviceAccount := &ServiceAccount{}
+		err := rows.Scan(&serviceAccount.ID, &serviceAccount.UserID, &serviceAccount.ClientID, &serviceAccount.SecretHash, &serviceAccount.Description,
+			&serviceAccount.CreatedBy, &serviceAccount.CreatedAt, &serviceAccount.UpdatedAt, &serviceAccount.Name)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		serviceAccounts = append(serviceAccounts, serviceAccount)
+	}
+
+	// step 4: return the result
+	return serviceAccounts, rows.Err()
+}
+
+func (u *ServiceAccountRepositoryImpl) GetByID(id int64) (*ServiceAccount, error) {
+	fmt.Println("Fetching service account by id in serviceAccount repository.")
+
+	// step 1: prepare the query
+	query := "SELECT " + serviceAccountColumns + serviceAccountFrom + " AND sa.id = ?"
+
+	// step 2: execute the query
+	row := u.db.Raw(query, id).Row()
+
+	// step 3: process the result
+	return scanServiceAccount(row)
+}
+
+func (u *ServiceAccountRepositoryImpl) GetByClientID(clientId string) (*ServiceAccount, error) {
+	// step 1: prepare the query
+	query := "SELECT " + serviceAccountColumns + serviceAccountFrom + " AND sa.client_id = ?"
+
+	// step 2: execute the query
+	row := u.db.Raw(query, clientId).Row()
+
+	// step 3: process the result
+	return scanServiceAccount(row)
+}
+
+func (u *ServiceAccountRepositoryImpl) UpdateSecretHash(id int64, secretHash string) error {
+	fmt.Println("Rotating service account secret in serviceAccount repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE service_accounts SET secret_hash = ?, updated_at = NOW() WHERE deleted_at IS NULL AND id = ?"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, secretHash, id)
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error rotating service account secret: %v\n", result.Error)
+		return result.Error
+	}
+
+	// step 4: evaluate the result
+	if result.RowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Delete deletes the account along with its identity, which ends its role
+// assignments and API keys as well.
+func (u *ServiceAccountRepositoryImpl) Delete(id int64) (*ServiceAccount, error) {
+	fmt.Println("Deleting service account in serviceAccount repository.")
+
+	var serviceAccount *ServiceAccount
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		// step 1: delete the account
+		row := tx.Raw(`UPDATE service_accounts sa SET deleted_at = NOW(), updated_at = NOW() FROM users usr
+			WHERE usr.id = sa.user_id AND sa.deleted_at IS NULL AND sa.id = ?
+			RETURNING `+serviceAccountColumns, id).Row()
+		var err error
+		serviceAccount, err = scanServiceAccount(row)
+		if err != nil {
+			return err
+		}
+
+		// step 2: delete its identity
+		return tx.Exec("UPDATE users SET deleted_at = NOW() WHERE deleted_at IS NULL AND id = ?", serviceAccount.UserID).Error
+	})
+	if err != nil {
+		fmt.Printf("Error deleting service account: %v\n", err)
+		return nil, err
+	}
+
+	// step 3: return the result
+	fmt.Printf("Deleted service account %d with identity %d\n", serviceAccount.ID, serviceAccount.UserID)
+	return serviceAccount, nil
+}
+
+func scanServiceAccount(row *sql.Row) (*ServiceAccount, error) {
+	serviceAccount := &ServiceAccount{}
+	err := row.Scan(&serviceAccount.ID, &serviceAccount.UserID, &serviceAccount.ClientID, &serviceAccount.SecretHash, &serviceAccount.Description,
+		&serviceAccount.CreatedBy, &serviceAccount.CreatedAt, &serviceAccount.UpdatedAt, &serviceAccount.Name)
+	if err != nil {
+		return nil, err
+	}
+	return serviceAccount, nil
+}
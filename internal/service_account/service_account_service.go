@@ -0,0 +1,242 @@
+package serviceaccount
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	env "go_project_structure/config/env"
+	apikey "go_project_structure/internal/api_key"
+	"go_project_structure/internal/events"
+	revokedtoken "go_project_structure/internal/revoked_token"
+	"go_project_structure/internal/token"
+	userrole "go_project_structure/internal/user_role"
+	"time"
+)
+
+var ErrClientAuthenticationFailed = errors.New("service account client authentication failed")
+
+// ClientIDPrefix starts the client id of every service account.
+const ClientIDPrefix = "sa_"
+
+// Events published about service accounts, so that what they do can be told apart
+// from what people do.
+const (
+	CreatedEvent       = "service_account.created"
+	DeletedEvent       = "service_account.deleted"
+	SecretRotatedEvent = "service_account.secret_rotated"
+	TokenIssuedEvent   = "service_account.token_issued"
+)
+
+type ServiceAccountService interface {
+	Create(name string, description string, createdBy int64) (string, *ServiceAccount, error)
+	GetAll() ([]*ServiceAccount, error)
+	GetByID(id int64) (*ServiceAccount, error)
+	RotateSecret(id int64, rotatedBy int64) (string, *ServiceAccount, error)
+	Delete(id int64, deletedBy int64) error
+	IssueAccessToken(clientId string, secret string) (string, *ServiceAccount, error)
+	CreateApiKey(id int64, name string, scopes []string, expiresInDays int, organizationId int64) (string, *apikey.ApiKey, error)
+	GetApiKeys(id int64) ([]*apikey.ApiKey, error)
+	RevokeApiKey(id int64, apiKeyId int64) error
+}
+
+type ServiceAccountServiceImpl struct {
+	serviceAccountRepository ServiceAccountRepository
+	userRoleRepository       userrole.UserRoleRepository
+	apiKeyService            apikey.ApiKeyService
+	revokedTokenService      revokedtoken.RevokedTokenService
+	bus                      *events.Bus
+}
+
+func NewServiceAccountService(_serviceAccountRepository ServiceAccountRepository, _userRoleRepository userrole.UserRoleRepository, _apiKeyService apikey.ApiKeyService, _revokedTokenService revokedtoken.RevokedTokenService, _bus *events.Bus) ServiceAccountService {
+	return &ServiceAccountServiceImpl{
+		serviceAccountRepository: _serviceAccountRepository,
+		userRoleRepository:       _userRoleRepository,
+		apiKeyService:            _apiKeyService,
+		revokedTokenService:      _revokedTokenService,
+		bus:                      _bus,
+	}
+}
+
+// identityEmail is the placeholder email of the identity of an account. The users
+// table requires one, and the reserved .invalid domain can never receive mail.
+func identityEmail(clientId string) string {
+	return clientId + "@service-accounts.invalid"
+}
+
+// Create creates an account and returns its client secret, which is shown only here.
+func (ss *ServiceAccountServiceImpl) Create(name string, description string, createdBy int64) (string, *ServiceAccount, error) {
+	fmt.Println("Creating service account in serviceAccount service.")
+	clientId, err := randomString(12)
+	if err != nil {
+		return "", nil, err
+	}
+	clientId = ClientIDPrefix + clientId
+	secret, err := randomString(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	serviceAccount, err := ss.serviceAccountRepository.Create(name, identityEmail(clientId), description, clientId, hashSecret(secret), createdBy)
+	if err != nil {
+		fmt.Printf("Error creating service account: %v\n", err)
+		return "", nil, err
+	}
+	ss.publish(CreatedEvent, serviceAccount, map[string]interface{}{"created_by": createdBy})
+	return secret, serviceAccount, nil
+}
+
+func (ss *ServiceAccountServiceImpl) GetAll() ([]*ServiceAccount, error) {
+	fmt.Println("Fetching all service accounts in serviceAccount service.")
+	return ss.serviceAccountRepository.GetAll()
+}
+
+func (ss *ServiceAccountServiceImpl) GetByID(id int64) (*ServiceAccount, error) {
+	fmt.Println("Fetching service account by id in serviceAccount service.")
+	return ss.serviceAccountRepository.GetByID(id)
+}
+
+// RotateSecret replaces the client secret of an account. Access tokens issued with
+// the old secret are revoked with it.
+func (ss *ServiceAccountServiceImpl) RotateSecret(id int64, rotatedBy int64) (string, *ServiceAccount, error) {
+	fmt.Println("Rotating service account secret in serviceAccount service.")
+	serviceAccount, err := ss.serviceAccountRepository.GetByID(id)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomString(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := ss.serviceAccountRepository.UpdateSecretHash(id, hashSecret(secret)); err != nil {
+		return "", nil, err
+	}
+	if err := ss.revokedTokenService.RevokeUserTokens(int64(serviceAccount.UserID)); err != nil {
+		fmt.Printf("Error revoking access tokens: %v\n", err)
+		return "", nil, err
+	}
+	ss.publish(SecretRotatedEvent, serviceAccount, map[string]interface{}{"rotated_by": rotatedBy})
+	return secret, serviceAccount, nil
+}
+
+// Delete deletes an account. Its API keys stop working with its identity, and the
+// access tokens issued to it are revoked.
+func (ss *ServiceAccountServiceImpl) Delete(id int64, deletedBy int64) error {
+	fmt.Println("Deleting service account in serviceAccount service.")
+	serviceAccount, err := ss.serviceAccountRepository.Delete(id)
+	if err != nil {
+		return err
+	}
+	if err := ss.revokedTokenService.RevokeUserTokens(int64(serviceAccount.UserID)); err != nil {
+		fmt.Printf("Error revoking access tokens: %v\n", err)
+		return err
+	}
+	ss.publish(DeletedEvent, serviceAccount, map[string]interface{}{"deleted_by": deletedBy})
+	return nil
+}
+
+// IssueAccessToken serves the client credentials grant: an account that presents its
+// client id and secret gets an access token for its identity. No refresh token is
+// issued; the account simply asks again.
+func (ss *ServiceAccountServiceImpl) IssueAccessToken(clientId string, secret string) (string, *ServiceAccount, error) {
+	fmt.Println("Issuing access token in serviceAccount service.")
+	serviceAccount, err := ss.serviceAccountRepository.GetByClientID(clientId)
+	if err != nil {
+		fmt.Printf("Error fetching service account: %v\n", err)
+		return "", nil, ErrClientAuthenticationFailed
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(serviceAccount.SecretHash)) != 1 {
+		return "", nil, ErrClientAuthenticationFailed
+	}
+
+	userId := int64(serviceAccount.UserID)
+	roles, err := ss.userRoleRepository.GetUserRoles(userId, 0)
+	if err != nil {
+		fmt.Printf("Error fetching service account roles: %v\n", err)
+		return "", nil, err
+	}
+	roleNames := []string{}
+	for _, r := range roles {
+		roleNames = append(roleNames, r.Name)
+	}
+
+	var permissionNames []string
+	if env.GetBool("JWT_INCLUDE_PERMISSIONS", false) {
+		permissions, err := ss.userRoleRepository.GetUserPermissions(userId, 0)
+		if err != nil {
+			fmt.Printf("Error fetching service account permissions: %v\n", err)
+			return "", nil, err
+		}
+		permissionNames = []string{}
+		for _, p := range permissions {
+			permissionNames = append(permissionNames, p.Name)
+		}
+	}
+
+	claims, err := token.NewAccessClaims(userId, identityEmail(serviceAccount.ClientID), roleNames, permissionNames, 0, "")
+	if err != nil {
+		return "", nil, err
+	}
+	claims.SubjectType = token.SubjectServiceAccount
+	accessToken, err := token.SignAccessToken(claims)
+	if err != nil {
+		fmt.Printf("Error signing access token: %v\n", err)
+		return "", nil, err
+	}
+
+	ss.publish(TokenIssuedEvent, serviceAccount, map[string]interface{}{"token_id": claims.ID})
+	return accessToken, serviceAccount, nil
+}
+
+// CreateApiKey mints an API key for the identity of an account, scoped to the
+// permissions the account holds.
+func (ss *ServiceAccountServiceImpl) CreateApiKey(id int64, name string, scopes []string, expiresInDays int, organizationId int64) (string, *apikey.ApiKey, error) {
+	fmt.Println("Creating service account api key in serviceAccount service.")
+	serviceAccount, err := ss.serviceAccountRepository.GetByID(id)
+	if err != nil {
+		return "", nil, err
+	}
+	return ss.apiKeyService.Create(int64(serviceAccount.UserID), name, scopes, expiresInDays, organizationId)
+}
+
+func (ss *ServiceAccountServiceImpl) GetApiKeys(id int64) ([]*apikey.ApiKey, error) {
+	fmt.Println("Fetching service account api keys in serviceAccount service.")
+	serviceAccount, err := ss.serviceAccountRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return ss.apiKeyService.List(int64(serviceAccount.UserID))
+}
+
+func (ss *ServiceAccountServiceImpl) RevokeApiKey(id int64, apiKeyId int64) error {
+	fmt.Println("Revoking service account api key in serviceAccount service.")
+	serviceAccount, err := ss.serviceAccountRepository.GetByID(id)
+	if err != nil {
+		return err
+	}
+	return ss.apiKeyService.Revoke(apiKeyId, int64(serviceAccount.UserID))
+}
+
+func (ss *ServiceAccountServiceImpl) publish(name string, serviceAccount *ServiceAccount, data map[string]interface{}) {
+	data["service_account_id"] = serviceAccount.ID
+	data["user_id"] = serviceAccount.UserID
+	data["client_id"] = serviceAccount.ClientID
+	ss.bus.Publish(events.Event{Name: name, OccurredAt: time.Now(), Data: data})
+}
+
+func randomString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,53 @@
+package token
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ApiKeyPrefix starts every API key, which tells them apart from access tokens.
+const ApiKeyPrefix = "gpsk_"
+
+var ErrApiKeysDisabled = errors.New("API keys are not accepted")
+
+// ApiKeyAuthenticator returns the caller an API key belongs to.
+type ApiKeyAuthenticator func(apiKey string) (*Principal, error)
+
+var (
+	apiKeyMu            sync.RWMutex
+	apiKeyAuthenticator ApiKeyAuthenticator
+)
+
+// RegisterApiKeyAuthenticator makes Authenticate accept API keys. A later
+// authenticator replaces an earlier one.
+func RegisterApiKeyAuthenticator(authenticator ApiKeyAuthenticator) {
+	apiKeyMu.Lock()
+	defer apiKeyMu.Unlock()
+	apiKeyAuthenticator = authenticator
+}
+
+// IsApiKey reports whether a credential is an API key rather than an access token.
+func IsApiKey(credential string) bool {
+	return strings.HasPrefix(credential, ApiKeyPrefix)
+}
+
+// Authenticate returns the caller a bearer credential, an access token or an API
+// key, belongs to.
+func Authenticate(credential string) (*Principal, error) {
+	if !IsApiKey(credential) {
+		claims, err := ParseAccessToken(credential)
+		if err != nil {
+			return nil, err
+		}
+		return claims.Principal()
+	}
+
+	apiKeyMu.RLock()
+	authenticator := apiKeyAuthenticator
+	apiKeyMu.RUnlock()
+	if authenticator == nil {
+		return nil, ErrApiKeysDisabled
+	}
+	return authenticator(credential)
+}
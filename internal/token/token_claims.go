@@ -0,0 +1,266 @@
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	env "go_project_structure/config/env"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AccessClaims are the claims of an access token. The registered claims carry the
+// user id as sub, the issuer, the audience, the issue and expiry times and a unique
+// jti. Roles and Permissions describe the user when the token was issued; they are
+// informational and every check still goes through the role graph. SessionID names
+// the login session, i.e. the refresh token family, the token was issued in.
+// SubjectType tells service accounts apart from users; it is omitted for users.
+// ClientID is set on tokens issued to an OpenID Connect client (RFC 9068), which
+// carry the audience the client may use them with instead of this API's.
+type AccessClaims struct {
+	Email          string   `json:"email"`
+	Roles          []string `json:"roles"`
+	Permissions    []string `json:"permissions,omitempty"`
+	OrganizationID int64    `json:"org_id,omitempty"`
+	SessionID      string   `json:"sid,omitempty"`
+	SubjectType    string   `json:"sub_type,omitempty"`
+	ClientID       string   `json:"client_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// The kinds of subject a principal can be.
+const (
+	SubjectUser           = "user"
+	SubjectServiceAccount = "service_account"
+)
+
+// Principal is the authenticated caller of a request, as described by its access token
+// or API key. A caller authenticated with an API key has its ApiKeyID set and may only
+// use the permissions in Scopes; Scopes is nil for access tokens, which are unrestricted.
+type Principal struct {
+	UserID         int64
+	Email          string
+	Roles          []string
+	Permissions    []string
+	OrganizationID int64
+	SessionID      string
+	TokenID        string
+	IssuedAt       time.Time
+	ExpiresAt      time.Time
+	ApiKeyID       uint
+	Scopes         []string
+	// SubjectType is SubjectUser or SubjectServiceAccount
+	SubjectType string
+}
+
+// IsServiceAccount reports whether the caller is a service account rather than a person.
+func (p *Principal) IsServiceAccount() bool {
+	return p.SubjectType == SubjectServiceAccount
+}
+
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// RevocationCheck reports whether an otherwise valid access token has been revoked.
+type RevocationCheck func(claims *AccessClaims) (bool, error)
+
+var (
+	revocationMu    sync.RWMutex
+	revocationCheck RevocationCheck
+)
+
+// RegisterRevocationCheck makes ParseAccessToken refuse the tokens the check reports
+// as revoked. A later check replaces an earlier one.
+func RegisterRevocationCheck(check RevocationCheck) {
+	revocationMu.Lock()
+	defer revocationMu.Unlock()
+	revocationCheck = check
+}
+
+func issuer() string {
+	return env.GetString("JWT_ISSUER", "go_project_structure")
+}
+
+func audience() string {
+	return env.GetString("JWT_AUDIENCE", "go_project_structure")
+}
+
+func secret() []byte {
+	return []byte(env.GetString("JWT_SECRET", "default_secret_key"))
+}
+
+// AccessTokenTTL is how long an access token stays valid after it is issued.
+func AccessTokenTTL() time.Duration {
+	return time.Duration(env.GetInt("ACCESS_TOKEN_TTL_MINUTES", 15)) * time.Minute
+}
+
+// NewTokenID returns a random token id for the jti claim.
+func NewTokenID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// NewAccessClaims builds the claims of an access token for a user, valid from now
+// for AccessTokenTTL.
+func NewAccessClaims(userId int64, email string, roles []string, permissions []string, organizationId int64, sessionId string) (*AccessClaims, error) {
+	tokenId, err := NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &AccessClaims{
+		Email:          email,
+		Roles:          roles,
+		Permissions:    permissions,
+		OrganizationID: organizationId,
+		SessionID:      sessionId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(userId, 10),
+			Issuer:    issuer(),
+			Audience:  jwt.ClaimStrings{audience()},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL())),
+			ID:        tokenId,
+		},
+	}, nil
+}
+
+// NewClientAccessClaims builds the claims of an access token issued to an OpenID
+// Connect client for a user, valid from now for AccessTokenTTL. The token is only
+// accepted by ParseClientAccessToken with the same audience.
+func NewClientAccessClaims(userId int64, clientId string, audience string, sessionId string) (*AccessClaims, error) {
+	claims, err := NewAccessClaims(userId, "", nil, nil, 0, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	claims.ClientID = clientId
+	claims.Audience = jwt.ClaimStrings{audience}
+	return claims, nil
+}
+
+// SignAccessToken signs access token claims with the active signing key.
+func SignAccessToken(claims *AccessClaims) (string, error) {
+	return signingKeys().sign(claims)
+}
+
+// Sign signs other tokens of this issuer, such as OpenID Connect ID tokens, with the
+// active signing key so that they verify against the published JWKS.
+func Sign(claims jwt.Claims) (string, error) {
+	return signingKeys().sign(claims)
+}
+
+// SigningAlgorithm is the algorithm of the active signing key.
+func SigningAlgorithm() string {
+	keySet := signingKeys()
+	if keySet.Active == nil {
+		return jwt.SigningMethodHS256.Alg()
+	}
+	return keySet.Active.Method.Alg()
+}
+
+// ParseAccessToken verifies the signature (see KeySet), issuer, audience and expiry of an access
+// token and returns its claims. Tokens without an expiry or a subject are refused,
+// and so are tokens the registered revocation check reports as revoked and tokens
+// issued to an OpenID Connect client.
+func ParseAccessToken(tokenString string) (*AccessClaims, error) {
+	claims, err := parseAccessToken(tokenString, audience())
+	if err != nil {
+		return nil, err
+	}
+	if claims.ClientID != "" {
+		return nil, fmt.Errorf("the token was issued to client %q", claims.ClientID)
+	}
+	return claims, nil
+}
+
+// ParseClientAccessToken verifies an access token issued to an OpenID Connect client
+// the way ParseAccessToken does, against the audience it was issued for.
+func ParseClientAccessToken(tokenString string, audience string) (*AccessClaims, error) {
+	claims, err := parseAccessToken(tokenString, audience)
+	if err != nil {
+		return nil, err
+	}
+	if claims.ClientID == "" {
+		return nil, fmt.Errorf("the token was not issued to a client")
+	}
+	return claims, nil
+}
+
+func parseAccessToken(tokenString string, audience string) (*AccessClaims, error) {
+	claims := &AccessClaims{}
+	keySet := signingKeys()
+	_, err := jwt.ParseWithClaims(tokenString, claims, keySet.verificationKey,
+		jwt.WithValidMethods(keySet.methods()),
+		jwt.WithIssuer(issuer()),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := claims.Principal(); err != nil {
+		return nil, err
+	}
+
+	revocationMu.RLock()
+	check := revocationCheck
+	revocationMu.RUnlock()
+	if check != nil {
+		revoked, err := check(claims)
+		if err != nil {
+			return nil, fmt.Errorf("revocation check failed: %w", err)
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+	return claims, nil
+}
+
+// Principal describes the caller the claims were issued to.
+func (c *AccessClaims) Principal() (*Principal, error) {
+	userId, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil || userId <= 0 {
+		return nil, fmt.Errorf("invalid token subject %q", c.Subject)
+	}
+	principal := &Principal{
+		UserID:         userId,
+		Email:          c.Email,
+		Roles:          c.Roles,
+		Permissions:    c.Permissions,
+		OrganizationID: c.OrganizationID,
+		SessionID:      c.SessionID,
+		TokenID:        c.ID,
+		SubjectType:    c.SubjectType,
+	}
+	if principal.SubjectType == "" {
+		principal.SubjectType = SubjectUser
+	}
+	if c.IssuedAt != nil {
+		principal.IssuedAt = c.IssuedAt.Time
+	}
+	if c.ExpiresAt != nil {
+		principal.ExpiresAt = c.ExpiresAt.Time
+	}
+	return principal, nil
+}
+
+// WithPrincipal stores the authenticated caller in the context.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, "principal", principal)
+}
+
+// PrincipalFromContext returns the authenticated caller stored by JwtAuthMiddleware.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value("principal").(*Principal)
+	return principal, ok && principal != nil
+}
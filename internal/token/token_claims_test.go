@@ -0,0 +1,45 @@
+package token
+
+import "testing"
+
+func TestClientAccessTokens(t *testing.T) {
+	const userInfo = "https://op.example.com/userinfo"
+
+	firstParty, err := NewAccessClaims(7, "user@example.com", []string{"user"}, nil, 0, "session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClientAccessClaims(7, "client-1", userInfo, "session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstPartyToken, err := SignAccessToken(firstParty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientToken, err := SignAccessToken(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParseAccessToken(firstPartyToken); err != nil {
+		t.Errorf("ParseAccessToken refused a first-party token: %v", err)
+	}
+	if _, err := ParseAccessToken(clientToken); err == nil {
+		t.Error("ParseAccessToken accepted a token issued to a client")
+	}
+
+	claims, err := ParseClientAccessToken(clientToken, userInfo)
+	if err != nil {
+		t.Fatalf("ParseClientAccessToken: %v", err)
+	}
+	if claims.ClientID != "client-1" || claims.Subject != "7" {
+		t.Errorf("claims = client %q subject %q, want client-1 and 7", claims.ClientID, claims.Subject)
+	}
+	if _, err := ParseClientAccessToken(clientToken, "https://other.example.com/userinfo"); err == nil {
+		t.Error("ParseClientAccessToken accepted a token of another audience")
+	}
+	if _, err := ParseClientAccessToken(firstPartyToken, audience()); err == nil {
+		t.Error("ParseClientAccessToken accepted a first-party token")
+	}
+}
@@ -0,0 +1,18 @@
+package token
+
+import (
+	utils "go_project_structure/utils"
+	"net/http"
+)
+
+type TokenController struct{}
+
+func NewTokenController() *TokenController {
+	return &TokenController{}
+}
+
+// Jwks serves the public signing keys as a bare JWK Set, the format verifiers expect.
+func (tc *TokenController) Jwks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.WriteJSONResponse(w, http.StatusOK, PublicKeys())
+}
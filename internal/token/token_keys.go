@@ -0,0 +1,283 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	env "go_project_structure/config/env"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is one key access tokens are signed or verified with, identified by the
+// kid header of the tokens.
+type SigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	// Private signs tokens; Public verifies them and is published in the JWKS.
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// KeySet holds the active signing key and the previous keys that still verify the
+// tokens issued before the rotation until the grace period ends.
+type KeySet struct {
+	Active    *SigningKey
+	Previous  []*SigningKey
+	RotatedAt time.Time
+	Grace     time.Duration
+
+	// secret is set instead of the keys while no key directory is configured.
+	secret []byte
+}
+
+var loadedKeys atomic.Pointer[KeySet]
+
+// LoadSigningKeys reads the signing keys from JWT_KEYS_DIR, one PEM encoded RSA or
+// Ed25519 private key per <kid>.pem file, and makes JWT_ACTIVE_KID the key that
+// signs new tokens. The other keys keep verifying tokens issued before
+// JWT_KEY_ROTATED_AT (RFC 3339, the load time when unset) for JWT_KEY_GRACE_MINUTES
+// (the access token lifetime when unset). Without JWT_KEYS_DIR tokens are signed
+// with HS256 and JWT_SECRET, and no keys are published.
+func LoadSigningKeys() error {
+	keySet, err := loadKeySet()
+	if err != nil {
+		return err
+	}
+	loadedKeys.Store(keySet)
+	return nil
+}
+
+func loadKeySet() (*KeySet, error) {
+	dir := env.GetString("JWT_KEYS_DIR", "")
+	if dir == "" {
+		fmt.Println("JWT_KEYS_DIR is not set, signing access tokens with HS256.")
+		return &KeySet{secret: secret()}, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no signing keys found in %s", dir)
+	}
+
+	activeId := env.GetString("JWT_ACTIVE_KID", "")
+	if activeId == "" && len(paths) == 1 {
+		activeId = strings.TrimSuffix(filepath.Base(paths[0]), ".pem")
+	}
+
+	keySet := &KeySet{
+		RotatedAt: time.Now(),
+		Grace:     time.Duration(env.GetInt("JWT_KEY_GRACE_MINUTES", int(AccessTokenTTL()/time.Minute))) * time.Minute,
+	}
+	if rotatedAt := env.GetString("JWT_KEY_ROTATED_AT", ""); rotatedAt != "" {
+		keySet.RotatedAt, err = time.Parse(time.RFC3339, rotatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_KEY_ROTATED_AT: %w", err)
+		}
+	}
+
+	for _, path := range paths {
+		key, err := readSigningKey(path)
+		if err != nil {
+			return nil, err
+		}
+		if key.ID == activeId {
+			keySet.Active = key
+		} else {
+			keySet.Previous = append(keySet.Previous, key)
+		}
+	}
+	if keySet.Active == nil {
+		return nil, fmt.Errorf("active signing key %q not found in %s", activeId, dir)
+	}
+
+	fmt.Printf("Signing access tokens with key %s (%s), %d previous keys\n", keySet.Active.ID, keySet.Active.Method.Alg(), len(keySet.Previous))
+	return keySet, nil
+}
+
+// readSigningKey reads a PKCS #8 (RSA or Ed25519) or PKCS #1 (RSA) private key. The
+// file name without .pem is the kid.
+func readSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", path)
+	}
+
+	var private interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	key := &SigningKey{ID: strings.TrimSuffix(filepath.Base(path), ".pem")}
+	switch private := private.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("%s: RSA keys must have at least 2048 bits", path)
+		}
+		key.Method = jwt.SigningMethodRS256
+		key.Private = private
+		key.Public = &private.PublicKey
+	case ed25519.PrivateKey:
+		key.Method = jwt.SigningMethodEdDSA
+		key.Private = private
+		key.Public = private.Public()
+	default:
+		return nil, fmt.Errorf("%s: only RSA and Ed25519 keys are supported", path)
+	}
+	return key, nil
+}
+
+// signingKeys returns the loaded key set, or the HS256 one when LoadSigningKeys has
+// not run.
+func signingKeys() *KeySet {
+	if keySet := loadedKeys.Load(); keySet != nil {
+		return keySet
+	}
+	return &KeySet{secret: secret()}
+}
+
+// AsymmetricSigning reports whether tokens are signed with a key published in the
+// JWKS rather than with JWT_SECRET, so that others can verify them.
+func AsymmetricSigning() bool {
+	return signingKeys().Active != nil
+}
+
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	if ks.Active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.secret)
+	}
+	token := jwt.NewWithClaims(ks.Active.Method, claims)
+	token.Header["kid"] = ks.Active.ID
+	return token.SignedString(ks.Active.Private)
+}
+
+// methods lists the algorithms tokens may be signed with.
+func (ks *KeySet) methods() []string {
+	if ks.Active == nil {
+		return []string{jwt.SigningMethodHS256.Alg()}
+	}
+	methods := []string{ks.Active.Method.Alg()}
+	for _, key := range ks.Previous {
+		methods = append(methods, key.Method.Alg())
+	}
+	return methods
+}
+
+// inGrace reports whether the previous keys still verify tokens.
+func (ks *KeySet) inGrace() bool {
+	return time.Now().Before(ks.RotatedAt.Add(ks.Grace))
+}
+
+// verificationKey is the jwt.Keyfunc of the key set. A previous key only verifies
+// tokens issued before the rotation, and only during the grace period.
+func (ks *KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	if ks.Active == nil {
+		return ks.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == ks.Active.ID {
+		return checkMethod(ks.Active, token)
+	}
+	for _, key := range ks.Previous {
+		if key.ID != kid {
+			continue
+		}
+		if !ks.inGrace() {
+			return nil, fmt.Errorf("signing key %q has been retired", kid)
+		}
+		issuedAt, err := token.Claims.GetIssuedAt()
+		if err != nil || issuedAt == nil || !issuedAt.Before(ks.RotatedAt) {
+			return nil, fmt.Errorf("signing key %q was not active when the token was issued", kid)
+		}
+		return checkMethod(key, token)
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func checkMethod(key *SigningKey, token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("signing key %q does not sign with %s", key.ID, token.Method.Alg())
+	}
+	return key.Public, nil
+}
+
+// JWK is the public part of a signing key as published in the JWKS (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	// RSA
+	Modulus  string `json:"n,omitempty"`
+	Exponent string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS is the JSON Web Key Set served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKeys returns the keys other services verify access tokens with: the active
+// key and, during the grace period, the previous ones.
+func PublicKeys() JWKS {
+	keySet := signingKeys()
+	jwks := JWKS{Keys: []JWK{}}
+	if keySet.Active == nil {
+		return jwks
+	}
+
+	jwks.Keys = append(jwks.Keys, newJWK(keySet.Active))
+	if keySet.inGrace() {
+		for _, key := range keySet.Previous {
+			jwks.Keys = append(jwks.Keys, newJWK(key))
+		}
+	}
+	return jwks
+}
+
+func newJWK(key *SigningKey) JWK {
+	jwk := JWK{Use: "sig", Algorithm: key.Method.Alg(), KeyID: key.ID}
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.Modulus = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.Exponent = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
@@ -0,0 +1,205 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeSigningKey(t *testing.T, dir string, kid string, key crypto.Signer) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// loadTestKeys loads the keys of dir with kid active, rotated at rotatedAt, and puts
+// the HS256 signing back when the test ends.
+func loadTestKeys(t *testing.T, dir string, kid string, rotatedAt time.Time, graceMinutes string) {
+	t.Helper()
+	t.Setenv("JWT_KEYS_DIR", dir)
+	t.Setenv("JWT_ACTIVE_KID", kid)
+	t.Setenv("JWT_KEY_ROTATED_AT", rotatedAt.Format(time.RFC3339))
+	t.Setenv("JWT_KEY_GRACE_MINUTES", graceMinutes)
+	if err := LoadSigningKeys(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { loadedKeys.Store(nil) })
+}
+
+// signedAt signs claims issued at issuedAt with key under kid, as the service did or
+// as a forger would.
+func signedAt(t *testing.T, issuedAt time.Time, method jwt.SigningMethod, kid string, key interface{}) string {
+	t.Helper()
+	claims, err := NewAccessClaims(7, "user@example.com", []string{"user"}, nil, 0, "session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims.IssuedAt = jwt.NewNumericDate(issuedAt)
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func newTestKeys(t *testing.T) (string, ed25519.PrivateKey, *rsa.PrivateKey) {
+	t.Helper()
+	_, retired, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	active, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeSigningKey(t, dir, "2026-01", retired)
+	writeSigningKey(t, dir, "2026-02", active)
+	return dir, retired, active
+}
+
+func TestKeyRotation(t *testing.T) {
+	dir, retired, active := newTestKeys(t)
+	rotatedAt := time.Now().Add(-time.Minute)
+	loadTestKeys(t, dir, "2026-02", rotatedAt, "15")
+
+	// new tokens are signed with the active key
+	claims, _ := NewAccessClaims(7, "user@example.com", []string{"user"}, nil, 0, "session")
+	fresh, err := SignAccessToken(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(fresh, &AccessClaims{})
+	if err != nil || parsed.Header["kid"] != "2026-02" || parsed.Method.Alg() != "RS256" {
+		t.Fatalf("new token has header %v, want kid 2026-02 and RS256", parsed.Header)
+	}
+	if _, err := ParseAccessToken(fresh); err != nil {
+		t.Errorf("ParseAccessToken of a new token: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"retired key, issued before the rotation", signedAt(t, rotatedAt.Add(-5*time.Minute), jwt.SigningMethodEdDSA, "2026-01", retired), true},
+		{"retired key, issued after the rotation", signedAt(t, rotatedAt.Add(30*time.Second), jwt.SigningMethodEdDSA, "2026-01", retired), false},
+		{"active key, issued before the rotation", signedAt(t, rotatedAt.Add(-5*time.Minute), jwt.SigningMethodRS256, "2026-02", active), true},
+		{"unknown kid", signedAt(t, rotatedAt.Add(-5*time.Minute), jwt.SigningMethodEdDSA, "2025-12", retired), false},
+		{"no kid", signedAt(t, rotatedAt.Add(-5*time.Minute), jwt.SigningMethodEdDSA, "", retired), false},
+		{"retired key under the active kid", signedAt(t, time.Now(), jwt.SigningMethodEdDSA, "2026-02", retired), false},
+		{"HS256 with the secret", signedAt(t, time.Now(), jwt.SigningMethodHS256, "2026-02", secret()), false},
+	}
+	for _, tt := range tests {
+		_, err := ParseAccessToken(tt.token)
+		if tt.ok && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("%s: ParseAccessToken accepted the token", tt.name)
+		}
+	}
+}
+
+func TestRetiredKeyAfterGrace(t *testing.T) {
+	dir, retired, _ := newTestKeys(t)
+	rotatedAt := time.Now().Add(-20 * time.Minute)
+	loadTestKeys(t, dir, "2026-02", rotatedAt, "15")
+
+	old := signedAt(t, rotatedAt.Add(-5*time.Minute), jwt.SigningMethodEdDSA, "2026-01", retired)
+	if _, err := ParseAccessToken(old); err == nil || !strings.Contains(err.Error(), "retired") {
+		t.Errorf("ParseAccessToken after the grace period = %v, want the key to be retired", err)
+	}
+}
+
+func TestPublicKeys(t *testing.T) {
+	if jwks := PublicKeys(); len(jwks.Keys) != 0 {
+		t.Errorf("PublicKeys with HS256 = %v, want none", jwks.Keys)
+	}
+
+	dir, retired, active := newTestKeys(t)
+	loadTestKeys(t, dir, "2026-02", time.Now().Add(-time.Minute), "15")
+	jwks := PublicKeys()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("PublicKeys during the grace period = %d keys, want 2", len(jwks.Keys))
+	}
+
+	rsaKey, edKey := jwks.Keys[0], jwks.Keys[1]
+	if rsaKey.KeyID != "2026-02" || rsaKey.KeyType != "RSA" || rsaKey.Algorithm != "RS256" || rsaKey.Use != "sig" {
+		t.Errorf("active key = %+v", rsaKey)
+	}
+	modulus, _ := base64.RawURLEncoding.DecodeString(rsaKey.Modulus)
+	exponent, _ := base64.RawURLEncoding.DecodeString(rsaKey.Exponent)
+	if new(big.Int).SetBytes(modulus).Cmp(active.N) != 0 || new(big.Int).SetBytes(exponent).Int64() != int64(active.E) {
+		t.Error("the RSA key does not publish the modulus and exponent of the active key")
+	}
+	if edKey.KeyID != "2026-01" || edKey.KeyType != "OKP" || edKey.Curve != "Ed25519" || edKey.Algorithm != "EdDSA" {
+		t.Errorf("previous key = %+v", edKey)
+	}
+	if x, _ := base64.RawURLEncoding.DecodeString(edKey.X); !ed25519.PublicKey(x).Equal(retired.Public()) {
+		t.Error("the Ed25519 key does not publish the public key of the previous key")
+	}
+	if rsaKey.Exponent == "" || edKey.Modulus != "" {
+		t.Error("a key publishes the fields of another key type")
+	}
+
+	// after the grace period only the active key is published
+	loadTestKeys(t, dir, "2026-02", time.Now().Add(-time.Hour), "15")
+	if jwks := PublicKeys(); len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != "2026-02" {
+		t.Errorf("PublicKeys after the grace period = %v, want only 2026-02", jwks.Keys)
+	}
+}
+
+func TestLoadSigningKeysRejects(t *testing.T) {
+	dir, _, _ := newTestKeys(t)
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weakDir := t.TempDir()
+	writeSigningKey(t, weakDir, "weak", weak)
+	notPem := t.TempDir()
+	os.WriteFile(filepath.Join(notPem, "key.pem"), []byte("not a key"), 0o600)
+
+	tests := []struct {
+		name      string
+		dir       string
+		kid       string
+		rotatedAt string
+	}{
+		{"no keys", t.TempDir(), "", ""},
+		{"unknown active kid", dir, "2026-03", ""},
+		{"several keys and no active kid", dir, "", ""},
+		{"RSA key below 2048 bits", weakDir, "weak", ""},
+		{"file that is not PEM", notPem, "key", ""},
+		{"invalid rotation time", dir, "2026-02", "yesterday"},
+	}
+	for _, tt := range tests {
+		t.Setenv("JWT_KEYS_DIR", tt.dir)
+		t.Setenv("JWT_ACTIVE_KID", tt.kid)
+		t.Setenv("JWT_KEY_ROTATED_AT", tt.rotatedAt)
+		if _, err := loadKeySet(); err == nil {
+			t.Errorf("%s: loadKeySet accepted the keys", tt.name)
+		}
+	}
+}
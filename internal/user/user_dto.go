@@ -19,8 +19,28 @@ type UpdateUserRequest struct {
 type LoginUserRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8,max=20"`
+	// OrganizationID pins the issued token to an organization
+	OrganizationID int64 `json:"organization_id,omitempty"`
 }
 
+// LoginUserResponse carries a short-lived access token in Token and the refresh
+// token that renews it at /token/refresh. ExpiresIn is the access token lifetime in seconds.
 type LoginUserResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// TokenPair is what a login or a refresh issues. SessionID is the refresh token
+// family of the session; UserID is set by a refresh, whose caller does not know the user.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+	SessionID    string
+	UserID       int64
 }
\ No newline at end of file
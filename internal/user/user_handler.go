@@ -1,8 +1,12 @@
 package user
 
 import (
+	"errors"
+	refreshtoken "go_project_structure/internal/refresh_token"
+	"go_project_structure/internal/token"
 	utils "go_project_structure/utils"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -37,23 +41,59 @@ func (uc *UserController) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJsonSuccessResponse(w, http.StatusOK, "User registration successful", responsePayload)
 }
 
-func (uc *UserController) LoginUser(w http.ResponseWriter, r *http.Request) {
-	var requestPayload = LoginUserRequest{}
-	err := utils.ReadJsonBody(r, &requestPayload)
+func (uc *UserController) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	requestPayload := r.Context().Value("refresh_token_payload").(RefreshTokenRequest)
+
+	tokens, err := uc.UserService.RefreshToken(requestPayload.RefreshToken)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, refreshtoken.ErrRefreshTokenInvalid) || errors.Is(err, refreshtoken.ErrRefreshTokenReused) {
+			status = http.StatusUnauthorized
+		}
+		utils.WriteJsonErrorResponse(w, status, "Token refresh failed", err)
+		return
+	}
+	responsePayload := LoginUserResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Token refreshed", responsePayload)
+}
+
+func (uc *UserController) Logout(w http.ResponseWriter, r *http.Request) {
+	principal, ok := token.PrincipalFromContext(r.Context())
+	if !ok {
+		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Unauthorized", errors.New("missing principal"))
+		return
+	}
+	// an API key has no session to end; it is revoked at /api-keys/{id}
+	if principal.ApiKeyID != 0 {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "API keys cannot log out", errors.New("API keys have no session to end"))
+		return
+	}
+
+	err := uc.UserService.Logout(principal)
 	if err != nil {
-		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", err)
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Logout failed", err)
 		return
 	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Logout successful", nil)
+}
 
-	token, err := uc.UserService.LoginUser(requestPayload.Email, requestPayload.Password)
+func (uc *UserController) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		utils.WriteJsonErrorResponse(w, http.StatusUnauthorized, "Login failed", err)
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid user id", err)
 		return
 	}
-	responsePayload := LoginUserResponse{
-		Token: token,
+
+	err = uc.UserService.RevokeAllSessions(userId)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Session revocation failed", err)
+		return
 	}
-	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Login successful", responsePayload)
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "All sessions of user revoked", nil)
 }
 
 func (uc *UserController) GetUserById(w http.ResponseWriter, r *http.Request) {
@@ -27,6 +27,28 @@ func UserRegisterRequestValidator(next http.Handler) http.Handler {
 	})
 }
 
+func RefreshTokenRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = RefreshTokenRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("refresh token payload received.")
+
+		if RequestPayload.RefreshToken == "" || len(RequestPayload.RefreshToken) > 255 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("refresh_token is required"))
+			return
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "refresh_token_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func UserUpdateRequestValidator(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var RequestPayload = UpdateUserRequest{}
@@ -1,12 +1,31 @@
 package user
 
 import (
+	"strconv"
+
 	"gorm.io/gorm"
 )
 
 type User struct {
 	gorm.Model
 	Name     string `gorm:"size:255;not null"`
-	Email    string `gorm:"size:255;unique;not null"`
+	Email    string `gorm:"size:255;not null;uniqueIndex:idx_users_email_live,where:deleted_at IS NULL"`
 	Password string `gorm:"size:255;not null"`
+	// Kind is token.SubjectUser for people and token.SubjectServiceAccount for the
+	// identities of service accounts, which have no password and cannot log in.
+	Kind string `gorm:"size:32;not null;default:user"`
+}
+
+// OwnsAccount is the ownership rule of the user resource: every user owns their own account.
+func OwnsAccount(userId int64, resourceId string) bool {
+	return resourceId == strconv.FormatInt(userId, 10)
+}
+
+// AccountAttributes are the condition attributes of a user account: its owner is the user itself.
+func AccountAttributes(resourceId string) map[string]interface{} {
+	id, err := strconv.ParseInt(resourceId, 10, 64)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{"owner_id": id}
 }
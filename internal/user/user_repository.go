@@ -85,14 +85,14 @@ func (u *UserRepositoryImpl) GetByID(id string) (*User, error) {
 	fmt.Println("Fetching user by id in user repository.")
 
 	// step 1: prepare the query
-	query := "SELECT id, name, email, created_at, updated_at FROM users WHERE deleted_at IS NULL AND id = ?"
+	query := "SELECT id, name, email, kind, created_at, updated_at FROM users WHERE deleted_at IS NULL AND id = ?"
 
 	// step 2: execute the query
 	row := u.db.Raw(query, id).Row()
 
 	// step 3: process the result
 	user := &User{}
-	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Kind, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			fmt.Println("User not found.")
@@ -111,7 +111,8 @@ func (u *UserRepositoryImpl) GetAll() ([]*User, error) {
 	fmt.Println("Fetching all users in user repository.")
 
 	// step 1: prepare the query
-	query := "SELECT id, name, email, created_at, updated_at FROM users WHERE deleted_at IS NULL"
+	// service accounts are listed at /service-accounts
+	query := "SELECT id, name, email, kind, created_at, updated_at FROM users WHERE deleted_at IS NULL AND kind = 'user'"
 
 	// step 2: execute the query
 	rows, err := u.db.Raw(query).Rows()
@@ -255,14 +256,15 @@ func (u *UserRepositoryImpl) GetByEmail(email string) (*User, error) {
 	fmt.Println("Fetching user by email in user repository.")
 
 	// step 1: prepare the query
-	query := "SELECT name, email, password FROM users WHERE deleted_at IS NULL AND email = ?"
+	// service accounts have no email of their own and never log in with one
+	query := "SELECT id, name, email, password, kind FROM users WHERE deleted_at IS NULL AND kind = 'user' AND email = ?"
 
 	// step 2: execute the query
 	row := u.db.Raw(query, email).Row()
 
 	// step 3: process the result
 	user := &User{}
-	err := row.Scan(&user.Name, &user.Email, &user.Password)
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Kind)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			fmt.Println("User not found.")
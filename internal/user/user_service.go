@@ -3,14 +3,20 @@ package user
 import (
 	"fmt"
 	env "go_project_structure/config/env"
+	refreshtoken "go_project_structure/internal/refresh_token"
+	revokedtoken "go_project_structure/internal/revoked_token"
+	"go_project_structure/internal/token"
+	userrole "go_project_structure/internal/user_role"
 	"go_project_structure/utils"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type UserService interface {
 	CreateUser(username string, email string, password string) error
-	LoginUser(email string, password string) (string, error)
+	Authenticate(email string, password string) (*User, error)
+	StartSession(user *User, organizationId int64) (*TokenPair, error)
+	RefreshToken(refreshToken string) (*TokenPair, error)
+	Logout(principal *token.Principal) error
+	RevokeAllSessions(userId int64) error
 	GetUserById(id string) (*User, error)
 	GetAllUsers() ([]*User, error)
 	UpdateUser(id string, username *string, email *string) (string, error)
@@ -19,12 +25,18 @@ type UserService interface {
 }
 
 type UserServiceImpl struct {
-	userRepository UserRepository
+	userRepository      UserRepository
+	userRoleRepository  userrole.UserRoleRepository
+	refreshTokenService refreshtoken.RefreshTokenService
+	revokedTokenService revokedtoken.RevokedTokenService
 }
 
-func NewUserService(_userRepository UserRepository) UserService {
+func NewUserService(_userRepository UserRepository, _userRoleRepository userrole.UserRoleRepository, _refreshTokenService refreshtoken.RefreshTokenService, _revokedTokenService revokedtoken.RevokedTokenService) UserService {
 	return &UserServiceImpl{
-		userRepository: _userRepository,
+		userRepository:      _userRepository,
+		userRoleRepository:  _userRoleRepository,
+		refreshTokenService: _refreshTokenService,
+		revokedTokenService: _revokedTokenService,
 	}
 }
 
@@ -50,30 +62,165 @@ func (us *UserServiceImpl) CreateUser(username string, email string, password st
 	return nil
 }
 
-func (us *UserServiceImpl) LoginUser(email string, password string) (string, error) {
-	fmt.Println("Logging in user in user service.")
+// Authenticate returns the user the credentials belong to.
+func (us *UserServiceImpl) Authenticate(email string, password string) (*User, error) {
 	user, err := us.userRepository.GetByEmail(email)
 	if err != nil {
 		fmt.Printf("Error fetching user by email: %v\n", err)
-		return "", err
+		return nil, err
 	}
 
 	IsPasswordValid := utils.CheckPasswordHash(password, user.Password)
 	if !IsPasswordValid {
 		fmt.Println("Invalid password provided.")
-		return "", fmt.Errorf("invalid credentials")
+		return nil, fmt.Errorf("invalid credentials")
 	}
+	return user, nil
+}
 
-	payload := jwt.MapClaims{
-		"email": user.Email,
+// StartSession issues a short-lived access token and a refresh token that renews it.
+// A session started for an organization pins every access token it issues to that
+// organization.
+func (us *UserServiceImpl) StartSession(user *User, organizationId int64) (*TokenPair, error) {
+	refreshToken, session, err := us.refreshTokenService.Issue(int64(user.ID), organizationId)
+	if err != nil {
+		fmt.Printf("Error issuing refresh token: %v\n", err)
+		return nil, err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
-	tokenString, tokenErr := token.SignedString([]byte(env.GetString("JWT_SECRET", "default_secret_key")))
-	if tokenErr != nil {
-		fmt.Printf("Error signing JWT token: %v\n", tokenErr)
-		return "", tokenErr
+	accessToken, err := us.issueAccessToken(int64(user.ID), user.Email, organizationId, session.FamilyID)
+	if err != nil {
+		return nil, err
 	}
+
 	fmt.Println("User logged in successfully.")
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(token.AccessTokenTTL().Seconds()),
+		SessionID:    session.FamilyID,
+	}, nil
+}
+
+// RefreshToken rotates a refresh token and issues a fresh access token with the
+// user's current roles. The session ends if the user has been deleted since.
+func (us *UserServiceImpl) RefreshToken(refreshToken string) (*TokenPair, error) {
+	fmt.Println("Refreshing token in user service.")
+	// sessions of OpenID Connect clients are refreshed by their client at /oauth/token
+	issued, err := us.refreshTokenService.Lookup(refreshToken)
+	if err != nil {
+		fmt.Printf("Error looking up refresh token: %v\n", err)
+		return nil, err
+	}
+	if issued.ClientID != nil {
+		fmt.Printf("Refresh token of client %s presented to /token/refresh\n", *issued.ClientID)
+		return nil, refreshtoken.ErrRefreshTokenInvalid
+	}
+
+	nextRefreshToken, rotated, err := us.refreshTokenService.Rotate(refreshToken)
+	if err != nil {
+		fmt.Printf("Error rotating refresh token: %v\n", err)
+		return nil, err
+	}
+
+	user, err := us.userRepository.GetByID(fmt.Sprint(rotated.UserID))
+	if err != nil {
+		fmt.Printf("Error fetching user of refresh token: %v\n", err)
+		if revokeErr := us.refreshTokenService.RevokeFamily(rotated.FamilyID); revokeErr != nil {
+			return nil, revokeErr
+		}
+		return nil, refreshtoken.ErrRefreshTokenInvalid
+	}
+
+	var organizationId int64
+	if rotated.OrganizationID != nil {
+		organizationId = int64(*rotated.OrganizationID)
+	}
+	accessToken, err := us.issueAccessToken(int64(user.ID), user.Email, organizationId, rotated.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: nextRefreshToken,
+		ExpiresIn:    int64(token.AccessTokenTTL().Seconds()),
+		SessionID:    rotated.FamilyID,
+		UserID:       int64(user.ID),
+	}, nil
+}
+
+// Logout ends the session of the caller: their access token is denylisted until it
+// expires and the refresh tokens of its session are revoked.
+func (us *UserServiceImpl) Logout(principal *token.Principal) error {
+	fmt.Println("Logging out user in user service.")
+	if err := us.revokedTokenService.RevokeToken(principal.TokenID, principal.UserID, principal.ExpiresAt); err != nil {
+		fmt.Printf("Error revoking access token: %v\n", err)
+		return err
+	}
+	if principal.SessionID != "" {
+		if err := us.refreshTokenService.RevokeFamily(principal.SessionID); err != nil {
+			fmt.Printf("Error revoking session: %v\n", err)
+			return err
+		}
+	}
+	fmt.Printf("User %d logged out of session %s\n", principal.UserID, principal.SessionID)
+	return nil
+}
+
+// RevokeAllSessions ends every session of the user: all access tokens issued to them
+// so far stop working and none of their refresh tokens can be rotated anymore.
+func (us *UserServiceImpl) RevokeAllSessions(userId int64) error {
+	fmt.Println("Revoking all sessions of user in user service.")
+	if err := us.revokedTokenService.RevokeUserTokens(userId); err != nil {
+		fmt.Printf("Error revoking access tokens: %v\n", err)
+		return err
+	}
+	if err := us.refreshTokenService.RevokeUserTokens(userId); err != nil {
+		fmt.Printf("Error revoking refresh tokens: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// issueAccessToken signs an access token carrying the user id as sub and the roles
+// the user holds, plus their permissions when JWT_INCLUDE_PERMISSIONS is set. A token
+// issued for an organization carries it in the org_id claim and the roles held there;
+// membership is checked on every request that uses it. sessionId is the refresh token
+// family the token belongs to, so that ending the session can revoke both.
+func (us *UserServiceImpl) issueAccessToken(userId int64, email string, organizationId int64, sessionId string) (string, error) {
+	roles, err := us.userRoleRepository.GetUserRoles(userId, organizationId)
+	if err != nil {
+		fmt.Printf("Error fetching user roles: %v\n", err)
+		return "", err
+	}
+	roleNames := []string{}
+	for _, r := range roles {
+		roleNames = append(roleNames, r.Name)
+	}
+
+	var permissionNames []string
+	if env.GetBool("JWT_INCLUDE_PERMISSIONS", false) {
+		permissions, err := us.userRoleRepository.GetUserPermissions(userId, organizationId)
+		if err != nil {
+			fmt.Printf("Error fetching user permissions: %v\n", err)
+			return "", err
+		}
+		permissionNames = []string{}
+		for _, p := range permissions {
+			permissionNames = append(permissionNames, p.Name)
+		}
+	}
+
+	claims, err := token.NewAccessClaims(userId, email, roleNames, permissionNames, organizationId, sessionId)
+	if err != nil {
+		fmt.Printf("Error building token claims: %v\n", err)
+		return "", err
+	}
+	tokenString, err := token.SignAccessToken(claims)
+	if err != nil {
+		fmt.Printf("Error signing JWT token: %v\n", err)
+		return "", err
+	}
 	return tokenString, nil
 }
 
@@ -1,3 +1,66 @@
 package userrole
 
+import (
+	"go_project_structure/internal/permission"
+	"time"
+)
 
+type AssignRoleRequest struct {
+	RoleID     int64      `json:"role_id" validate:"required"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+}
+
+type PermissionCheckResponse struct {
+	UserID         int64  `json:"user_id"`
+	OrganizationID int64  `json:"organization_id,omitempty"`
+	Permission     string `json:"permission"`
+	ResourceID     string `json:"resource_id,omitempty"`
+	Allowed        bool   `json:"allowed"`
+}
+
+type RoleCheckResponse struct {
+	UserID         int64    `json:"user_id"`
+	OrganizationID int64    `json:"organization_id,omitempty"`
+	Roles          []string `json:"roles"`
+	Match          string   `json:"match"`
+	Matched        bool     `json:"matched"`
+}
+
+// PermissionSource is one way a user obtains a permission: Path runs from the
+// role assigned to the user to the role that holds the grant. Condition is the
+// condition the grant carries, if any.
+type PermissionSource struct {
+	RoleID    uint     `json:"role_id"`
+	RoleName  string   `json:"role_name"`
+	Path      []string `json:"path"`
+	Inherited bool     `json:"inherited"`
+	Condition string   `json:"condition,omitempty"`
+}
+
+// EffectivePermission is one permission granted to a user with a single effect.
+// OverriddenBy lists the deny grants that cancel an allow entirely.
+type EffectivePermission struct {
+	Permission   *permission.Permission `json:"permission"`
+	Effect       string                 `json:"effect"`
+	Sources      []PermissionSource     `json:"sources"`
+	OverriddenBy []string               `json:"overridden_by,omitempty"`
+}
+
+// PermissionDecision explains a permission check: the effect, name and condition of
+// the grant that decided it, the roles holding that grant, and whether a grant on the
+// object itself or ownership of it took part. Effect is empty when nothing matched.
+type PermissionDecision struct {
+	Allowed           bool     `json:"allowed"`
+	Effect            string   `json:"effect,omitempty"`
+	MatchedPermission string   `json:"matched_permission,omitempty"`
+	MatchedCondition  string   `json:"matched_condition,omitempty"`
+	MatchedRoles      []string `json:"matched_roles"`
+	ResourceGrant     bool     `json:"resource_grant"`
+	Owner             bool     `json:"owner"`
+}
+
+type EffectivePermissions struct {
+	Allowed []*EffectivePermission `json:"allowed"`
+	Denied  []*EffectivePermission `json:"denied"`
+}
@@ -1 +1,217 @@
-package userrole
\ No newline at end of file
+package userrole
+
+import (
+	"fmt"
+	"go_project_structure/internal/permission"
+	utils "go_project_structure/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type UserRoleController struct {
+	UserRoleService UserRoleService
+}
+
+func NewUserRoleController(_userRoleService UserRoleService) *UserRoleController {
+	return &UserRoleController{
+		UserRoleService: _userRoleService,
+	}
+}
+
+// parseIdParam reads a positive integer id from the chi url params.
+func parseIdParam(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return id, nil
+}
+
+func (uc *UserRoleController) GetUserRoles(w http.ResponseWriter, r *http.Request) {
+	userId, err := parseIdParam(r, "userId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid user id", err)
+		return
+	}
+
+	roles, err := uc.UserRoleService.GetUserRoles(userId, permission.TenantFromContext(r.Context()))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "User roles fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get user roles end point", roles)
+}
+
+func (uc *UserRoleController) GetUserAssignments(w http.ResponseWriter, r *http.Request) {
+	userId, err := parseIdParam(r, "userId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid user id", err)
+		return
+	}
+
+	assignments, err := uc.UserRoleService.GetUserAssignments(userId, permission.TenantFromContext(r.Context()))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "User role assignments fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get user role assignments end point", assignments)
+}
+
+func (uc *UserRoleController) AssignRoleToUser(w http.ResponseWriter, r *http.Request) {
+	userId, err := parseIdParam(r, "userId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid user id", err)
+		return
+	}
+
+	requestPayload := r.Context().Value("assign_role_payload").(AssignRoleRequest)
+
+	err = uc.UserRoleService.AssignRoleToUser(userId, requestPayload.RoleID, permission.TenantFromContext(r.Context()), requestPayload.ValidFrom, requestPayload.ValidUntil)
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Role assignment failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Role assigned to user successfully", nil)
+}
+
+func (uc *UserRoleController) RemoveRoleFromUser(w http.ResponseWriter, r *http.Request) {
+	userId, err := parseIdParam(r, "userId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid user id", err)
+		return
+	}
+	roleId, err := parseIdParam(r, "roleId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid role id", err)
+		return
+	}
+
+	err = uc.UserRoleService.RemoveRoleFromUser(userId, roleId, permission.TenantFromContext(r.Context()))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusNotFound, "Role removal failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Role removed from user successfully", nil)
+}
+
+func (uc *UserRoleController) GetUserPermissions(w http.ResponseWriter, r *http.Request) {
+	userId, err := parseIdParam(r, "userId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid user id", err)
+		return
+	}
+
+	permissions, err := uc.UserRoleService.GetUserPermissions(userId, permission.TenantFromContext(r.Context()))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "User permissions fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get user permissions end point", permissions)
+}
+
+func (uc *UserRoleController) GetUserEffectivePermissions(w http.ResponseWriter, r *http.Request) {
+	userId, err := parseIdParam(r, "userId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid user id", err)
+		return
+	}
+
+	effectivePermissions, err := uc.UserRoleService.GetUserEffectivePermissions(userId, permission.TenantFromContext(r.Context()))
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Effective permissions fetch failed.", err)
+		return
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Get effective user permissions end point", effectivePermissions)
+}
+
+func (uc *UserRoleController) CheckPermission(w http.ResponseWriter, r *http.Request) {
+	userId, err := parseIdParam(r, "userId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid user id", err)
+		return
+	}
+
+	permissionName := r.URL.Query().Get("name")
+	if permissionName == "" {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid permission name", fmt.Errorf("query parameter name is required"))
+		return
+	}
+
+	// resource_id narrows the check to a single object of the permission's resource type
+	resourceId := r.URL.Query().Get("resource_id")
+	organizationId := permission.TenantFromContext(r.Context())
+	// grant conditions see this request as if the checked user had made it
+	attributes := permission.RequestAttributes(r, userId)
+	var allowed bool
+	if resourceId == "" {
+		allowed, err = uc.UserRoleService.HasPermission(userId, organizationId, permissionName, attributes)
+	} else {
+		allowed, err = uc.UserRoleService.HasResourcePermission(userId, organizationId, permissionName, resourceId, attributes)
+	}
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Permission check failed.", err)
+		return
+	}
+	responsePayload := PermissionCheckResponse{
+		UserID:         userId,
+		OrganizationID: organizationId,
+		Permission:     permissionName,
+		ResourceID:     resourceId,
+		Allowed:        allowed,
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Permission check end point", responsePayload)
+}
+
+func (uc *UserRoleController) CheckRoles(w http.ResponseWriter, r *http.Request) {
+	userId, err := parseIdParam(r, "userId")
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid user id", err)
+		return
+	}
+
+	roleNames := []string{}
+	for _, name := range strings.Split(r.URL.Query().Get("names"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			roleNames = append(roleNames, name)
+		}
+	}
+	if len(roleNames) == 0 {
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid role names", fmt.Errorf("query parameter names is required"))
+		return
+	}
+
+	match := r.URL.Query().Get("match")
+	if match == "" {
+		match = "any"
+	}
+
+	organizationId := permission.TenantFromContext(r.Context())
+	var matched bool
+	switch {
+	case len(roleNames) == 1:
+		matched, err = uc.UserRoleService.HasRole(userId, organizationId, roleNames[0])
+	case match == "all":
+		matched, err = uc.UserRoleService.HasAllRoles(userId, organizationId, roleNames)
+	case match == "any":
+		matched, err = uc.UserRoleService.HasAnyRole(userId, organizationId, roleNames)
+	default:
+		utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid match mode", fmt.Errorf("match must be either any or all"))
+		return
+	}
+	if err != nil {
+		utils.WriteJsonErrorResponse(w, http.StatusInternalServerError, "Role check failed.", err)
+		return
+	}
+
+	responsePayload := RoleCheckResponse{
+		UserID:         userId,
+		OrganizationID: organizationId,
+		Roles:          roleNames,
+		Match:          match,
+		Matched:        matched,
+	}
+	utils.WriteJsonSuccessResponse(w, http.StatusOK, "Role check end point", responsePayload)
+}
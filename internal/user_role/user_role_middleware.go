@@ -1 +1,42 @@
-package userrole
\ No newline at end of file
+package userrole
+
+import (
+	"context"
+	"fmt"
+	utils "go_project_structure/utils"
+	"net/http"
+	"time"
+)
+
+func AssignRoleRequestValidator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var RequestPayload = AssignRoleRequest{}
+		if payloadErr := utils.ReadJsonBody(r, &RequestPayload); payloadErr != nil {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Json encoding error.", payloadErr)
+			return
+		}
+		fmt.Println("assign role payload received.")
+
+		if RequestPayload.RoleID <= 0 {
+			utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("role_id must be a positive integer"))
+			return
+		}
+
+		if RequestPayload.ValidUntil != nil {
+			if RequestPayload.ValidFrom != nil && !RequestPayload.ValidUntil.After(*RequestPayload.ValidFrom) {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("valid_until must be after valid_from"))
+				return
+			}
+			if !RequestPayload.ValidUntil.After(time.Now()) {
+				utils.WriteJsonErrorResponse(w, http.StatusBadRequest, "Invalid request payload", fmt.Errorf("valid_until must be in the future"))
+				return
+			}
+		}
+
+		req_context := r.Context()
+		ctx := context.WithValue(req_context, "assign_role_payload", RequestPayload)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
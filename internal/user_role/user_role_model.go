@@ -1,11 +1,23 @@
 package userrole
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type UserRole struct {
 	gorm.Model
-	UserID uint
-	RoleID uint
+	UserID uint `gorm:"not null;uniqueIndex:idx_user_role_global_live,where:deleted_at IS NULL AND organization_id IS NULL;uniqueIndex:idx_user_role_organization_live,where:deleted_at IS NULL AND organization_id IS NOT NULL"`
+	RoleID uint `gorm:"not null;uniqueIndex:idx_user_role_global_live,where:deleted_at IS NULL AND organization_id IS NULL;uniqueIndex:idx_user_role_organization_live,where:deleted_at IS NULL AND organization_id IS NOT NULL"`
+	// an assignment with an OrganizationID only applies inside that organization; nil applies everywhere
+	OrganizationID *uint `gorm:"index;uniqueIndex:idx_user_role_organization_live,where:deleted_at IS NULL AND organization_id IS NOT NULL"`
+	// the assignment is only in force between ValidFrom and ValidUntil; nil leaves that side open
+	ValidFrom  *time.Time
+	ValidUntil *time.Time `gorm:"index:idx_user_role_valid_until,where:deleted_at IS NULL AND valid_until IS NOT NULL"`
+}
+
+// TableName keeps gorm on the singular table created by the user_role migration.
+func (UserRole) TableName() string {
+	return "user_role"
 }
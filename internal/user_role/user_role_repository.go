@@ -1,10 +1,13 @@
 package userrole
 
 import (
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"go_project_structure/internal/permission"
 	"go_project_structure/internal/role"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
@@ -19,15 +22,21 @@ type UserRoleRepository interface {
 	HardDelete(id string) (string, error)
 
 	
-	GetUserRoles(userId int64) ([]*role.Role, error)
-	AssignRoleToUser(userId int64, roleId int64) error
-	RemoveRoleFromUser(userId int64, roleId int64) error
-	GetUserPermissions(userId int64) ([]*permission.Permission, error)
-	HasPermission(userId int64, permissionName string) (bool, error)
-	HasRole(userId int64, roleName string) (bool, error)
-	HasAllRoles(userId int64, roleNames []string) (bool, error)
-	HasAnyRole(userId int64, roleNames []string) (bool, error)
-
+	GetUserRoles(userId int64, organizationId int64) ([]*role.Role, error)
+	AssignRoleToUser(userId int64, roleId int64, organizationId int64, validFrom *time.Time, validUntil *time.Time) error
+	RemoveRoleFromUser(userId int64, roleId int64, organizationId int64) error
+	GetUserPermissions(userId int64, organizationId int64) ([]*permission.Permission, error)
+	HasPermission(userId int64, organizationId int64, permissionName string, attributes permission.Attributes) (bool, error)
+	HasResourcePermission(userId int64, organizationId int64, permissionName string, resourceId string, isOwner bool, attributes permission.Attributes) (bool, error)
+	DecidePermission(userId int64, organizationId int64, permissionName string, resourceId string, isOwner bool, attributes permission.Attributes) (*PermissionDecision, error)
+	HasRole(userId int64, organizationId int64, roleName string) (bool, error)
+	HasAllRoles(userId int64, organizationId int64, roleNames []string) (bool, error)
+	HasAnyRole(userId int64, organizationId int64, roleNames []string) (bool, error)
+	GetUserEffectivePermissions(userId int64, organizationId int64) (*EffectivePermissions, error)
+	GetUserAssignments(userId int64, organizationId int64) ([]*UserRole, error)
+	IsOrganizationMember(userId int64, organizationId int64) (bool, error)
+	RequiresMfa(userId int64) (bool, error)
+	SweepExpiredAssignments() ([]*UserRole, error)
 }
 
 type UserRoleRepositoryImpl struct {
@@ -50,7 +59,7 @@ func (u *UserRoleRepositoryImpl) Create(userID string, roleID string) error {
 	// }
 
 	// step 1: prepare the query
-	query := "INSERT INTO user_roles (name, description, resources, action) VALUES (?, ?, ?, ?)"
+	query := "INSERT INTO user_role (user_id, role_id) VALUES (?, ?)"
 
 	// step 2: execute the query
 	result := u.db.Exec(query, userID, roleID)
@@ -92,14 +101,14 @@ func (u *UserRoleRepositoryImpl) GetByID(id string) (*UserRole, error) {
 	fmt.Println("Fetching userRole by id in userRole repository.")
 
 	// step 1: prepare the query
-	query := "SELECT id, user_id, role_id FROM user_roles WHERE deleted_at IS NULL AND id = ?"
+	query := "SELECT id, user_id, role_id, created_at, updated_at FROM user_role WHERE deleted_at IS NULL AND id = ?"
 
 	// step 2: execute the query
 	row := u.db.Raw(query, id).Row()
 
 	// step 3: process the result
 	userRole := &UserRole{}
-	err := row.Scan(&userRole.ID, &userRole.UserID, userRole.RoleID, &userRole.CreatedAt, &userRole.UpdatedAt)
+	err := row.Scan(&userRole.ID, &userRole.UserID, &userRole.RoleID, &userRole.CreatedAt, &userRole.UpdatedAt)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			fmt.Println("UserRole not found.")
@@ -118,7 +127,7 @@ func (u *UserRoleRepositoryImpl) GetAll() ([]*UserRole, error) {
 	fmt.Println("Fetching all userRoles in userRole repository.")
 
 	// step 1: prepare the query
-	query := "SELECT id, user_id, role_id FROM user_roles WHERE deleted_at IS NULL"
+	query := "SELECT id, user_id, role_id, created_at, updated_at FROM user_role WHERE deleted_at IS NULL"
 
 	// step 2: execute the query
 	rows, err := u.db.Raw(query).Rows()
@@ -163,16 +172,16 @@ func (u *UserRoleRepositoryImpl) Update(id string, userID *string, roleID *strin
 	fmt.Println("updating userRole in userRole repository.")
 
 	// step 1: prepare the query
-	query := "UPDATE user_roles SET "
+	query := "UPDATE user_role SET "
 	args := []interface{}{}
 
 	if userID != nil {
 		query += "user_id = ?, "
-		args = append(args, userID)
+		args = append(args, *userID)
 	}
 	if roleID != nil {
 		query += "role_id = ?, "
-		args = append(args, roleID)
+		args = append(args, *roleID)
 	}
 
 
@@ -207,7 +216,7 @@ func (u *UserRoleRepositoryImpl) SoftDelete(id string) (string, error) {
 	fmt.Println("deleting userRole in userRole repository.")
 
 	// step 1: prepare the query
-	query := "UPDATE user_roles SET deleted_at = NOW() WHERE deleted_at IS NULL AND id = ?"
+	query := "UPDATE user_role SET deleted_at = NOW() WHERE deleted_at IS NULL AND id = ?"
 
 	// step 2: execute the query
 	result := u.db.Exec(query, id)
@@ -235,7 +244,7 @@ func (u *UserRoleRepositoryImpl) HardDelete(id string) (string, error) {
 	fmt.Println("deleting userRole in userRole repository.")
 
 	// step 1: prepare the query
-	query := "DELETE FROM user_roles WHERE id = ?"
+	query := "DELETE FROM user_role WHERE id = ?"
 
 	// step 2: execute the query
 	result := u.db.Exec(query, id)
@@ -259,68 +268,654 @@ func (u *UserRoleRepositoryImpl) HardDelete(id string) (string, error) {
 	return fmt.Sprintf("Deleted userRole (rows affected: %d)\n", rowsAffected), nil
 }
 
-func (u *UserRoleRepositoryImpl) GetByName(name string) (*UserRole, error) {
-	fmt.Println("Fetching userRole by id in userRole repository.")
+// user role related actions
+
+// activeAssignment filters user_role rows (aliased ur) down to the assignments in
+// force right now: not deleted, already started and not yet expired.
+const activeAssignment = `ur.deleted_at IS NULL
+		AND (ur.valid_from IS NULL OR ur.valid_from <= NOW())
+		AND (ur.valid_until IS NULL OR ur.valid_until > NOW())`
+
+// inOrganization keeps the assignments that apply in the organization bound to its
+// placeholder: the global ones plus the ones scoped to it. 0 keeps global ones only.
+const inOrganization = `(ur.organization_id IS NULL OR ur.organization_id = ?)`
+
+// effectiveRolesCTE resolves the live roles of a user in an organization: the roles
+// actively assigned to them there plus every role those inherit through role_parents. UNION drops rows
+// already seen, so the recursion terminates even if the hierarchy contains a cycle.
+const effectiveRolesCTE = `WITH RECURSIVE effective_roles(role_id) AS (
+		SELECT r.id
+		FROM roles r
+		JOIN user_role ur ON ur.role_id = r.id AND ` + activeAssignment + `
+		JOIN users u ON u.id = ur.user_id AND u.deleted_at IS NULL
+		WHERE r.deleted_at IS NULL AND u.id = ? AND ` + inOrganization + `
+		UNION
+		SELECT pr.id
+		FROM role_parents rp
+		JOIN effective_roles er ON er.role_id = rp.role_id
+		JOIN roles pr ON pr.id = rp.parent_role_id AND pr.deleted_at IS NULL
+		WHERE rp.deleted_at IS NULL
+	)
+	`
+
+func (u *UserRoleRepositoryImpl) GetUserRoles(userId int64, organizationId int64) ([]*role.Role, error) {
+	fmt.Println("Fetching roles of user in userRole repository.")
 
 	// step 1: prepare the query
-	query := "SELECT id, name, description, resources, action, created_at, updated_at FROM userRoles WHERE deleted_at IS NULL AND name = ?"
+	query := `SELECT DISTINCT r.id, r.name, r.description, r.organization_id, r.require_mfa, r.created_at, r.updated_at
+		FROM roles r
+		JOIN user_role ur ON ur.role_id = r.id AND ` + activeAssignment + `
+		JOIN users u ON u.id = ur.user_id AND u.deleted_at IS NULL
+		WHERE r.deleted_at IS NULL AND u.id = ? AND ` + inOrganization + `
+		ORDER BY r.id`
 
 	// step 2: execute the query
-	row := u.db.Raw(query, name).Row()
+	rows, err := u.db.Raw(query, userId, organizationId).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
 
 	// step 3: process the result
-	userRole := &UserRole{}
-	err := row.Scan(&userRole.ID, &userRole.UserID, userRole.RoleID, &userRole.CreatedAt, &userRole.UpdatedAt)
+	roles := []*role.Role{}
+	for rows.Next() {
+		r := &role.Role{}
+		err := rows.Scan(&r.ID, &r.Name, &r.Description, &r.OrganizationID, &r.RequireMfa, &r.CreatedAt, &r.UpdatedAt)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		roles = append(roles, r)
+	}
+
+	// step 4: return the result
+	fmt.Printf("Fetched %d roles for user %d\n", len(roles), userId)
+	return roles, nil
+}
+
+// AssignRoleToUser assigns a role to a user within an organization, or globally when
+// organizationId is 0, in force only between validFrom and validUntil when they are
+// set. Assigning a role the user already holds in that scope replaces the validity
+// window of the existing assignment.
+func (u *UserRoleRepositoryImpl) AssignRoleToUser(userId int64, roleId int64, organizationId int64, validFrom *time.Time, validUntil *time.Time) error {
+	fmt.Println("assigning role to user in userRole repository.")
+
+	// step 1: move the window of an existing assignment
+	result := u.db.Exec(`UPDATE user_role SET valid_from = ?, valid_until = ?, updated_at = NOW()
+		WHERE deleted_at IS NULL AND user_id = ? AND role_id = ? AND organization_id IS NOT DISTINCT FROM ?
+		AND role_id IN (SELECT id FROM roles WHERE organization_id IS NOT DISTINCT FROM ?)`,
+		validFrom, validUntil, userId, roleId, organizationScope(organizationId), organizationScope(organizationId))
+	if result.Error != nil {
+		fmt.Printf("Error updating assignment window: %v\n", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		fmt.Println("Role is already assigned to user, validity window updated.")
+		return nil
+	}
+
+	// step 2: prepare the query
+	// the row is only inserted when the user, the role and the organization are live,
+	// and the role belongs to the scope of the assignment: global roles are only
+	// assigned globally and an organization's roles only within it. A global role
+	// assigned inside an organization would let that organization's admins hand out
+	// grants that reach past it.
+	query := `INSERT INTO user_role (user_id, role_id, organization_id, valid_from, valid_until)
+		SELECT u.id, r.id, o.id, ?, ? FROM users u
+		JOIN roles r ON r.id = ? AND r.deleted_at IS NULL
+		LEFT JOIN organizations o ON o.id = ? AND o.deleted_at IS NULL
+		WHERE u.id = ? AND u.deleted_at IS NULL
+		AND (? = 0 OR o.id IS NOT NULL)
+		AND r.organization_id IS NOT DISTINCT FROM o.id`
+
+	// step 3: execute the query
+	result = u.db.Exec(query, validFrom, validUntil, roleId, organizationId, userId, organizationId)
+
+	// step 4: check for errors
+	if result.Error != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(result.Error, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // unique_violation, a concurrent request assigned the role first
+				return fmt.Errorf("unique constraint violation")
+			case "23503": // foreign_key_violation
+				return fmt.Errorf("foreign key violation.")
+			case "23514": // check_violation
+				return fmt.Errorf("valid_until must be after valid_from")
+			default:
+				return fmt.Errorf("database error: %v", pgErr.Message)
+			}
+		}
+		return result.Error
+	}
+
+	// step 5: evaluate the result
+	if result.RowsAffected == 0 {
+		fmt.Println("No role was assigned to user.")
+		return fmt.Errorf("user, role or organization not found, or the role belongs to another organization")
+	}
+
+	fmt.Printf("Assigned role %d to user %d\n", roleId, userId)
+	return nil
+}
+
+func (u *UserRoleRepositoryImpl) RemoveRoleFromUser(userId int64, roleId int64, organizationId int64) error {
+	fmt.Println("removing role from user in userRole repository.")
+
+	// step 1: prepare the query
+	query := "UPDATE user_role SET deleted_at = NOW(), updated_at = NOW() WHERE deleted_at IS NULL AND user_id = ? AND role_id = ? AND organization_id IS NOT DISTINCT FROM ?"
+
+	// step 2: execute the query
+	result := u.db.Exec(query, userId, roleId, organizationScope(organizationId))
+
+	// step 3: check for errors
+	if result.Error != nil {
+		fmt.Printf("Error removing role from user: %v\n", result.Error)
+		return result.Error
+	}
+
+	// step 4: evaluate the result
+	if result.RowsAffected == 0 {
+		fmt.Println("No role was removed from user.")
+		return fmt.Errorf("No role was removed from user.")
+	}
+
+	fmt.Printf("Removed role %d from user %d (rows affected: %d)\n", roleId, userId, result.RowsAffected)
+
+	// step 5: return the result
+	return nil
+}
+
+// GetUserPermissions lists the permissions the user is allowed through their
+// effective roles. Allow grants that an unconditional deny grant covers entirely
+// are left out; a conditional deny only applies to some requests and does not.
+func (u *UserRoleRepositoryImpl) GetUserPermissions(userId int64, organizationId int64) ([]*permission.Permission, error) {
+	fmt.Println("Fetching permissions of user in userRole repository.")
+
+	// step 1: prepare the query
+	query := effectiveRolesCTE + `SELECT DISTINCT p.id, p.name, p.description, p.resource, p.action, p.created_at, p.updated_at, rp.effect, rp.condition
+		FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.id AND rp.deleted_at IS NULL
+		JOIN effective_roles er ON er.role_id = rp.role_id
+		WHERE p.deleted_at IS NULL
+		ORDER BY p.id`
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query, userId, organizationId).Rows()
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			fmt.Println("UserRole not found.")
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	allowed := []*permission.Permission{}
+	denies := []string{}
+	seen := map[uint]bool{}
+	for rows.Next() {
+		p := &permission.Permission{}
+		var effect, condition string
+		err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Resource, &p.Action, &p.CreatedAt, &p.UpdatedAt, &effect, &condition)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
 			return nil, err
 		}
-		fmt.Printf("Error fetching userRole: %v\n", err)
+		if effect == permission.EffectDeny {
+			if condition == "" {
+				denies = append(denies, p.Name)
+			}
+			continue
+		}
+		if !seen[p.ID] {
+			seen[p.ID] = true
+			allowed = append(allowed, p)
+		}
+	}
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
+	permissions := []*permission.Permission{}
+	for _, p := range allowed {
+		if len(deniedBy(denies, p.Name)) == 0 {
+			permissions = append(permissions, p)
+		}
+	}
+
 	// step 4: return the result
-	fmt.Printf("Fetched userRole: %+v\n", userRole)
-	return userRole, nil
+	fmt.Printf("Fetched %d permissions for user %d\n", len(permissions), userId)
+	return permissions, nil
 }
 
+// HasPermission decides whether the user may use a permission. Grants match
+// exactly or through wildcards such as user:* or *, and any matching deny grant
+// overrides every matching allow grant. Grant conditions are evaluated against
+// the attributes of the request.
+func (u *UserRoleRepositoryImpl) HasPermission(userId int64, organizationId int64, permissionName string, attributes permission.Attributes) (bool, error) {
+	fmt.Println("Checking user permission in userRole repository.")
+	decision, err := u.DecidePermission(userId, organizationId, permissionName, "", false, attributes)
+	if err != nil {
+		return false, err
+	}
+	return decision.Allowed, nil
+}
 
+// HasResourcePermission decides whether the user may use a permission on one object.
+// Grants on that object count next to the grants of the user's roles, owning the
+// object counts as an allow grant, and any matching deny still overrides them all.
+func (u *UserRoleRepositoryImpl) HasResourcePermission(userId int64, organizationId int64, permissionName string, resourceId string, isOwner bool, attributes permission.Attributes) (bool, error) {
+	fmt.Println("Checking user resource permission in userRole repository.")
+	decision, err := u.DecidePermission(userId, organizationId, permissionName, resourceId, isOwner, attributes)
+	if err != nil {
+		return false, err
+	}
+	return decision.Allowed, nil
+}
 
-// user role related actions
+// grantSource is where a grant loaded by DecidePermission comes from: a role, a
+// grant on the object itself, or neither for ownership.
+type grantSource struct {
+	roleName      string
+	resourceGrant bool
+}
 
-func (u *UserRoleRepositoryImpl) GetUserRoles(userId int64) ([]*role.Role, error) {
-	
-	return nil, nil
+// DecidePermission evaluates a permission for the user and explains the outcome:
+// the grant that decided it and the roles holding that grant. With a resourceId the
+// grants on that object and ownership of it count too, as in HasResourcePermission.
+func (u *UserRoleRepositoryImpl) DecidePermission(userId int64, organizationId int64, permissionName string, resourceId string, isOwner bool, attributes permission.Attributes) (*PermissionDecision, error) {
+	fmt.Println("Deciding user permission in userRole repository.")
+
+	// step 1: prepare the query
+	// only the grants that could possibly cover the permission are loaded; grants
+	// on a single object carry no role
+	candidates := permission.CandidateGrants(permissionName)
+	query := effectiveRolesCTE + `SELECT p.name, rp.effect, rp.condition, r.name, FALSE
+		FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.id AND rp.deleted_at IS NULL
+		JOIN effective_roles er ON er.role_id = rp.role_id
+		JOIN roles r ON r.id = er.role_id
+		WHERE p.deleted_at IS NULL AND p.name IN ?`
+	args := []interface{}{userId, organizationId, candidates}
+	if resourceId != "" {
+		query += `
+		UNION
+		SELECT p.name, g.effect, '', '', TRUE
+		FROM permissions p
+		JOIN resource_grants g ON g.permission_id = p.id AND g.deleted_at IS NULL
+		WHERE p.deleted_at IS NULL AND g.user_id = ? AND g.resource_id = ? AND p.name IN ?`
+		args = append(args, userId, resourceId, candidates)
+	}
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query, args...).Rows()
+	if err != nil {
+		fmt.Printf("Error checking permission: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	grants := []permission.Grant{}
+	sources := []grantSource{}
+	for rows.Next() {
+		var grant permission.Grant
+		var source grantSource
+		if err := rows.Scan(&grant.Name, &grant.Effect, &grant.Condition, &source.roleName, &source.resourceGrant); err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		grants = append(grants, grant)
+		sources = append(sources, source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// step 4: decide and collect the roles behind the deciding grant
+	result := decideGrants(grants, sources, isOwner, permissionName, attributes)
+
+	// step 5: return the result
+	fmt.Printf("User %d has permission %s in organization %d on %q: %t (matched %q %s, roles %v)\n", userId, permissionName, organizationId, resourceId, result.Allowed, result.MatchedPermission, result.Effect, result.MatchedRoles)
+	return result, nil
 }
 
-func (u *UserRoleRepositoryImpl) AssignRoleToUser(userId int64, roleId int64) error {
-	return nil 
+// decideGrants decides a permission from the grants loaded for the user, sources[i]
+// being where grants[i] comes from. Owning the object counts as an allow grant.
+func decideGrants(grants []permission.Grant, sources []grantSource, isOwner bool, permissionName string, attributes permission.Attributes) *PermissionDecision {
+	if isOwner {
+		grants = append(grants, permission.Grant{Name: permissionName, Effect: permission.EffectAllow})
+		sources = append(sources, grantSource{})
+	}
+
+	// the roles behind the deciding grant are collected too
+	decision := permission.DecideWithAttributes(grants, permissionName, attributes)
+	result := &PermissionDecision{
+		Allowed:           decision.Allowed,
+		Effect:            decision.Matched.Effect,
+		MatchedPermission: decision.Matched.Name,
+		MatchedCondition:  decision.Matched.Condition,
+		MatchedRoles:      []string{},
+		Owner:             isOwner,
+	}
+	seen := map[string]bool{}
+	for i, grant := range grants {
+		if decision.Matched.Name == "" || grant != decision.Matched {
+			continue
+		}
+		source := sources[i]
+		if source.resourceGrant {
+			result.ResourceGrant = true
+		}
+		if source.roleName != "" && !seen[source.roleName] {
+			seen[source.roleName] = true
+			result.MatchedRoles = append(result.MatchedRoles, source.roleName)
+		}
+	}
+	return result
 }
 
-func (u *UserRoleRepositoryImpl) RemoveRoleFromUser(userId int64, roleId int64) error {
-	return nil 
+func (u *UserRoleRepositoryImpl) HasRole(userId int64, organizationId int64, roleName string) (bool, error) {
+	return u.HasAnyRole(userId, organizationId, []string{roleName})
+}
+
+// HasAllRoles and HasAnyRole match inherited roles too, so a user holding admin
+// also satisfies a check for any role admin inherits from.
+func (u *UserRoleRepositoryImpl) HasAllRoles(userId int64, organizationId int64, roleNames []string) (bool, error) {
+	fmt.Println("Checking all user roles in userRole repository.")
+
+	if len(roleNames) == 0 {
+		return false, nil
+	}
+
+	// step 1: prepare the query
+	query := effectiveRolesCTE + `SELECT COUNT(DISTINCT r.name)
+		FROM roles r
+		JOIN effective_roles er ON er.role_id = r.id
+		WHERE r.name IN ?`
+
+	// step 2: execute the query
+	row := u.db.Raw(query, userId, organizationId, roleNames).Row()
+
+	// step 3: process the result
+	var matched int
+	if err := row.Scan(&matched); err != nil {
+		fmt.Printf("Error checking roles: %v\n", err)
+		return false, err
+	}
+
+	// duplicated names in the input must not make the check impossible to satisfy
+	wanted := map[string]struct{}{}
+	for _, name := range roleNames {
+		wanted[name] = struct{}{}
+	}
+
+	// step 4: return the result
+	return matched == len(wanted), nil
 }
 
-func (u *UserRoleRepositoryImpl) GetUserPermissions(userId int64) ([]*permission.Permission, error) {
-	return nil, nil
+func (u *UserRoleRepositoryImpl) HasAnyRole(userId int64, organizationId int64, roleNames []string) (bool, error) {
+	fmt.Println("Checking any user role in userRole repository.")
+
+	if len(roleNames) == 0 {
+		return false, nil
+	}
+
+	// step 1: prepare the query
+	query := effectiveRolesCTE + `SELECT EXISTS (
+		SELECT 1
+		FROM roles r
+		JOIN effective_roles er ON er.role_id = r.id
+		WHERE r.name IN ?
+	)`
+
+	// step 2: execute the query
+	row := u.db.Raw(query, userId, organizationId, roleNames).Row()
+
+	// step 3: process the result
+	var matched bool
+	if err := row.Scan(&matched); err != nil {
+		fmt.Printf("Error checking roles: %v\n", err)
+		return false, err
+	}
+
+	// step 4: return the result
+	return matched, nil
+}
+
+// GetUserEffectivePermissions lists every grant the user ends up with, split into
+// allows and denies, and for each the chain of roles it was inherited through.
+// Allows that an unconditional deny covers entirely name the overriding denies.
+func (u *UserRoleRepositoryImpl) GetUserEffectivePermissions(userId int64, organizationId int64) (*EffectivePermissions, error) {
+	fmt.Println("Fetching effective permissions of user in userRole repository.")
+
+	// step 1: prepare the query
+	// every path from an assigned role up through its ancestors is walked; the
+	// visited ids guard against cycles.
+	query := `WITH RECURSIVE role_paths(role_id, visited, path) AS (
+			SELECT r.id, ARRAY[r.id], ARRAY[r.name::text]
+			FROM roles r
+			JOIN user_role ur ON ur.role_id = r.id AND ` + activeAssignment + `
+			JOIN users u ON u.id = ur.user_id AND u.deleted_at IS NULL
+			WHERE r.deleted_at IS NULL AND u.id = ? AND ` + inOrganization + `
+			UNION ALL
+			SELECT pr.id, rpath.visited || pr.id, rpath.path || pr.name::text
+			FROM role_parents rp
+			JOIN role_paths rpath ON rpath.role_id = rp.role_id
+			JOIN roles pr ON pr.id = rp.parent_role_id AND pr.deleted_at IS NULL
+			WHERE rp.deleted_at IS NULL AND NOT pr.id = ANY(rpath.visited)
+		)
+		SELECT p.id, p.name, p.description, p.resource, p.action, p.created_at, p.updated_at,
+			grp.effect, grp.condition, r.id, r.name, array_to_json(rpath.path)::text
+		FROM role_paths rpath
+		JOIN roles r ON r.id = rpath.role_id
+		JOIN role_permissions grp ON grp.role_id = rpath.role_id AND grp.deleted_at IS NULL
+		JOIN permissions p ON p.id = grp.permission_id AND p.deleted_at IS NULL
+		ORDER BY p.id, grp.effect, array_length(rpath.path, 1), r.id`
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query, userId, organizationId).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: group the grant paths by permission and effect
+	effectivePermissions := &EffectivePermissions{
+		Allowed: []*EffectivePermission{},
+		Denied:  []*EffectivePermission{},
+	}
+	byGrant := map[string]*EffectivePermission{}
+	for rows.Next() {
+		p := &permission.Permission{}
+		source := PermissionSource{}
+		var effect, path string
+		err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Resource, &p.Action, &p.CreatedAt, &p.UpdatedAt, &effect, &source.Condition, &source.RoleID, &source.RoleName, &path)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(path), &source.Path); err != nil {
+			return nil, err
+		}
+		source.Inherited = len(source.Path) > 1
+
+		key := fmt.Sprintf("%d:%s", p.ID, effect)
+		effective, ok := byGrant[key]
+		if !ok {
+			effective = &EffectivePermission{Permission: p, Effect: effect}
+			byGrant[key] = effective
+			if effect == permission.EffectDeny {
+				effectivePermissions.Denied = append(effectivePermissions.Denied, effective)
+			} else {
+				effectivePermissions.Allowed = append(effectivePermissions.Allowed, effective)
+			}
+		}
+		effective.Sources = append(effective.Sources, source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// step 4: mark the allows that are overridden by denies
+	denies := []string{}
+	for _, denied := range effectivePermissions.Denied {
+		for _, source := range denied.Sources {
+			if source.Condition == "" {
+				denies = append(denies, denied.Permission.Name)
+				break
+			}
+		}
+	}
+	for _, allowed := range effectivePermissions.Allowed {
+		allowed.OverriddenBy = deniedBy(denies, allowed.Permission.Name)
+	}
+
+	// step 5: return the result
+	fmt.Printf("Fetched %d allowed and %d denied permissions for user %d\n", len(effectivePermissions.Allowed), len(effectivePermissions.Denied), userId)
+	return effectivePermissions, nil
 }
 
-func (u *UserRoleRepositoryImpl) HasPermission(userId int64, permissionName string) (bool, error) {
-	return false, nil
+// deniedBy returns the deny grants that cover everything the allowed grant does.
+func deniedBy(denies []string, allowed string) []string {
+	overriding := []string{}
+	for _, denied := range denies {
+		if permission.MatchPermission(denied, allowed) {
+			overriding = append(overriding, denied)
+		}
+	}
+	return overriding
 }
 
-func (u *UserRoleRepositoryImpl) HasRole(userId int64, roleName string) (bool, error) {
-	return false, nil 
+// GetUserAssignments lists the live role assignments of a user that apply in the
+// organization, including the ones that have not started yet or have expired but
+// were not swept.
+func (u *UserRoleRepositoryImpl) GetUserAssignments(userId int64, organizationId int64) ([]*UserRole, error) {
+	fmt.Println("Fetching role assignments of user in userRole repository.")
+
+	// step 1: prepare the query
+	query := `SELECT ur.id, ur.user_id, ur.role_id, ur.organization_id, ur.valid_from, ur.valid_until, ur.created_at, ur.updated_at
+		FROM user_role ur
+		JOIN roles r ON r.id = ur.role_id AND r.deleted_at IS NULL
+		WHERE ur.deleted_at IS NULL AND ur.user_id = ? AND ` + inOrganization + `
+		ORDER BY ur.role_id, ur.organization_id NULLS FIRST`
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query, userId, organizationId).Rows()
+	if err != nil {
+		fmt.Printf("Error executing query: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	return scanUserRoles(rows)
 }
 
-func (u *UserRoleRepositoryImpl) HasAllRoles(userId int64, roleNames []string) (bool, error) {
-	return false, nil
+// SweepExpiredAssignments soft deletes every live assignment whose validity
+// window has ended and returns the rows it removed.
+func (u *UserRoleRepositoryImpl) SweepExpiredAssignments() ([]*UserRole, error) {
+	fmt.Println("Sweeping expired role assignments in userRole repository.")
+
+	// step 1: prepare the query
+	query := `UPDATE user_role SET deleted_at = NOW(), updated_at = NOW()
+		WHERE deleted_at IS NULL AND valid_until IS NOT NULL AND valid_until <= NOW()
+		RETURNING id, user_id, role_id, organization_id, valid_from, valid_until, created_at, updated_at`
+
+	// step 2: execute the query
+	rows, err := u.db.Raw(query).Rows()
+	if err != nil {
+		fmt.Printf("Error sweeping expired assignments: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// step 3: process the result
+	return scanUserRoles(rows)
 }
 
-func (u *UserRoleRepositoryImpl) HasAnyRole(userId int64, roleNames []string) (bool, error) {
-	return false, nil
+// IsOrganizationMember reports whether the user holds an active role assignment
+// scoped to the live organization.
+func (u *UserRoleRepositoryImpl) IsOrganizationMember(userId int64, organizationId int64) (bool, error) {
+	fmt.Println("Checking organization membership in userRole repository.")
+
+	// step 1: prepare the query
+	query := `SELECT EXISTS (
+		SELECT 1
+		FROM user_role ur
+		JOIN organizations o ON o.id = ur.organization_id AND o.deleted_at IS NULL
+		JOIN roles r ON r.id = ur.role_id AND r.deleted_at IS NULL
+		WHERE ` + activeAssignment + ` AND ur.user_id = ? AND ur.organization_id = ?
+	)`
+
+	// step 2: execute the query
+	row := u.db.Raw(query, userId, organizationId).Row()
+
+	// step 3: process the result
+	var member bool
+	if err := row.Scan(&member); err != nil {
+		fmt.Printf("Error checking organization membership: %v\n", err)
+		return false, err
+	}
+
+	// step 4: return the result
+	return member, nil
 }
 
+// RequiresMfa reports whether any role the user holds, in any organization and
+// including the roles they inherit, requires multi-factor authentication.
+func (u *UserRoleRepositoryImpl) RequiresMfa(userId int64) (bool, error) {
+	fmt.Println("Checking mfa requirement in userRole repository.")
+
+	// step 1: prepare the query
+	query := `WITH RECURSIVE held_roles(role_id) AS (
+		SELECT r.id
+		FROM roles r
+		JOIN user_role ur ON ur.role_id = r.id AND ` + activeAssignment + `
+		WHERE r.deleted_at IS NULL AND ur.user_id = ?
+		UNION
+		SELECT pr.id
+		FROM role_parents rp
+		JOIN held_roles hr ON hr.role_id = rp.role_id
+		JOIN roles pr ON pr.id = rp.parent_role_id AND pr.deleted_at IS NULL
+		WHERE rp.deleted_at IS NULL
+	)
+	SELECT EXISTS (SELECT 1 FROM held_roles hr JOIN roles r ON r.id = hr.role_id WHERE r.require_mfa)`
 
+	// step 2: execute the query
+	row := u.db.Raw(query, userId).Row()
+
+	// step 3: process the result
+	var required bool
+	if err := row.Scan(&required); err != nil {
+		fmt.Printf("Error checking mfa requirement: %v\n", err)
+		return false, err
+	}
 
+	// step 4: return the result
+	return required, nil
+}
+
+// organizationScope turns an organization id into the value stored in
+// user_role.organization_id, where global assignments are NULL.
+func organizationScope(organizationId int64) interface{} {
+	if organizationId == 0 {
+		return nil
+	}
+	return organizationId
+}
+
+func scanUserRoles(rows *sql.Rows) ([]*UserRole, error) {
+	userRoles := []*UserRole{}
+	for rows.Next() {
+		userRole := &UserRole{}
+		err := rows.Scan(&userRole.ID, &userRole.UserID, &userRole.RoleID, &userRole.OrganizationID, &userRole.ValidFrom, &userRole.ValidUntil, &userRole.CreatedAt, &userRole.UpdatedAt)
+		if err != nil {
+			fmt.Printf("Error scanning row: %v\n", err)
+			return nil, err
+		}
+		userRoles = append(userRoles, userRole)
+	}
+	return userRoles, rows.Err()
+}
@@ -1 +1,192 @@
 package userrole
+
+import (
+	"fmt"
+	"go_project_structure/internal/permission"
+	"go_project_structure/internal/role"
+	"time"
+)
+
+type UserRoleService interface {
+	GetUserRoles(userId int64, organizationId int64) ([]*role.Role, error)
+	AssignRoleToUser(userId int64, roleId int64, organizationId int64, validFrom *time.Time, validUntil *time.Time) error
+	RemoveRoleFromUser(userId int64, roleId int64, organizationId int64) error
+	GetUserPermissions(userId int64, organizationId int64) ([]*permission.Permission, error)
+	HasPermission(userId int64, organizationId int64, permissionName string, attributes permission.Attributes) (bool, error)
+	HasResourcePermission(userId int64, organizationId int64, permissionName string, resourceId string, attributes permission.Attributes) (bool, error)
+	DecidePermission(userId int64, organizationId int64, permissionName string, resourceId string, attributes permission.Attributes) (*PermissionDecision, error)
+	HasRole(userId int64, organizationId int64, roleName string) (bool, error)
+	HasAllRoles(userId int64, organizationId int64, roleNames []string) (bool, error)
+	HasAnyRole(userId int64, organizationId int64, roleNames []string) (bool, error)
+	GetUserEffectivePermissions(userId int64, organizationId int64) (*EffectivePermissions, error)
+	GetUserAssignments(userId int64, organizationId int64) ([]*UserRole, error)
+	SweepExpiredAssignments() ([]*UserRole, error)
+	IsOrganizationMember(userId int64, organizationId int64) (bool, error)
+}
+
+type UserRoleServiceImpl struct {
+	userRoleRepository UserRoleRepository
+}
+
+func NewUserRoleService(_userRoleRepository UserRoleRepository) UserRoleService {
+	return &UserRoleServiceImpl{
+		userRoleRepository: _userRoleRepository,
+	}
+}
+
+func (us *UserRoleServiceImpl) GetUserRoles(userId int64, organizationId int64) ([]*role.Role, error) {
+	fmt.Println("Getting user roles in userRole service.")
+	roles, err := us.userRoleRepository.GetUserRoles(userId, organizationId)
+	if err != nil {
+		fmt.Printf("Error fetching user roles: %v\n", err)
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (us *UserRoleServiceImpl) AssignRoleToUser(userId int64, roleId int64, organizationId int64, validFrom *time.Time, validUntil *time.Time) error {
+	fmt.Println("Assigning role to user in userRole service.")
+	err := us.userRoleRepository.AssignRoleToUser(userId, roleId, organizationId, validFrom, validUntil)
+	if err != nil {
+		fmt.Printf("Error assigning role to user: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func (us *UserRoleServiceImpl) RemoveRoleFromUser(userId int64, roleId int64, organizationId int64) error {
+	fmt.Println("Removing role from user in userRole service.")
+	err := us.userRoleRepository.RemoveRoleFromUser(userId, roleId, organizationId)
+	if err != nil {
+		fmt.Printf("Error removing role from user: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func (us *UserRoleServiceImpl) GetUserPermissions(userId int64, organizationId int64) ([]*permission.Permission, error) {
+	fmt.Println("Getting user permissions in userRole service.")
+	permissions, err := us.userRoleRepository.GetUserPermissions(userId, organizationId)
+	if err != nil {
+		fmt.Printf("Error fetching user permissions: %v\n", err)
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (us *UserRoleServiceImpl) HasPermission(userId int64, organizationId int64, permissionName string, attributes permission.Attributes) (bool, error) {
+	fmt.Println("Checking user permission in userRole service.")
+	allowed, err := us.userRoleRepository.HasPermission(userId, organizationId, permissionName, attributes)
+	if err != nil {
+		fmt.Printf("Error checking user permission: %v\n", err)
+		return false, err
+	}
+	return allowed, nil
+}
+
+// HasResourcePermission checks a permission on one object, treating the owner of the
+// object as allowed according to the ownership rule of the permission's resource type.
+// The object is exposed to grant conditions as resource.*.
+func (us *UserRoleServiceImpl) HasResourcePermission(userId int64, organizationId int64, permissionName string, resourceId string, attributes permission.Attributes) (bool, error) {
+	fmt.Println("Checking user resource permission in userRole service.")
+	isOwner := false
+	if resource, _, err := permission.ParsePermissionName(permissionName); err == nil {
+		isOwner = permission.IsOwner(userId, resource, resourceId)
+		attributes = permission.WithResource(attributes, resource, resourceId)
+	}
+	allowed, err := us.userRoleRepository.HasResourcePermission(userId, organizationId, permissionName, resourceId, isOwner, attributes)
+	if err != nil {
+		fmt.Printf("Error checking user resource permission: %v\n", err)
+		return false, err
+	}
+	return allowed, nil
+}
+
+// DecidePermission explains a permission check. With a resourceId it is checked on that
+// object the way HasResourcePermission does.
+func (us *UserRoleServiceImpl) DecidePermission(userId int64, organizationId int64, permissionName string, resourceId string, attributes permission.Attributes) (*PermissionDecision, error) {
+	fmt.Println("Deciding user permission in userRole service.")
+	isOwner := false
+	if resourceId != "" {
+		if resource, _, err := permission.ParsePermissionName(permissionName); err == nil {
+			isOwner = permission.IsOwner(userId, resource, resourceId)
+			attributes = permission.WithResource(attributes, resource, resourceId)
+		}
+	}
+	decision, err := us.userRoleRepository.DecidePermission(userId, organizationId, permissionName, resourceId, isOwner, attributes)
+	if err != nil {
+		fmt.Printf("Error deciding user permission: %v\n", err)
+		return nil, err
+	}
+	return decision, nil
+}
+
+func (us *UserRoleServiceImpl) HasRole(userId int64, organizationId int64, roleName string) (bool, error) {
+	fmt.Println("Checking user role in userRole service.")
+	matched, err := us.userRoleRepository.HasRole(userId, organizationId, roleName)
+	if err != nil {
+		fmt.Printf("Error checking user role: %v\n", err)
+		return false, err
+	}
+	return matched, nil
+}
+
+func (us *UserRoleServiceImpl) HasAllRoles(userId int64, organizationId int64, roleNames []string) (bool, error) {
+	fmt.Println("Checking all user roles in userRole service.")
+	matched, err := us.userRoleRepository.HasAllRoles(userId, organizationId, roleNames)
+	if err != nil {
+		fmt.Printf("Error checking user roles: %v\n", err)
+		return false, err
+	}
+	return matched, nil
+}
+
+func (us *UserRoleServiceImpl) HasAnyRole(userId int64, organizationId int64, roleNames []string) (bool, error) {
+	fmt.Println("Checking any user role in userRole service.")
+	matched, err := us.userRoleRepository.HasAnyRole(userId, organizationId, roleNames)
+	if err != nil {
+		fmt.Printf("Error checking user roles: %v\n", err)
+		return false, err
+	}
+	return matched, nil
+}
+
+func (us *UserRoleServiceImpl) GetUserEffectivePermissions(userId int64, organizationId int64) (*EffectivePermissions, error) {
+	fmt.Println("Getting effective user permissions in userRole service.")
+	effectivePermissions, err := us.userRoleRepository.GetUserEffectivePermissions(userId, organizationId)
+	if err != nil {
+		fmt.Printf("Error fetching effective user permissions: %v\n", err)
+		return nil, err
+	}
+	return effectivePermissions, nil
+}
+
+func (us *UserRoleServiceImpl) GetUserAssignments(userId int64, organizationId int64) ([]*UserRole, error) {
+	fmt.Println("Getting user role assignments in userRole service.")
+	assignments, err := us.userRoleRepository.GetUserAssignments(userId, organizationId)
+	if err != nil {
+		fmt.Printf("Error fetching user role assignments: %v\n", err)
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (us *UserRoleServiceImpl) SweepExpiredAssignments() ([]*UserRole, error) {
+	fmt.Println("Sweeping expired role assignments in userRole service.")
+	expired, err := us.userRoleRepository.SweepExpiredAssignments()
+	if err != nil {
+		fmt.Printf("Error sweeping expired role assignments: %v\n", err)
+		return nil, err
+	}
+	return expired, nil
+}
+
+func (us *UserRoleServiceImpl) IsOrganizationMember(userId int64, organizationId int64) (bool, error) {
+	fmt.Println("Checking organization membership in userRole service.")
+	member, err := us.userRoleRepository.IsOrganizationMember(userId, organizationId)
+	if err != nil {
+		fmt.Printf("Error checking organization membership: %v\n", err)
+		return false, err
+	}
+	return member, nil
+}
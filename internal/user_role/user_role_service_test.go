@@ -0,0 +1,66 @@
+package userrole
+
+import (
+	"strconv"
+	"testing"
+
+	"go_project_structure/internal/permission"
+)
+
+// fakeUserRoleRepository decides with the grants of the user's roles and the grants
+// on single objects, keyed by object id, the way the SQL repository loads them.
+type fakeUserRoleRepository struct {
+	UserRoleRepository
+	roleGrants     []permission.Grant
+	resourceGrants map[string][]permission.Grant
+}
+
+func (f *fakeUserRoleRepository) HasResourcePermission(userId int64, organizationId int64, permissionName string, resourceId string, isOwner bool, attributes permission.Attributes) (bool, error) {
+	grants := []permission.Grant{}
+	sources := []grantSource{}
+	for _, grant := range f.roleGrants {
+		grants = append(grants, grant)
+		sources = append(sources, grantSource{roleName: "user"})
+	}
+	for _, grant := range f.resourceGrants[resourceId] {
+		grants = append(grants, grant)
+		sources = append(sources, grantSource{resourceGrant: true})
+	}
+	return decideGrants(grants, sources, isOwner, permissionName, attributes).Allowed, nil
+}
+
+// TestProfileUpdate covers PATCH /profile/{id} for user 7 holding the user role,
+// which grants user:read only: ownership or a grant on the account decides.
+func TestProfileUpdate(t *testing.T) {
+	// the rule the application registers for users, user.OwnsAccount
+	permission.RegisterOwnershipRule("user", func(userId int64, resourceId string) bool {
+		return resourceId == strconv.FormatInt(userId, 10)
+	})
+	allow := func(name string) permission.Grant { return permission.Grant{Name: name, Effect: permission.EffectAllow} }
+	deny := func(name string) permission.Grant { return permission.Grant{Name: name, Effect: permission.EffectDeny} }
+
+	tests := []struct {
+		name           string
+		roleGrants     []permission.Grant
+		resourceGrants map[string][]permission.Grant
+		resourceId     string
+		want           bool
+	}{
+		{"own profile", []permission.Grant{allow("user:read")}, nil, "7", true},
+		{"other profile", []permission.Grant{allow("user:read")}, nil, "8", false},
+		{"other profile with a grant on it", []permission.Grant{allow("user:read")}, map[string][]permission.Grant{"8": {allow("user:update")}}, "8", true},
+		{"grant on a third profile", []permission.Grant{allow("user:read")}, map[string][]permission.Grant{"9": {allow("user:update")}}, "8", false},
+		{"deny overrides ownership", []permission.Grant{allow("user:read"), deny("user:update")}, nil, "7", false},
+		{"deny on the object overrides ownership", []permission.Grant{allow("user:read")}, map[string][]permission.Grant{"7": {deny("user:*")}}, "7", false},
+	}
+	for _, tt := range tests {
+		us := NewUserRoleService(&fakeUserRoleRepository{roleGrants: tt.roleGrants, resourceGrants: tt.resourceGrants})
+		allowed, err := us.HasResourcePermission(7, 0, "user:update", tt.resourceId, permission.NewAttributes(7, "", "", ""))
+		if err != nil {
+			t.Fatalf("%s: HasResourcePermission: %v", tt.name, err)
+		}
+		if allowed != tt.want {
+			t.Errorf("%s: allowed = %v, want %v", tt.name, allowed, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package userrole
+
+import (
+	"context"
+	"fmt"
+	"go_project_structure/internal/events"
+	"time"
+)
+
+// ExpiredAssignmentEvent is published for every assignment the sweeper removes.
+const ExpiredAssignmentEvent = "user_role.expired"
+
+// AssignmentSweeper periodically soft deletes role assignments whose validity
+// window has ended and publishes an event for each of them.
+type AssignmentSweeper struct {
+	userRoleService UserRoleService
+	bus             *events.Bus
+	interval        time.Duration
+}
+
+func NewAssignmentSweeper(_userRoleService UserRoleService, _bus *events.Bus, _interval time.Duration) *AssignmentSweeper {
+	return &AssignmentSweeper{
+		userRoleService: _userRoleService,
+		bus:             _bus,
+		interval:        _interval,
+	}
+}
+
+// Run sweeps once immediately and then on every tick until ctx is cancelled.
+func (s *AssignmentSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.Sweep()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *AssignmentSweeper) Sweep() {
+	expired, err := s.userRoleService.SweepExpiredAssignments()
+	if err != nil {
+		fmt.Printf("Error sweeping expired role assignments: %v\n", err)
+		return
+	}
+
+	for _, assignment := range expired {
+		s.bus.Publish(events.Event{
+			Name: ExpiredAssignmentEvent,
+			Data: map[string]interface{}{
+				"assignment_id":   assignment.ID,
+				"user_id":         assignment.UserID,
+				"role_id":         assignment.RoleID,
+				"organization_id": assignment.OrganizationID,
+				"valid_from":      assignment.ValidFrom,
+				"valid_until":     assignment.ValidUntil,
+			},
+		})
+	}
+}
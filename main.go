@@ -1,8 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"go_project_structure/app"
 	config "go_project_structure/config/env"
+	"os"
 )
 
 func main() {
@@ -10,7 +12,10 @@ func main() {
 	cfg := app.NewConfig()
 	app := app.NewApplication(cfg)
 
-	app.Run()
+	if err := app.Run(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 }
 
 // func main() {